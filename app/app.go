@@ -88,6 +88,11 @@ func (a *App) startup(ctx context.Context) {
 	// Perform your setup here
 	a.ctx = ctx
 	runtime.LogSetLogLevel(ctx, logger.TRACE)
+
+	// resume a library conversion that was interrupted by closing the app
+	if opts, ok := core.PendingStorageConversion(); ok {
+		a.startStorageConversion(opts)
+	}
 }
 
 // domReady is called after front-end resources have been loaded
@@ -304,9 +309,21 @@ func (a *App) CancelZipCompression() {
 	defer a.compressMutex.Unlock()
 
 	a.cancelCompressionInternal()
+	// only a conversion interrupted by closing the app is resumed
+	core.ClearStorageConversion()
 }
 
+// kept for the settings screen, converts to the selected space saver format
 func (a *App) FixZipCompression() {
+	a.ConvertLibraryStorage()
+}
+
+// ConvertLibraryStorage re-compresses the mod library to the space saver format and level
+func (a *App) ConvertLibraryStorage() {
+	a.startStorageConversion(a.appPrefs.CompressionOptions())
+}
+
+func (a *App) startStorageConversion(opts core.CompressionOptions) {
 
 	a.compressMutex.Lock()
 	defer a.compressMutex.Unlock()
@@ -335,7 +352,7 @@ func (a *App) FixZipCompression() {
 			cancel()
 		}()
 
-		core.WalkAndRezip(util.GetRootModDir(), ctx, func(total, complete int) {
+		err := core.ConvertLibraryStorage(ctx, util.GetRootModDir(), opts, 0, func(total, complete int) {
 			log.LogDebugf("on compress progres %d / %d", complete, total)
 
 			if ctx.Err() == nil {
@@ -348,9 +365,23 @@ func (a *App) FixZipCompression() {
 				)
 			}
 		})
+
+		if err != nil {
+			log.LogError(err.Error())
+		}
 	}()
 }
 
+func (a *App) GetCompressionFormats() []core.CompressionFormat {
+	formats := make([]core.CompressionFormat, 0, len(core.CompressionFormats))
+	for _, f := range core.CompressionFormats {
+		if f.Supported() {
+			formats = append(formats, f)
+		}
+	}
+	return formats
+}
+
 func (a *App) GetStats() (*types.DownloadStats, error) {
 	log.LogDebug("Getting stats")
 	bytes, rootInfo, err := getDirInfo(util.GetRootModDir())
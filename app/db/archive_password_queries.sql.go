@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: archive_password_queries.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteArchivePassword = `-- name: DeleteArchivePassword :exec
+DELETE FROM archive_password WHERE scope = ?1 AND scope_id = ?2 AND password = ?3
+`
+
+type DeleteArchivePasswordParams struct {
+	Scope    int64
+	ScopeId  int64
+	Password string
+}
+
+func (q *Queries) DeleteArchivePassword(ctx context.Context, arg DeleteArchivePasswordParams) error {
+	_, err := q.db.ExecContext(ctx, deleteArchivePassword, arg.Scope, arg.ScopeId, arg.Password)
+	return err
+}
+
+const deleteArchivePasswordsByScope = `-- name: DeleteArchivePasswordsByScope :exec
+DELETE FROM archive_password WHERE scope = ?1 AND scope_id = ?2
+`
+
+type DeleteArchivePasswordsByScopeParams struct {
+	Scope   int64
+	ScopeId int64
+}
+
+func (q *Queries) DeleteArchivePasswordsByScope(ctx context.Context, arg DeleteArchivePasswordsByScopeParams) error {
+	_, err := q.db.ExecContext(ctx, deleteArchivePasswordsByScope, arg.Scope, arg.ScopeId)
+	return err
+}
+
+const insertArchivePassword = `-- name: InsertArchivePassword :exec
+
+INSERT OR IGNORE INTO archive_password(scope, scope_id, password) VALUES(?1, ?2, ?3)
+`
+
+type InsertArchivePasswordParams struct {
+	Scope    int64
+	ScopeId  int64
+	Password string
+}
+
+// archive_password(
+//
+//	scope INTEGER NOT NULL,
+//	scope_id INTEGER NOT NULL,
+//	password TEXT NOT NULL,
+//	PRIMARY KEY(scope, scope_id, password)
+//
+// );
+func (q *Queries) InsertArchivePassword(ctx context.Context, arg InsertArchivePasswordParams) error {
+	_, err := q.db.ExecContext(ctx, insertArchivePassword, arg.Scope, arg.ScopeId, arg.Password)
+	return err
+}
+
+const selectArchivePasswords = `-- name: SelectArchivePasswords :many
+SELECT password FROM archive_password WHERE scope = ?1 AND scope_id = ?2
+`
+
+type SelectArchivePasswordsParams struct {
+	Scope   int64
+	ScopeId int64
+}
+
+func (q *Queries) SelectArchivePasswords(ctx context.Context, arg SelectArchivePasswordsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, selectArchivePasswords, arg.Scope, arg.ScopeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var password string
+		if err := rows.Scan(&password); err != nil {
+			return nil, err
+		}
+		items = append(items, password)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: character_alias_queries.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteCustomCharacterAlias = `-- name: DeleteCustomCharacterAlias :exec
+DELETE FROM character_alias WHERE game = ?1 AND alias = ?2 AND custom = TRUE
+`
+
+type DeleteCustomCharacterAliasParams struct {
+	Game  int64
+	Alias string
+}
+
+func (q *Queries) DeleteCustomCharacterAlias(ctx context.Context, arg DeleteCustomCharacterAliasParams) error {
+	_, err := q.db.ExecContext(ctx, deleteCustomCharacterAlias, arg.Game, arg.Alias)
+	return err
+}
+
+const disableCharacterAlias = `-- name: DisableCharacterAlias :exec
+UPDATE character_alias SET
+    disabled = TRUE
+WHERE game = ?1 AND alias = ?2
+`
+
+type DisableCharacterAliasParams struct {
+	Game  int64
+	Alias string
+}
+
+func (q *Queries) DisableCharacterAlias(ctx context.Context, arg DisableCharacterAliasParams) error {
+	_, err := q.db.ExecContext(ctx, disableCharacterAlias, arg.Game, arg.Alias)
+	return err
+}
+
+const insertBundledCharacterAlias = `-- name: InsertBundledCharacterAlias :exec
+INSERT INTO character_alias(game, char_id, alias, custom, disabled)
+VALUES (?1, ?2, ?3, FALSE, FALSE)
+ON CONFLICT(game, alias) DO NOTHING
+`
+
+type InsertBundledCharacterAliasParams struct {
+	Game   int64
+	CharId int64
+	Alias  string
+}
+
+func (q *Queries) InsertBundledCharacterAlias(ctx context.Context, arg InsertBundledCharacterAliasParams) error {
+	_, err := q.db.ExecContext(ctx, insertBundledCharacterAlias, arg.Game, arg.CharId, arg.Alias)
+	return err
+}
+
+const selectCharacterAliasesByGame = `-- name: SelectCharacterAliasesByGame :many
+
+SELECT game, char_id, alias, custom, disabled FROM character_alias WHERE game = ?1 ORDER BY char_id, alias
+`
+
+// character_alias(
+//
+//	game INTEGER NOT NULL,
+//	char_id INTEGER NOT NULL,
+//	alias TEXT NOT NULL COLLATE NOCASE,
+//	custom BOOLEAN NOT NULL DEFAULT FALSE,
+//	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+//	PRIMARY KEY(game, alias),
+//	FOREIGN KEY (char_id, game) REFERENCES character(id, game) ON DELETE CASCADE
+//
+// );
+func (q *Queries) SelectCharacterAliasesByGame(ctx context.Context, game int64) ([]CharacterAlias, error) {
+	rows, err := q.db.QueryContext(ctx, selectCharacterAliasesByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CharacterAlias
+	for rows.Next() {
+		var i CharacterAlias
+		if err := rows.Scan(
+			&i.Game,
+			&i.CharID,
+			&i.Alias,
+			&i.Custom,
+			&i.Disabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCustomCharacterAlias = `-- name: UpsertCustomCharacterAlias :exec
+INSERT INTO character_alias(game, char_id, alias, custom, disabled)
+VALUES (?1, ?2, ?3, TRUE, FALSE)
+ON CONFLICT(game, alias) DO UPDATE SET
+    char_id = excluded.char_id,
+    custom = TRUE,
+    disabled = FALSE
+`
+
+type UpsertCustomCharacterAliasParams struct {
+	Game   int64
+	CharId int64
+	Alias  string
+}
+
+func (q *Queries) UpsertCustomCharacterAlias(ctx context.Context, arg UpsertCustomCharacterAliasParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCustomCharacterAlias, arg.Game, arg.CharId, arg.Alias)
+	return err
+}
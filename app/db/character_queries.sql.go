@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const deleteCharacterById = `-- name: DeleteCharacterById :exec
@@ -20,9 +21,45 @@ func (q *Queries) DeleteCharacterById(ctx context.Context, id int64) error {
 	return err
 }
 
+const selectCategoriesByGame = `-- name: SelectCategoriesByGame :many
+SELECT id, game, name, avatar_url, element, flags, category, "exclusive" FROM character WHERE game = ?1 AND category != 0
+`
+
+func (q *Queries) SelectCategoriesByGame(ctx context.Context, game int64) ([]Character, error) {
+	rows, err := q.db.QueryContext(ctx, selectCategoriesByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Character
+	for rows.Next() {
+		var i Character
+		if err := rows.Scan(
+			&i.ID,
+			&i.Game,
+			&i.Name,
+			&i.AvatarUrl,
+			&i.Element,
+			&i.Flags,
+			&i.Category,
+			&i.Exclusive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const selectCharacterById = `-- name: SelectCharacterById :one
 
-SELECT id, game, name, avatar_url, element, flags FROM character WHERE id = ?1 AND game = ?2 LIMIT 1
+SELECT id, game, name, avatar_url, element, flags, category, "exclusive" FROM character WHERE id = ?1 AND game = ?2 LIMIT 1
 `
 
 type SelectCharacterByIdParams struct {
@@ -38,6 +75,8 @@ type SelectCharacterByIdParams struct {
 //	avatar_url TEXT NOT NULL DEFAULT '',
 //	element TEXT NOT NULL,
 //	flags INT NOT NULL DEFAULT 0,
+//	category INTEGER NOT NULL DEFAULT 0,
+//	exclusive INTEGER NOT NULL DEFAULT 0,
 //	PRIMARY KEY(id, game)
 //
 // );
@@ -51,12 +90,14 @@ func (q *Queries) SelectCharacterById(ctx context.Context, arg SelectCharacterBy
 		&i.AvatarUrl,
 		&i.Element,
 		&i.Flags,
+		&i.Category,
+		&i.Exclusive,
 	)
 	return i, err
 }
 
 const selectCharactersByGame = `-- name: SelectCharactersByGame :many
-SELECT id, game, name, avatar_url, element, flags FROM character WHERE game = ?1
+SELECT id, game, name, avatar_url, element, flags, category, "exclusive" FROM character WHERE game = ?1
 `
 
 func (q *Queries) SelectCharactersByGame(ctx context.Context, game int64) ([]Character, error) {
@@ -75,6 +116,60 @@ func (q *Queries) SelectCharactersByGame(ctx context.Context, game int64) ([]Cha
 			&i.AvatarUrl,
 			&i.Element,
 			&i.Flags,
+			&i.Category,
+			&i.Exclusive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectCharactersByIds = `-- name: SelectCharactersByIds :many
+SELECT id, game, name, avatar_url, element, flags, category, "exclusive" FROM character WHERE game = ?1 AND id IN (/*SLICE:ids*/?) ORDER BY name
+`
+
+type SelectCharactersByIdsParams struct {
+	Game int64
+	Ids  []int64
+}
+
+func (q *Queries) SelectCharactersByIds(ctx context.Context, arg SelectCharactersByIdsParams) ([]Character, error) {
+	query := selectCharactersByIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.Game)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Character
+	for rows.Next() {
+		var i Character
+		if err := rows.Scan(
+			&i.ID,
+			&i.Game,
+			&i.Name,
+			&i.AvatarUrl,
+			&i.Element,
+			&i.Flags,
+			&i.Category,
+			&i.Exclusive,
 		); err != nil {
 			return nil, err
 		}
@@ -91,8 +186,8 @@ func (q *Queries) SelectCharactersByGame(ctx context.Context, game int64) ([]Cha
 
 const selectCharactersWithModsAndTags = `-- name: SelectCharactersWithModsAndTags :many
 SELECT 
-    c.id, c.game, c.name, c.avatar_url, c.element, c.flags,
-    m.id, m.fname, m.game, m.char_name, m.char_id, m.selected, m.preview_images, m.gb_id, m.mod_link, m.gb_file_name, m.gb_download_link,
+    c.id, c.game, c.name, c.avatar_url, c.element, c.flags, c.category, c."exclusive",
+    m.id, m.fname, m.game, m.char_name, m.char_id, m.selected, m.preview_images, m.gb_id, m.mod_link, m.gb_file_name, m.gb_download_link, m.flags, m.fingerprint, m.author, m.version, m.description, m.content_ratings, m.added_at, m.last_enabled_at, m.size, m.gb_updated_at, m.favorite, m.rating, m.notes, m.gb_name,
     t.mod_id, t.tag_name,
     tex.id, tex.mod_id, tex.fname, tex.selected, tex.preview_images, tex.gb_id, tex.mod_link, tex.gb_file_name, tex.gb_download_link
 FROM character c
@@ -105,6 +200,7 @@ WHERE c.game = ?1
 AND (
         (
             m.fname LIKE '%' || ?2 || '%'
+            OR m.notes LIKE '%' || ?2 || '%'
             OR c.name LIKE '%' || ?3 || '%'
             OR t.tag_name LIKE '%' || ?4 || '%'
         ) OR (
@@ -131,6 +227,8 @@ type SelectCharactersWithModsAndTagsRow struct {
 	AvatarUrl        string
 	Element          string
 	Flags            int64
+	Category         int64
+	Exclusive        int64
 	ID_2             sql.NullInt64
 	Fname            sql.NullString
 	Game_2           sql.NullInt64
@@ -142,6 +240,20 @@ type SelectCharactersWithModsAndTagsRow struct {
 	ModLink          sql.NullString
 	GbFileName       sql.NullString
 	GbDownloadLink   sql.NullString
+	Flags_2          sql.NullInt64
+	Fingerprint      sql.NullString
+	Author           sql.NullString
+	Version          sql.NullString
+	Description      sql.NullString
+	ContentRatings   sql.NullString
+	AddedAt          sql.NullInt64
+	LastEnabledAt    sql.NullInt64
+	Size             sql.NullInt64
+	GbUpdatedAt      sql.NullInt64
+	Favorite         sql.NullBool
+	Rating           sql.NullInt64
+	Notes            sql.NullString
+	GbName           sql.NullString
 	ModID            sql.NullInt64
 	TagName          sql.NullString
 	ID_3             sql.NullInt64
@@ -176,6 +288,8 @@ func (q *Queries) SelectCharactersWithModsAndTags(ctx context.Context, arg Selec
 			&i.AvatarUrl,
 			&i.Element,
 			&i.Flags,
+			&i.Category,
+			&i.Exclusive,
 			&i.ID_2,
 			&i.Fname,
 			&i.Game_2,
@@ -187,6 +301,20 @@ func (q *Queries) SelectCharactersWithModsAndTags(ctx context.Context, arg Selec
 			&i.ModLink,
 			&i.GbFileName,
 			&i.GbDownloadLink,
+			&i.Flags_2,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
 			&i.ModID,
 			&i.TagName,
 			&i.ID_3,
@@ -213,7 +341,7 @@ func (q *Queries) SelectCharactersWithModsAndTags(ctx context.Context, arg Selec
 }
 
 const selectClosestCharacter = `-- name: SelectClosestCharacter :one
-SELECT id, game, name, avatar_url, element, flags FROM character WHERE LOWER(name) LIKE '%' || LOWER(?1) || '%' AND game = ?2 LIMIT 1
+SELECT id, game, name, avatar_url, element, flags, category, "exclusive" FROM character WHERE LOWER(name) LIKE '%' || LOWER(?1) || '%' AND game = ?2 LIMIT 1
 `
 
 type SelectClosestCharacterParams struct {
@@ -231,12 +359,14 @@ func (q *Queries) SelectClosestCharacter(ctx context.Context, arg SelectClosestC
 		&i.AvatarUrl,
 		&i.Element,
 		&i.Flags,
+		&i.Category,
+		&i.Exclusive,
 	)
 	return i, err
 }
 
 const selectClosestCharacterMatch = `-- name: SelectClosestCharacterMatch :one
-SELECT id, game, name, avatar_url, element, flags FROM character WHERE LOWER(name) LIKE '%' || LOWER(?1) || '%' AND game = ?2 LIMIT 1
+SELECT id, game, name, avatar_url, element, flags, category, "exclusive" FROM character WHERE LOWER(name) LIKE '%' || LOWER(?1) || '%' AND game = ?2 LIMIT 1
 `
 
 type SelectClosestCharacterMatchParams struct {
@@ -254,6 +384,25 @@ func (q *Queries) SelectClosestCharacterMatch(ctx context.Context, arg SelectClo
 		&i.AvatarUrl,
 		&i.Element,
 		&i.Flags,
+		&i.Category,
+		&i.Exclusive,
 	)
 	return i, err
 }
+
+const updateCharacterExclusive = `-- name: UpdateCharacterExclusive :exec
+UPDATE character SET
+    exclusive = ?1
+WHERE id = ?2 AND game = ?3
+`
+
+type UpdateCharacterExclusiveParams struct {
+	Exclusive int64
+	ID        int64
+	Game      int64
+}
+
+func (q *Queries) UpdateCharacterExclusive(ctx context.Context, arg UpdateCharacterExclusiveParams) error {
+	_, err := q.db.ExecContext(ctx, updateCharacterExclusive, arg.Exclusive, arg.ID, arg.Game)
+	return err
+}
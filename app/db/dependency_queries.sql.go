@@ -0,0 +1,210 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: dependency_queries.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteModDependenciesByModId = `-- name: DeleteModDependenciesByModId :exec
+DELETE FROM mod_dependency WHERE mod_id = ?1 OR (kind = 0 AND dep_id = ?1)
+`
+
+func (q *Queries) DeleteModDependenciesByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteModDependenciesByModId, modid)
+	return err
+}
+
+const deleteModDependenciesBySource = `-- name: DeleteModDependenciesBySource :exec
+DELETE FROM mod_dependency WHERE mod_id = ?1 AND source = ?2
+`
+
+type DeleteModDependenciesBySourceParams struct {
+	ModId  int64
+	Source int64
+}
+
+func (q *Queries) DeleteModDependenciesBySource(ctx context.Context, arg DeleteModDependenciesBySourceParams) error {
+	_, err := q.db.ExecContext(ctx, deleteModDependenciesBySource, arg.ModId, arg.Source)
+	return err
+}
+
+const deleteModDependency = `-- name: DeleteModDependency :exec
+DELETE FROM mod_dependency WHERE mod_id = ?1 AND kind = ?2 AND dep_id = ?3
+`
+
+type DeleteModDependencyParams struct {
+	ModId int64
+	Kind  int64
+	DepId int64
+}
+
+func (q *Queries) DeleteModDependency(ctx context.Context, arg DeleteModDependencyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteModDependency, arg.ModId, arg.Kind, arg.DepId)
+	return err
+}
+
+const insertModDependency = `-- name: InsertModDependency :exec
+
+INSERT OR IGNORE INTO mod_dependency(mod_id, kind, dep_id, source) VALUES(?1, ?2, ?3, ?4)
+`
+
+type InsertModDependencyParams struct {
+	ModId  int64
+	Kind   int64
+	DepId  int64
+	Source int64
+}
+
+// mod_dependency(
+//
+//	mod_id INTEGER NOT NULL,
+//	kind INTEGER NOT NULL,
+//	dep_id INTEGER NOT NULL,
+//	source INTEGER NOT NULL DEFAULT 0,
+//	PRIMARY KEY(mod_id, kind, dep_id),
+//	FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+//
+// );
+func (q *Queries) InsertModDependency(ctx context.Context, arg InsertModDependencyParams) error {
+	_, err := q.db.ExecContext(ctx, insertModDependency,
+		arg.ModId,
+		arg.Kind,
+		arg.DepId,
+		arg.Source,
+	)
+	return err
+}
+
+const mergeModDependencies = `-- name: MergeModDependencies :exec
+INSERT OR IGNORE INTO mod_dependency(mod_id, kind, dep_id, source)
+SELECT ?1, d.kind, d.dep_id, d.source FROM mod_dependency d
+WHERE d.mod_id = ?2 AND (d.kind != 0 OR d.dep_id != ?1)
+`
+
+type MergeModDependenciesParams struct {
+	KeepId   int64
+	RemoveId int64
+}
+
+func (q *Queries) MergeModDependencies(ctx context.Context, arg MergeModDependenciesParams) error {
+	_, err := q.db.ExecContext(ctx, mergeModDependencies, arg.KeepId, arg.RemoveId)
+	return err
+}
+
+const mergeModDependents = `-- name: MergeModDependents :exec
+INSERT OR IGNORE INTO mod_dependency(mod_id, kind, dep_id, source)
+SELECT d.mod_id, d.kind, ?1, d.source FROM mod_dependency d
+WHERE d.kind = 0 AND d.dep_id = ?2 AND d.mod_id != ?1
+`
+
+type MergeModDependentsParams struct {
+	KeepId   int64
+	RemoveId int64
+}
+
+func (q *Queries) MergeModDependents(ctx context.Context, arg MergeModDependentsParams) error {
+	_, err := q.db.ExecContext(ctx, mergeModDependents, arg.KeepId, arg.RemoveId)
+	return err
+}
+
+const selectModDependencies = `-- name: SelectModDependencies :many
+SELECT mod_id, kind, dep_id, source FROM mod_dependency WHERE mod_id = ?1 ORDER BY kind, dep_id
+`
+
+func (q *Queries) SelectModDependencies(ctx context.Context, modid int64) ([]ModDependency, error) {
+	rows, err := q.db.QueryContext(ctx, selectModDependencies, modid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModDependency
+	for rows.Next() {
+		var i ModDependency
+		if err := rows.Scan(
+			&i.ModID,
+			&i.Kind,
+			&i.DepID,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModDependenciesByGame = `-- name: SelectModDependenciesByGame :many
+SELECT mod_dependency.mod_id, mod_dependency.kind, mod_dependency.dep_id, mod_dependency.source FROM mod_dependency
+JOIN mod ON mod.id = mod_dependency.mod_id
+WHERE mod.game = ?1
+`
+
+func (q *Queries) SelectModDependenciesByGame(ctx context.Context, game int64) ([]ModDependency, error) {
+	rows, err := q.db.QueryContext(ctx, selectModDependenciesByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModDependency
+	for rows.Next() {
+		var i ModDependency
+		if err := rows.Scan(
+			&i.ModID,
+			&i.Kind,
+			&i.DepID,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModDependenciesInvolving = `-- name: SelectModDependenciesInvolving :many
+SELECT mod_id, kind, dep_id, source FROM mod_dependency WHERE mod_id = ?1 OR (kind = 0 AND dep_id = ?1)
+`
+
+func (q *Queries) SelectModDependenciesInvolving(ctx context.Context, modid int64) ([]ModDependency, error) {
+	rows, err := q.db.QueryContext(ctx, selectModDependenciesInvolving, modid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModDependency
+	for rows.Next() {
+		var i ModDependency
+		if err := rows.Scan(
+			&i.ModID,
+			&i.Kind,
+			&i.DepID,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -9,38 +9,126 @@ import (
 	"context"
 )
 
-const insertOrUpdateEntry = `-- name: InsertOrUpdateEntry :exec
-INSERT INTO inicache(mod_id, fname)
-VALUES (?1, ?2)
-ON CONFLICT(mod_id) DO UPDATE SET
-  fname = excluded.fname
+const deleteIniCacheByModId = `-- name: DeleteIniCacheByModId :exec
+DELETE FROM inicache WHERE mod_id = ?1
 `
 
-type InsertOrUpdateEntryParams struct {
-	ModId int64
-	FName string
+func (q *Queries) DeleteIniCacheByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteIniCacheByModId, modid)
+	return err
+}
+
+const insertIniCacheEntry = `-- name: InsertIniCacheEntry :exec
+INSERT INTO inicache(mod_id, fname, fingerprint)
+VALUES (?1, ?2, ?3)
+ON CONFLICT(mod_id, fname) DO UPDATE SET
+  fingerprint = excluded.fingerprint
+`
+
+type InsertIniCacheEntryParams struct {
+	ModId       int64
+	FName       string
+	Fingerprint string
 }
 
-func (q *Queries) InsertOrUpdateEntry(ctx context.Context, arg InsertOrUpdateEntryParams) error {
-	_, err := q.db.ExecContext(ctx, insertOrUpdateEntry, arg.ModId, arg.FName)
+func (q *Queries) InsertIniCacheEntry(ctx context.Context, arg InsertIniCacheEntryParams) error {
+	_, err := q.db.ExecContext(ctx, insertIniCacheEntry, arg.ModId, arg.FName, arg.Fingerprint)
 	return err
 }
 
-const selectIniCacheByModId = `-- name: SelectIniCacheByModId :one
+const selectIniCacheByModId = `-- name: SelectIniCacheByModId :many
 
-SELECT mod_id, fname FROM inicache WHERE mod_id = ?1
+SELECT mod_id, fname, fingerprint FROM inicache WHERE mod_id = ?1 ORDER BY fname
 `
 
 // CREATE TABLE IF NOT EXISTS inicache (
 //
-//	mod_id INTEGER PRIMARY KEY NOT NULL,
+//	mod_id INTEGER NOT NULL,
 //	fname TEXT NOT NULL,
+//	fingerprint TEXT NOT NULL DEFAULT '',
+//	PRIMARY KEY (mod_id, fname),
 //	FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
 //
 // );
-func (q *Queries) SelectIniCacheByModId(ctx context.Context, modid int64) (Inicache, error) {
-	row := q.db.QueryRowContext(ctx, selectIniCacheByModId, modid)
-	var i Inicache
-	err := row.Scan(&i.ModID, &i.Fname)
-	return i, err
+func (q *Queries) SelectIniCacheByModId(ctx context.Context, modid int64) ([]Inicache, error) {
+	rows, err := q.db.QueryContext(ctx, selectIniCacheByModId, modid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Inicache
+	for rows.Next() {
+		var i Inicache
+		if err := rows.Scan(&i.ModID, &i.Fname, &i.Fingerprint); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectIniCacheWithMods = `-- name: SelectIniCacheWithMods :many
+SELECT mod.id, mod.fname, mod.game, mod.char_name, mod.char_id, mod.selected, mod.preview_images, mod.gb_id, mod.mod_link, mod.gb_file_name, mod.gb_download_link, mod.flags, mod.fingerprint, mod.author, mod.version, mod.description, mod.content_ratings, mod.added_at, mod.last_enabled_at, mod.size, mod.gb_updated_at, mod.favorite, mod.rating, mod.notes, mod.gb_name, inicache.fname AS ini_fname FROM inicache
+JOIN mod ON mod.id = inicache.mod_id
+`
+
+type SelectIniCacheWithModsRow struct {
+	Mod      Mod
+	IniFname string
+}
+
+func (q *Queries) SelectIniCacheWithMods(ctx context.Context) ([]SelectIniCacheWithModsRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectIniCacheWithMods)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectIniCacheWithModsRow
+	for rows.Next() {
+		var i SelectIniCacheWithModsRow
+		if err := rows.Scan(
+			&i.Mod.ID,
+			&i.Mod.Fname,
+			&i.Mod.Game,
+			&i.Mod.CharName,
+			&i.Mod.CharID,
+			&i.Mod.Selected,
+			&i.Mod.PreviewImages,
+			&i.Mod.GbID,
+			&i.Mod.ModLink,
+			&i.Mod.GbFileName,
+			&i.Mod.GbDownloadLink,
+			&i.Mod.Flags,
+			&i.Mod.Fingerprint,
+			&i.Mod.Author,
+			&i.Mod.Version,
+			&i.Mod.Description,
+			&i.Mod.ContentRatings,
+			&i.Mod.AddedAt,
+			&i.Mod.LastEnabledAt,
+			&i.Mod.Size,
+			&i.Mod.GbUpdatedAt,
+			&i.Mod.Favorite,
+			&i.Mod.Rating,
+			&i.Mod.Notes,
+			&i.Mod.GbName,
+			&i.IniFname,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS archive_password(
+    scope INTEGER NOT NULL,
+    scope_id INTEGER NOT NULL,
+    password TEXT NOT NULL,
+    PRIMARY KEY(scope, scope_id, password)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS archive_password;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE mod ADD COLUMN flags INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS quarantine(
+    mod_id INTEGER NOT NULL,
+    path TEXT NOT NULL,
+    allowed BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY(mod_id, path),
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS quarantine;
+ALTER TABLE mod DROP COLUMN flags;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS sync_pending_delete(
+    kind INTEGER NOT NULL,
+    row_id INTEGER NOT NULL,
+    game INTEGER NOT NULL,
+    since INTEGER NOT NULL,
+    PRIMARY KEY(kind, row_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS sync_pending_delete;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE mod ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE mod DROP COLUMN fingerprint;
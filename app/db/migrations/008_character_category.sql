@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE character ADD COLUMN category INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE character DROP COLUMN category;
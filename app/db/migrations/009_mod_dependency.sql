@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS mod_dependency(
+    mod_id INTEGER NOT NULL,
+    kind INTEGER NOT NULL,
+    dep_id INTEGER NOT NULL,
+    source INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(mod_id, kind, dep_id),
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS mod_dependency;
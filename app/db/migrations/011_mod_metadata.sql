@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE mod ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE mod ADD COLUMN version TEXT NOT NULL DEFAULT '';
+ALTER TABLE mod ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE mod ADD COLUMN content_ratings TEXT NOT NULL DEFAULT '';
+ALTER TABLE mod ADD COLUMN added_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mod ADD COLUMN last_enabled_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mod ADD COLUMN size INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mod ADD COLUMN gb_updated_at INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE mod DROP COLUMN author;
+ALTER TABLE mod DROP COLUMN version;
+ALTER TABLE mod DROP COLUMN description;
+ALTER TABLE mod DROP COLUMN content_ratings;
+ALTER TABLE mod DROP COLUMN added_at;
+ALTER TABLE mod DROP COLUMN last_enabled_at;
+ALTER TABLE mod DROP COLUMN size;
+ALTER TABLE mod DROP COLUMN gb_updated_at;
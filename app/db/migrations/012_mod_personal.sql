@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE mod ADD COLUMN favorite BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE mod ADD COLUMN rating INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mod ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE mod DROP COLUMN favorite;
+ALTER TABLE mod DROP COLUMN rating;
+ALTER TABLE mod DROP COLUMN notes;
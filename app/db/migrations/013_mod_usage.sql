@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS mod_usage(
+    mod_id INTEGER PRIMARY KEY NOT NULL,
+    generate_count INTEGER NOT NULL DEFAULT 0,
+    enabled_seconds INTEGER NOT NULL DEFAULT 0,
+    active_since INTEGER NOT NULL DEFAULT 0,
+    last_exported_at INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS mod_usage;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE mod ADD COLUMN gb_name TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE mod DROP COLUMN gb_name;
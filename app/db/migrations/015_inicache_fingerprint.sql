@@ -0,0 +1,20 @@
+-- +goose Up
+-- the cached paths have no fingerprint and would be searched again anyway
+DROP TABLE IF EXISTS inicache;
+
+CREATE TABLE inicache (
+    mod_id INTEGER NOT NULL,
+    fname TEXT NOT NULL,
+    fingerprint TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (mod_id, fname),
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS inicache;
+
+CREATE TABLE inicache (
+    mod_id INTEGER PRIMARY KEY NOT NULL,
+    fname TEXT NOT NULL,
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS character_alias(
+    game INTEGER NOT NULL,
+    char_id INTEGER NOT NULL,
+    alias TEXT NOT NULL COLLATE NOCASE,
+    custom BOOLEAN NOT NULL DEFAULT FALSE,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY(game, alias),
+    FOREIGN KEY (char_id, game) REFERENCES character(id, game) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS character_alias;
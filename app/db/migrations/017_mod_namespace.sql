@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS mod_namespace(
+    mod_id INTEGER PRIMARY KEY NOT NULL,
+    fingerprint TEXT NOT NULL DEFAULT '',
+    declared TEXT NOT NULL DEFAULT '',
+    referenced TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS mod_namespace;
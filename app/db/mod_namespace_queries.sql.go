@@ -0,0 +1,80 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: mod_namespace_queries.sql
+
+package db
+
+import (
+	"context"
+)
+
+const selectModNamespacesByGame = `-- name: SelectModNamespacesByGame :many
+
+SELECT mod_namespace.mod_id, mod_namespace.fingerprint, mod_namespace.declared, mod_namespace.referenced FROM mod_namespace
+JOIN mod ON mod.id = mod_namespace.mod_id
+WHERE mod.game = ?1
+`
+
+// CREATE TABLE IF NOT EXISTS mod_namespace(
+//
+//	mod_id INTEGER PRIMARY KEY NOT NULL,
+//	fingerprint TEXT NOT NULL DEFAULT '',
+//	declared TEXT NOT NULL DEFAULT '',
+//	referenced TEXT NOT NULL DEFAULT '',
+//	FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+//
+// );
+func (q *Queries) SelectModNamespacesByGame(ctx context.Context, game int64) ([]ModNamespace, error) {
+	rows, err := q.db.QueryContext(ctx, selectModNamespacesByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModNamespace
+	for rows.Next() {
+		var i ModNamespace
+		if err := rows.Scan(
+			&i.ModID,
+			&i.Fingerprint,
+			&i.Declared,
+			&i.Referenced,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertModNamespace = `-- name: UpsertModNamespace :exec
+INSERT INTO mod_namespace(mod_id, fingerprint, declared, referenced)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT(mod_id) DO UPDATE SET
+  fingerprint = excluded.fingerprint,
+  declared = excluded.declared,
+  referenced = excluded.referenced
+`
+
+type UpsertModNamespaceParams struct {
+	ModId       int64
+	Fingerprint string
+	Declared    string
+	Referenced  string
+}
+
+func (q *Queries) UpsertModNamespace(ctx context.Context, arg UpsertModNamespaceParams) error {
+	_, err := q.db.ExecContext(ctx, upsertModNamespace,
+		arg.ModId,
+		arg.Fingerprint,
+		arg.Declared,
+		arg.Referenced,
+	)
+	return err
+}
@@ -45,6 +45,33 @@ func (q *Queries) DeleteUnusedMods(ctx context.Context, arg DeleteUnusedModsPara
 	return err
 }
 
+const deleteUnusedModsByCharacter = `-- name: DeleteUnusedModsByCharacter :exec
+DELETE FROM mod WHERE fname NOT IN /*SLICE:files*/? AND char_name = ?2 AND game = ?3
+`
+
+type DeleteUnusedModsByCharacterParams struct {
+	Files         []string
+	CharacterName string
+	Game          int64
+}
+
+func (q *Queries) DeleteUnusedModsByCharacter(ctx context.Context, arg DeleteUnusedModsByCharacterParams) error {
+	query := deleteUnusedModsByCharacter
+	var queryParams []interface{}
+	if len(arg.Files) > 0 {
+		for _, v := range arg.Files {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:files*/?", strings.Repeat(",?", len(arg.Files))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:files*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.CharacterName)
+	queryParams = append(queryParams, arg.Game)
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const insertMod = `-- name: InsertMod :one
 
 INSERT INTO mod (
@@ -56,7 +83,9 @@ INSERT INTO mod (
     preview_images, 
     gb_id, mod_link, 
     gb_file_name, 
-    gb_download_link
+    gb_download_link,
+    flags,
+    added_at
 ) VALUES(
     ?1,
     ?2,
@@ -67,7 +96,9 @@ INSERT INTO mod (
     ?7,
     ?8,
     ?9,
-    ?10
+    ?10,
+    ?11,
+    ?12
 )
 ON CONFLICT(fname, char_id, char_name) DO NOTHING
 RETURNING id
@@ -84,6 +115,8 @@ type InsertModParams struct {
 	ModLink        sql.NullString
 	GbFilename     sql.NullString
 	GbDownloadLink sql.NullString
+	Flags          int64
+	AddedAt        int64
 }
 
 // mod(
@@ -99,6 +132,20 @@ type InsertModParams struct {
 //	mod_link TEXT,
 //	gb_file_name TEXT,
 //	gb_download_link TEXT,
+//	flags INTEGER NOT NULL DEFAULT 0,
+//	fingerprint TEXT NOT NULL DEFAULT '',
+//	author TEXT NOT NULL DEFAULT '',
+//	version TEXT NOT NULL DEFAULT '',
+//	description TEXT NOT NULL DEFAULT '',
+//	content_ratings TEXT NOT NULL DEFAULT '',
+//	added_at INTEGER NOT NULL DEFAULT 0,
+//	last_enabled_at INTEGER NOT NULL DEFAULT 0,
+//	size INTEGER NOT NULL DEFAULT 0,
+//	gb_updated_at INTEGER NOT NULL DEFAULT 0,
+//	favorite BOOLEAN NOT NULL DEFAULT FALSE,
+//	rating INTEGER NOT NULL DEFAULT 0,
+//	notes TEXT NOT NULL DEFAULT '',
+//	gb_name TEXT NOT NULL DEFAULT '',
 //	UNIQUE(fname, char_id, char_name),
 //	FOREIGN KEY (char_id) REFERENCES character(id) ON DELETE CASCADE
 //
@@ -115,6 +162,126 @@ func (q *Queries) InsertMod(ctx context.Context, arg InsertModParams) (int64, er
 		arg.ModLink,
 		arg.GbFilename,
 		arg.GbDownloadLink,
+		arg.Flags,
+		arg.AddedAt,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const restoreMod = `-- name: RestoreMod :one
+INSERT INTO mod (
+    id,
+    fname,
+    game,
+    char_name,
+    char_id,
+    selected,
+    preview_images,
+    gb_id,
+    mod_link,
+    gb_file_name,
+    gb_download_link,
+    flags,
+    fingerprint,
+    author,
+    version,
+    description,
+    content_ratings,
+    added_at,
+    last_enabled_at,
+    size,
+    gb_updated_at,
+    favorite,
+    rating,
+    notes,
+    gb_name
+) VALUES(
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5,
+    ?6,
+    ?7,
+    ?8,
+    ?9,
+    ?10,
+    ?11,
+    ?12,
+    ?13,
+    ?14,
+    ?15,
+    ?16,
+    ?17,
+    ?18,
+    ?19,
+    ?20,
+    ?21,
+    ?22,
+    ?23,
+    ?24,
+    ?25
+)
+RETURNING id
+`
+
+type RestoreModParams struct {
+	ID             sql.NullInt64
+	Fname          string
+	Game           int64
+	CharName       string
+	CharId         int64
+	Selected       bool
+	PreviewImages  string
+	GbId           sql.NullInt64
+	ModLink        sql.NullString
+	GbFilename     sql.NullString
+	GbDownloadLink sql.NullString
+	Flags          int64
+	Fingerprint    string
+	Author         string
+	Version        string
+	Description    string
+	ContentRatings string
+	AddedAt        int64
+	LastEnabledAt  int64
+	Size           int64
+	GbUpdatedAt    int64
+	Favorite       bool
+	Rating         int64
+	Notes          string
+	GbName         string
+}
+
+func (q *Queries) RestoreMod(ctx context.Context, arg RestoreModParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, restoreMod,
+		arg.ID,
+		arg.Fname,
+		arg.Game,
+		arg.CharName,
+		arg.CharId,
+		arg.Selected,
+		arg.PreviewImages,
+		arg.GbId,
+		arg.ModLink,
+		arg.GbFilename,
+		arg.GbDownloadLink,
+		arg.Flags,
+		arg.Fingerprint,
+		arg.Author,
+		arg.Version,
+		arg.Description,
+		arg.ContentRatings,
+		arg.AddedAt,
+		arg.LastEnabledAt,
+		arg.Size,
+		arg.GbUpdatedAt,
+		arg.Favorite,
+		arg.Rating,
+		arg.Notes,
+		arg.GbName,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -122,7 +289,7 @@ func (q *Queries) InsertMod(ctx context.Context, arg InsertModParams) (int64, er
 }
 
 const selectEnabledModsForGame = `-- name: SelectEnabledModsForGame :many
-SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM mod WHERE selected AND game = ?1
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE selected AND game = ?1
 `
 
 func (q *Queries) SelectEnabledModsForGame(ctx context.Context, game int64) ([]Mod, error) {
@@ -146,6 +313,20 @@ func (q *Queries) SelectEnabledModsForGame(ctx context.Context, game int64) ([]M
 			&i.ModLink,
 			&i.GbFileName,
 			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
 		); err != nil {
 			return nil, err
 		}
@@ -161,7 +342,7 @@ func (q *Queries) SelectEnabledModsForGame(ctx context.Context, game int64) ([]M
 }
 
 const selectModByFileCharacterGame = `-- name: SelectModByFileCharacterGame :one
-SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM mod WHERE mod.fname = ?1 AND mod.game = ?2 AND mod.char_name = ?3
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.fname = ?1 AND mod.game = ?2 AND mod.char_name = ?3
 `
 
 type SelectModByFileCharacterGameParams struct {
@@ -185,12 +366,26 @@ func (q *Queries) SelectModByFileCharacterGame(ctx context.Context, arg SelectMo
 		&i.ModLink,
 		&i.GbFileName,
 		&i.GbDownloadLink,
+		&i.Flags,
+		&i.Fingerprint,
+		&i.Author,
+		&i.Version,
+		&i.Description,
+		&i.ContentRatings,
+		&i.AddedAt,
+		&i.LastEnabledAt,
+		&i.Size,
+		&i.GbUpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Notes,
+		&i.GbName,
 	)
 	return i, err
 }
 
 const selectModById = `-- name: SelectModById :one
-SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM mod WHERE mod.id = ?1 LIMIT 1
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.id = ?1 LIMIT 1
 `
 
 func (q *Queries) SelectModById(ctx context.Context, id int64) (Mod, error) {
@@ -208,21 +403,78 @@ func (q *Queries) SelectModById(ctx context.Context, id int64) (Mod, error) {
 		&i.ModLink,
 		&i.GbFileName,
 		&i.GbDownloadLink,
+		&i.Flags,
+		&i.Fingerprint,
+		&i.Author,
+		&i.Version,
+		&i.Description,
+		&i.ContentRatings,
+		&i.AddedAt,
+		&i.LastEnabledAt,
+		&i.Size,
+		&i.GbUpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Notes,
+		&i.GbName,
 	)
 	return i, err
 }
 
+const selectModFingerprintById = `-- name: SelectModFingerprintById :one
+SELECT fingerprint FROM mod WHERE mod.id = ?1
+`
+
+func (q *Queries) SelectModFingerprintById(ctx context.Context, id int64) (string, error) {
+	row := q.db.QueryRowContext(ctx, selectModFingerprintById, id)
+	var fingerprint string
+	err := row.Scan(&fingerprint)
+	return fingerprint, err
+}
+
+const selectModFingerprintsByGame = `-- name: SelectModFingerprintsByGame :many
+SELECT id, fingerprint FROM mod WHERE mod.game = ?1 AND fingerprint != ''
+`
+
+type SelectModFingerprintsByGameRow struct {
+	ID          int64
+	Fingerprint string
+}
+
+func (q *Queries) SelectModFingerprintsByGame(ctx context.Context, game int64) ([]SelectModFingerprintsByGameRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectModFingerprintsByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectModFingerprintsByGameRow
+	for rows.Next() {
+		var i SelectModFingerprintsByGameRow
+		if err := rows.Scan(&i.ID, &i.Fingerprint); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const selectModsByCharacterId = `-- name: SelectModsByCharacterId :many
-SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM mod WHERE mod.char_id = ?1 AND mod.game = ?2
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.char_id = ?1 AND mod.game = ?2
 `
 
 type SelectModsByCharacterIdParams struct {
-	Name int64
-	Game int64
+	CharId int64
+	Game   int64
 }
 
 func (q *Queries) SelectModsByCharacterId(ctx context.Context, arg SelectModsByCharacterIdParams) ([]Mod, error) {
-	rows, err := q.db.QueryContext(ctx, selectModsByCharacterId, arg.Name, arg.Game)
+	rows, err := q.db.QueryContext(ctx, selectModsByCharacterId, arg.CharId, arg.Game)
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +494,20 @@ func (q *Queries) SelectModsByCharacterId(ctx context.Context, arg SelectModsByC
 			&i.ModLink,
 			&i.GbFileName,
 			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
 		); err != nil {
 			return nil, err
 		}
@@ -257,7 +523,7 @@ func (q *Queries) SelectModsByCharacterId(ctx context.Context, arg SelectModsByC
 }
 
 const selectModsByCharacterName = `-- name: SelectModsByCharacterName :many
-SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM mod WHERE mod.char_name = ?1 AND mod.game = ?2
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.char_name = ?1 AND mod.game = ?2
 `
 
 type SelectModsByCharacterNameParams struct {
@@ -286,6 +552,73 @@ func (q *Queries) SelectModsByCharacterName(ctx context.Context, arg SelectModsB
 			&i.ModLink,
 			&i.GbFileName,
 			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModsByGame = `-- name: SelectModsByGame :many
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.game = ?1
+`
+
+func (q *Queries) SelectModsByGame(ctx context.Context, game int64) ([]Mod, error) {
+	rows, err := q.db.QueryContext(ctx, selectModsByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Mod
+	for rows.Next() {
+		var i Mod
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fname,
+			&i.Game,
+			&i.CharName,
+			&i.CharID,
+			&i.Selected,
+			&i.PreviewImages,
+			&i.GbID,
+			&i.ModLink,
+			&i.GbFileName,
+			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
 		); err != nil {
 			return nil, err
 		}
@@ -301,7 +634,7 @@ func (q *Queries) SelectModsByCharacterName(ctx context.Context, arg SelectModsB
 }
 
 const selectModsByGbId = `-- name: SelectModsByGbId :many
-SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM mod WHERE mod.gb_id = ?1
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.gb_id = ?1
 `
 
 func (q *Queries) SelectModsByGbId(ctx context.Context, gbid sql.NullInt64) ([]Mod, error) {
@@ -325,6 +658,191 @@ func (q *Queries) SelectModsByGbId(ctx context.Context, gbid sql.NullInt64) ([]M
 			&i.ModLink,
 			&i.GbFileName,
 			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModsByIds = `-- name: SelectModsByIds :many
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) SelectModsByIds(ctx context.Context, ids []int64) ([]Mod, error) {
+	query := selectModsByIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Mod
+	for rows.Next() {
+		var i Mod
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fname,
+			&i.Game,
+			&i.CharName,
+			&i.CharID,
+			&i.Selected,
+			&i.PreviewImages,
+			&i.GbID,
+			&i.ModLink,
+			&i.GbFileName,
+			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModsWithGbIdByGame = `-- name: SelectModsWithGbIdByGame :many
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE mod.game = ?1 AND mod.gb_id IS NOT NULL AND mod.gb_id > 0
+`
+
+func (q *Queries) SelectModsWithGbIdByGame(ctx context.Context, game int64) ([]Mod, error) {
+	rows, err := q.db.QueryContext(ctx, selectModsWithGbIdByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Mod
+	for rows.Next() {
+		var i Mod
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fname,
+			&i.Game,
+			&i.CharName,
+			&i.CharID,
+			&i.Selected,
+			&i.PreviewImages,
+			&i.GbID,
+			&i.ModLink,
+			&i.GbFileName,
+			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModsWithoutCharacter = `-- name: SelectModsWithoutCharacter :many
+SELECT id, fname, game, char_name, char_id, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link, flags, fingerprint, author, version, description, content_ratings, added_at, last_enabled_at, size, gb_updated_at, favorite, rating, notes, gb_name FROM mod WHERE NOT EXISTS (
+    SELECT 1 FROM character WHERE character.id = mod.char_id AND character.game = mod.game
+)
+`
+
+func (q *Queries) SelectModsWithoutCharacter(ctx context.Context) ([]Mod, error) {
+	rows, err := q.db.QueryContext(ctx, selectModsWithoutCharacter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Mod
+	for rows.Next() {
+		var i Mod
+		if err := rows.Scan(
+			&i.ID,
+			&i.Fname,
+			&i.Game,
+			&i.CharName,
+			&i.CharID,
+			&i.Selected,
+			&i.PreviewImages,
+			&i.GbID,
+			&i.ModLink,
+			&i.GbFileName,
+			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
 		); err != nil {
 			return nil, err
 		}
@@ -350,6 +868,34 @@ func (q *Queries) UpdateDisableAllModsByGame(ctx context.Context, game int64) er
 	return err
 }
 
+const updateModCharacterAndGame = `-- name: UpdateModCharacterAndGame :exec
+UPDATE mod SET
+    char_name = ?1,
+    char_id = ?2,
+    game = ?3,
+    flags = (flags & ~2) | ?4
+WHERE mod.id = ?5
+`
+
+type UpdateModCharacterAndGameParams struct {
+	CharName     string
+	CharId       int64
+	Game         int64
+	CategoryFlag int64
+	ID           int64
+}
+
+func (q *Queries) UpdateModCharacterAndGame(ctx context.Context, arg UpdateModCharacterAndGameParams) error {
+	_, err := q.db.ExecContext(ctx, updateModCharacterAndGame,
+		arg.CharName,
+		arg.CharId,
+		arg.Game,
+		arg.CategoryFlag,
+		arg.ID,
+	)
+	return err
+}
+
 const updateModEnabledById = `-- name: UpdateModEnabledById :exec
 UPDATE mod SET
     selected = ?1
@@ -366,6 +912,54 @@ func (q *Queries) UpdateModEnabledById(ctx context.Context, arg UpdateModEnabled
 	return err
 }
 
+const updateModFavorite = `-- name: UpdateModFavorite :exec
+UPDATE mod SET
+    favorite = ?1
+WHERE mod.id = ?2
+`
+
+type UpdateModFavoriteParams struct {
+	Favorite bool
+	ID       int64
+}
+
+func (q *Queries) UpdateModFavorite(ctx context.Context, arg UpdateModFavoriteParams) error {
+	_, err := q.db.ExecContext(ctx, updateModFavorite, arg.Favorite, arg.ID)
+	return err
+}
+
+const updateModFilename = `-- name: UpdateModFilename :exec
+UPDATE mod SET
+    fname = ?1
+WHERE mod.id = ?2
+`
+
+type UpdateModFilenameParams struct {
+	Fname string
+	ID    int64
+}
+
+func (q *Queries) UpdateModFilename(ctx context.Context, arg UpdateModFilenameParams) error {
+	_, err := q.db.ExecContext(ctx, updateModFilename, arg.Fname, arg.ID)
+	return err
+}
+
+const updateModFingerprint = `-- name: UpdateModFingerprint :exec
+UPDATE mod SET
+    fingerprint = ?1
+WHERE mod.id = ?2
+`
+
+type UpdateModFingerprintParams struct {
+	Fingerprint string
+	ID          int64
+}
+
+func (q *Queries) UpdateModFingerprint(ctx context.Context, arg UpdateModFingerprintParams) error {
+	_, err := q.db.ExecContext(ctx, updateModFingerprint, arg.Fingerprint, arg.ID)
+	return err
+}
+
 const updateModGbId = `-- name: UpdateModGbId :exec
 UPDATE mod SET
     gb_id = ?1
@@ -382,6 +976,40 @@ func (q *Queries) UpdateModGbId(ctx context.Context, arg UpdateModGbIdParams) er
 	return err
 }
 
+const updateModGbMetadata = `-- name: UpdateModGbMetadata :exec
+UPDATE mod SET
+    gb_name = ?1,
+    author = ?2,
+    version = ?3,
+    description = ?4,
+    content_ratings = ?5,
+    gb_updated_at = ?6
+WHERE mod.id = ?7
+`
+
+type UpdateModGbMetadataParams struct {
+	GbName         string
+	Author         string
+	Version        string
+	Description    string
+	ContentRatings string
+	GbUpdatedAt    int64
+	ID             int64
+}
+
+func (q *Queries) UpdateModGbMetadata(ctx context.Context, arg UpdateModGbMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateModGbMetadata,
+		arg.GbName,
+		arg.Author,
+		arg.Version,
+		arg.Description,
+		arg.ContentRatings,
+		arg.GbUpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
 const updateModImages = `-- name: UpdateModImages :exec
 UPDATE mod SET
     preview_images = ?1
@@ -398,6 +1026,143 @@ func (q *Queries) UpdateModImages(ctx context.Context, arg UpdateModImagesParams
 	return err
 }
 
+const updateModLocation = `-- name: UpdateModLocation :exec
+UPDATE mod SET
+    fname = ?1,
+    char_name = ?2,
+    char_id = ?3,
+    flags = (flags & ~2) | ?4
+WHERE mod.id = ?5
+`
+
+type UpdateModLocationParams struct {
+	Fname        string
+	CharName     string
+	CharId       int64
+	CategoryFlag int64
+	ID           int64
+}
+
+func (q *Queries) UpdateModLocation(ctx context.Context, arg UpdateModLocationParams) error {
+	_, err := q.db.ExecContext(ctx, updateModLocation,
+		arg.Fname,
+		arg.CharName,
+		arg.CharId,
+		arg.CategoryFlag,
+		arg.ID,
+	)
+	return err
+}
+
+const updateModMetadata = `-- name: UpdateModMetadata :exec
+UPDATE mod SET
+    selected = ?1,
+    preview_images = ?2,
+    gb_id = ?3,
+    mod_link = ?4,
+    gb_file_name = ?5,
+    gb_download_link = ?6
+WHERE mod.id = ?7
+`
+
+type UpdateModMetadataParams struct {
+	Selected       bool
+	PreviewImages  string
+	GbId           sql.NullInt64
+	ModLink        sql.NullString
+	GbFilename     sql.NullString
+	GbDownloadLink sql.NullString
+	ID             int64
+}
+
+func (q *Queries) UpdateModMetadata(ctx context.Context, arg UpdateModMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateModMetadata,
+		arg.Selected,
+		arg.PreviewImages,
+		arg.GbId,
+		arg.ModLink,
+		arg.GbFilename,
+		arg.GbDownloadLink,
+		arg.ID,
+	)
+	return err
+}
+
+const updateModNotes = `-- name: UpdateModNotes :exec
+UPDATE mod SET
+    notes = ?1
+WHERE mod.id = ?2
+`
+
+type UpdateModNotesParams struct {
+	Notes string
+	ID    int64
+}
+
+func (q *Queries) UpdateModNotes(ctx context.Context, arg UpdateModNotesParams) error {
+	_, err := q.db.ExecContext(ctx, updateModNotes, arg.Notes, arg.ID)
+	return err
+}
+
+const updateModRating = `-- name: UpdateModRating :exec
+UPDATE mod SET
+    rating = ?1
+WHERE mod.id = ?2
+`
+
+type UpdateModRatingParams struct {
+	Rating int64
+	ID     int64
+}
+
+func (q *Queries) UpdateModRating(ctx context.Context, arg UpdateModRatingParams) error {
+	_, err := q.db.ExecContext(ctx, updateModRating, arg.Rating, arg.ID)
+	return err
+}
+
+const updateModSize = `-- name: UpdateModSize :exec
+UPDATE mod SET
+    size = ?1
+WHERE mod.id = ?2
+`
+
+type UpdateModSizeParams struct {
+	Size int64
+	ID   int64
+}
+
+func (q *Queries) UpdateModSize(ctx context.Context, arg UpdateModSizeParams) error {
+	_, err := q.db.ExecContext(ctx, updateModSize, arg.Size, arg.ID)
+	return err
+}
+
+const updateModsEnabledByIds = `-- name: UpdateModsEnabledByIds :exec
+UPDATE mod SET
+    selected = ?1
+WHERE mod.id IN (/*SLICE:ids*/?)
+`
+
+type UpdateModsEnabledByIdsParams struct {
+	Selected bool
+	Ids      []int64
+}
+
+func (q *Queries) UpdateModsEnabledByIds(ctx context.Context, arg UpdateModsEnabledByIdsParams) error {
+	query := updateModsEnabledByIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.Selected)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
 const updateModsEnabledFromSlice = `-- name: UpdateModsEnabledFromSlice :exec
 UPDATE mod SET 
     selected = CASE WHEN mod.id IN (/*SLICE:enabled*/?)
@@ -427,3 +1192,30 @@ func (q *Queries) UpdateModsEnabledFromSlice(ctx context.Context, arg UpdateMods
 	_, err := q.db.ExecContext(ctx, query, queryParams...)
 	return err
 }
+
+const updateModsLastEnabledAt = `-- name: UpdateModsLastEnabledAt :exec
+UPDATE mod SET
+    last_enabled_at = ?1
+WHERE mod.id IN (/*SLICE:ids*/?)
+`
+
+type UpdateModsLastEnabledAtParams struct {
+	LastEnabledAt int64
+	Ids           []int64
+}
+
+func (q *Queries) UpdateModsLastEnabledAt(ctx context.Context, arg UpdateModsLastEnabledAtParams) error {
+	query := updateModsLastEnabledAt
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.LastEnabledAt)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
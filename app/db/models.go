@@ -8,6 +8,12 @@ import (
 	"database/sql"
 )
 
+type ArchivePassword struct {
+	Scope    int64
+	ScopeID  int64
+	Password string
+}
+
 type Character struct {
 	ID        int64
 	Game      int64
@@ -15,11 +21,22 @@ type Character struct {
 	AvatarUrl string
 	Element   string
 	Flags     int64
+	Category  int64
+	Exclusive int64
+}
+
+type CharacterAlias struct {
+	Game     int64
+	CharID   int64
+	Alias    string
+	Custom   bool
+	Disabled bool
 }
 
 type Inicache struct {
-	ModID int64
-	Fname string
+	ModID       int64
+	Fname       string
+	Fingerprint string
 }
 
 type Mod struct {
@@ -34,6 +51,42 @@ type Mod struct {
 	ModLink        sql.NullString
 	GbFileName     sql.NullString
 	GbDownloadLink sql.NullString
+	Flags          int64
+	Fingerprint    string
+	Author         string
+	Version        string
+	Description    string
+	ContentRatings string
+	AddedAt        int64
+	LastEnabledAt  int64
+	Size           int64
+	GbUpdatedAt    int64
+	Favorite       bool
+	Rating         int64
+	Notes          string
+	GbName         string
+}
+
+type ModDependency struct {
+	ModID  int64
+	Kind   int64
+	DepID  int64
+	Source int64
+}
+
+type ModNamespace struct {
+	ModID       int64
+	Fingerprint string
+	Declared    string
+	Referenced  string
+}
+
+type ModUsage struct {
+	ModID          int64
+	GenerateCount  int64
+	EnabledSeconds int64
+	ActiveSince    int64
+	LastExportedAt int64
 }
 
 type Playlist struct {
@@ -47,6 +100,19 @@ type PlaylistModCrossRef struct {
 	ModID      int64
 }
 
+type Quarantine struct {
+	ModID   int64
+	Path    string
+	Allowed bool
+}
+
+type SyncPendingDelete struct {
+	Kind  int64
+	RowID int64
+	Game  int64
+	Since int64
+}
+
 type Tag struct {
 	ModID   int64
 	TagName string
@@ -19,6 +19,38 @@ func (q *Queries) DeletePlaylistById(ctx context.Context, id int64) error {
 	return err
 }
 
+const deletePlaylistModCrossRef = `-- name: DeletePlaylistModCrossRef :exec
+DELETE FROM playlist_mod_cross_ref WHERE playlist_id = ?1 AND mod_id = ?2
+`
+
+type DeletePlaylistModCrossRefParams struct {
+	PlaylistId int64
+	ModId      int64
+}
+
+func (q *Queries) DeletePlaylistModCrossRef(ctx context.Context, arg DeletePlaylistModCrossRefParams) error {
+	_, err := q.db.ExecContext(ctx, deletePlaylistModCrossRef, arg.PlaylistId, arg.ModId)
+	return err
+}
+
+const deletePlaylistModCrossRefsByModId = `-- name: DeletePlaylistModCrossRefsByModId :exec
+DELETE FROM playlist_mod_cross_ref WHERE mod_id = ?1
+`
+
+func (q *Queries) DeletePlaylistModCrossRefsByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deletePlaylistModCrossRefsByModId, modid)
+	return err
+}
+
+const deletePlaylistModCrossRefsByPlaylistId = `-- name: DeletePlaylistModCrossRefsByPlaylistId :exec
+DELETE FROM playlist_mod_cross_ref WHERE playlist_id = ?1
+`
+
+func (q *Queries) DeletePlaylistModCrossRefsByPlaylistId(ctx context.Context, playlistid int64) error {
+	_, err := q.db.ExecContext(ctx, deletePlaylistModCrossRefsByPlaylistId, playlistid)
+	return err
+}
+
 const enableModsForPlaylist = `-- name: EnableModsForPlaylist :exec
 UPDATE mod SET
     selected = TRUE
@@ -81,12 +113,163 @@ func (q *Queries) InsertPlaylist(ctx context.Context, arg InsertPlaylistParams)
 	return id, err
 }
 
+const mergePlaylistModCrossRefs = `-- name: MergePlaylistModCrossRefs :exec
+INSERT OR IGNORE INTO playlist_mod_cross_ref(playlist_id, mod_id)
+SELECT playlist_id, ?1 FROM playlist_mod_cross_ref WHERE playlist_mod_cross_ref.mod_id = ?2
+`
+
+type MergePlaylistModCrossRefsParams struct {
+	KeepId   int64
+	RemoveId int64
+}
+
+func (q *Queries) MergePlaylistModCrossRefs(ctx context.Context, arg MergePlaylistModCrossRefsParams) error {
+	_, err := q.db.ExecContext(ctx, mergePlaylistModCrossRefs, arg.KeepId, arg.RemoveId)
+	return err
+}
+
+const restorePlaylist = `-- name: RestorePlaylist :exec
+INSERT INTO playlist(id, playlist_name, game) VALUES(?1, ?2, ?3)
+`
+
+type RestorePlaylistParams struct {
+	ID   int64
+	Name string
+	Game int64
+}
+
+func (q *Queries) RestorePlaylist(ctx context.Context, arg RestorePlaylistParams) error {
+	_, err := q.db.ExecContext(ctx, restorePlaylist, arg.ID, arg.Name, arg.Game)
+	return err
+}
+
+const restorePlaylistModCrossRef = `-- name: RestorePlaylistModCrossRef :exec
+INSERT OR IGNORE INTO playlist_mod_cross_ref(playlist_id, mod_id)
+SELECT id, ?1 FROM playlist WHERE playlist.id = ?2
+`
+
+type RestorePlaylistModCrossRefParams struct {
+	ModId      int64
+	PlaylistId int64
+}
+
+func (q *Queries) RestorePlaylistModCrossRef(ctx context.Context, arg RestorePlaylistModCrossRefParams) error {
+	_, err := q.db.ExecContext(ctx, restorePlaylistModCrossRef, arg.ModId, arg.PlaylistId)
+	return err
+}
+
+const selectPlaylistById = `-- name: SelectPlaylistById :one
+SELECT id, playlist_name, game FROM playlist WHERE id = ?1
+`
+
+func (q *Queries) SelectPlaylistById(ctx context.Context, id int64) (Playlist, error) {
+	row := q.db.QueryRowContext(ctx, selectPlaylistById, id)
+	var i Playlist
+	err := row.Scan(&i.ID, &i.PlaylistName, &i.Game)
+	return i, err
+}
+
+const selectPlaylistIdsByModId = `-- name: SelectPlaylistIdsByModId :many
+SELECT playlist_id FROM playlist_mod_cross_ref WHERE mod_id = ?1
+`
+
+func (q *Queries) SelectPlaylistIdsByModId(ctx context.Context, modid int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, selectPlaylistIdsByModId, modid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var playlist_id int64
+		if err := rows.Scan(&playlist_id); err != nil {
+			return nil, err
+		}
+		items = append(items, playlist_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectPlaylistModCrossRefsWithoutMod = `-- name: SelectPlaylistModCrossRefsWithoutMod :many
+SELECT playlist.id, playlist.playlist_name, playlist.game, playlist_mod_cross_ref.mod_id FROM playlist_mod_cross_ref
+JOIN playlist ON playlist.id = playlist_mod_cross_ref.playlist_id
+WHERE NOT EXISTS (SELECT 1 FROM mod WHERE mod.id = playlist_mod_cross_ref.mod_id)
+`
+
+type SelectPlaylistModCrossRefsWithoutModRow struct {
+	ID           int64
+	PlaylistName string
+	Game         int64
+	ModID        int64
+}
+
+func (q *Queries) SelectPlaylistModCrossRefsWithoutMod(ctx context.Context) ([]SelectPlaylistModCrossRefsWithoutModRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectPlaylistModCrossRefsWithoutMod)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectPlaylistModCrossRefsWithoutModRow
+	for rows.Next() {
+		var i SelectPlaylistModCrossRefsWithoutModRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PlaylistName,
+			&i.Game,
+			&i.ModID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectPlaylistModIds = `-- name: SelectPlaylistModIds :many
+SELECT mod_id FROM playlist_mod_cross_ref WHERE playlist_id = ?1
+`
+
+func (q *Queries) SelectPlaylistModIds(ctx context.Context, playlistid int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, selectPlaylistModIds, playlistid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var mod_id int64
+		if err := rows.Scan(&mod_id); err != nil {
+			return nil, err
+		}
+		items = append(items, mod_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const selectPlaylistWithModsAndTags = `-- name: SelectPlaylistWithModsAndTags :many
 
 
 SELECT 
     p.id, p.playlist_name, p.game,
-    m.id, m.fname, m.game, m.char_name, m.char_id, m.selected, m.preview_images, m.gb_id, m.mod_link, m.gb_file_name, m.gb_download_link,
+    m.id, m.fname, m.game, m.char_name, m.char_id, m.selected, m.preview_images, m.gb_id, m.mod_link, m.gb_file_name, m.gb_download_link, m.flags, m.fingerprint, m.author, m.version, m.description, m.content_ratings, m.added_at, m.last_enabled_at, m.size, m.gb_updated_at, m.favorite, m.rating, m.notes, m.gb_name,
     t.mod_id, t.tag_name
 FROM 
     playlist p
@@ -115,6 +298,20 @@ type SelectPlaylistWithModsAndTagsRow struct {
 	ModLink        sql.NullString
 	GbFileName     sql.NullString
 	GbDownloadLink sql.NullString
+	Flags          int64
+	Fingerprint    string
+	Author         string
+	Version        string
+	Description    string
+	ContentRatings string
+	AddedAt        int64
+	LastEnabledAt  int64
+	Size           int64
+	GbUpdatedAt    int64
+	Favorite       bool
+	Rating         int64
+	Notes          string
+	GbName         string
 	ModID          sql.NullInt64
 	TagName        sql.NullString
 }
@@ -159,6 +356,20 @@ func (q *Queries) SelectPlaylistWithModsAndTags(ctx context.Context, game int64)
 			&i.ModLink,
 			&i.GbFileName,
 			&i.GbDownloadLink,
+			&i.Flags,
+			&i.Fingerprint,
+			&i.Author,
+			&i.Version,
+			&i.Description,
+			&i.ContentRatings,
+			&i.AddedAt,
+			&i.LastEnabledAt,
+			&i.Size,
+			&i.GbUpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Notes,
+			&i.GbName,
 			&i.ModID,
 			&i.TagName,
 		); err != nil {
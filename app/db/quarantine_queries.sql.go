@@ -0,0 +1,101 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: quarantine_queries.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteQuarantinedFilesByModId = `-- name: DeleteQuarantinedFilesByModId :exec
+DELETE FROM quarantine WHERE mod_id = ?1
+`
+
+func (q *Queries) DeleteQuarantinedFilesByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteQuarantinedFilesByModId, modid)
+	return err
+}
+
+const insertQuarantinedFile = `-- name: InsertQuarantinedFile :exec
+
+INSERT OR IGNORE INTO quarantine(mod_id, path) VALUES(?1, ?2)
+`
+
+type InsertQuarantinedFileParams struct {
+	ModId int64
+	Path  string
+}
+
+// quarantine(
+//
+//	mod_id INTEGER NOT NULL,
+//	path TEXT NOT NULL,
+//	allowed BOOLEAN NOT NULL DEFAULT FALSE,
+//	PRIMARY KEY(mod_id, path),
+//	FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+//
+// );
+func (q *Queries) InsertQuarantinedFile(ctx context.Context, arg InsertQuarantinedFileParams) error {
+	_, err := q.db.ExecContext(ctx, insertQuarantinedFile, arg.ModId, arg.Path)
+	return err
+}
+
+const selectQuarantinedFiles = `-- name: SelectQuarantinedFiles :many
+SELECT mod_id, path, allowed FROM quarantine WHERE mod_id = ?1 ORDER BY path
+`
+
+func (q *Queries) SelectQuarantinedFiles(ctx context.Context, modid int64) ([]Quarantine, error) {
+	rows, err := q.db.QueryContext(ctx, selectQuarantinedFiles, modid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Quarantine
+	for rows.Next() {
+		var i Quarantine
+		if err := rows.Scan(&i.ModID, &i.Path, &i.Allowed); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateModQuarantineFlag = `-- name: UpdateModQuarantineFlag :exec
+UPDATE mod SET
+    flags = CASE WHEN EXISTS(SELECT 1 FROM quarantine WHERE quarantine.mod_id = mod.id AND NOT quarantine.allowed)
+        THEN flags | 1
+        ELSE flags & ~1
+    END
+WHERE mod.id = ?1
+`
+
+func (q *Queries) UpdateModQuarantineFlag(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, updateModQuarantineFlag, modid)
+	return err
+}
+
+const updateQuarantineAllowed = `-- name: UpdateQuarantineAllowed :exec
+UPDATE quarantine SET
+    allowed = ?1
+WHERE mod_id = ?2 AND path = ?3
+`
+
+type UpdateQuarantineAllowedParams struct {
+	Allowed bool
+	ModId   int64
+	Path    string
+}
+
+func (q *Queries) UpdateQuarantineAllowed(ctx context.Context, arg UpdateQuarantineAllowedParams) error {
+	_, err := q.db.ExecContext(ctx, updateQuarantineAllowed, arg.Allowed, arg.ModId, arg.Path)
+	return err
+}
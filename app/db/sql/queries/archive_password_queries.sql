@@ -0,0 +1,18 @@
+-- archive_password(
+--     scope INTEGER NOT NULL,
+--     scope_id INTEGER NOT NULL,
+--     password TEXT NOT NULL,
+--     PRIMARY KEY(scope, scope_id, password)
+-- );
+
+-- name: InsertArchivePassword :exec
+INSERT OR IGNORE INTO archive_password(scope, scope_id, password) VALUES(:scope, :scopeId, :password);
+
+-- name: SelectArchivePasswords :many
+SELECT password FROM archive_password WHERE scope = :scope AND scope_id = :scopeId;
+
+-- name: DeleteArchivePassword :exec
+DELETE FROM archive_password WHERE scope = :scope AND scope_id = :scopeId AND password = :password;
+
+-- name: DeleteArchivePasswordsByScope :exec
+DELETE FROM archive_password WHERE scope = :scope AND scope_id = :scopeId;
@@ -0,0 +1,33 @@
+-- character_alias(
+--     game INTEGER NOT NULL,
+--     char_id INTEGER NOT NULL,
+--     alias TEXT NOT NULL COLLATE NOCASE,
+--     custom BOOLEAN NOT NULL DEFAULT FALSE,
+--     disabled BOOLEAN NOT NULL DEFAULT FALSE,
+--     PRIMARY KEY(game, alias),
+--     FOREIGN KEY (char_id, game) REFERENCES character(id, game) ON DELETE CASCADE
+-- );
+
+-- name: SelectCharacterAliasesByGame :many
+SELECT * FROM character_alias WHERE game = :game ORDER BY char_id, alias;
+
+-- name: UpsertCustomCharacterAlias :exec
+INSERT INTO character_alias(game, char_id, alias, custom, disabled)
+VALUES (:game, :charId, :alias, TRUE, FALSE)
+ON CONFLICT(game, alias) DO UPDATE SET
+    char_id = excluded.char_id,
+    custom = TRUE,
+    disabled = FALSE;
+
+-- name: InsertBundledCharacterAlias :exec
+INSERT INTO character_alias(game, char_id, alias, custom, disabled)
+VALUES (:game, :charId, :alias, FALSE, FALSE)
+ON CONFLICT(game, alias) DO NOTHING;
+
+-- name: DeleteCustomCharacterAlias :exec
+DELETE FROM character_alias WHERE game = :game AND alias = :alias AND custom = TRUE;
+
+-- name: DisableCharacterAlias :exec
+UPDATE character_alias SET
+    disabled = TRUE
+WHERE game = :game AND alias = :alias;
@@ -5,6 +5,8 @@
 --     avatar_url TEXT NOT NULL DEFAULT '',
 --     element TEXT NOT NULL,
 --     flags INT NOT NULL DEFAULT 0,
+--     category INTEGER NOT NULL DEFAULT 0,
+--     exclusive INTEGER NOT NULL DEFAULT 0,
 --     PRIMARY KEY(id, game)
 -- );
 
@@ -17,6 +19,9 @@ SELECT * FROM character WHERE LOWER(name) LIKE '%' || LOWER(:name) || '%' AND ga
 -- name: SelectCharactersByGame :many
 SELECT * FROM character WHERE game = :game;
 
+-- name: SelectCategoriesByGame :many
+SELECT * FROM character WHERE game = :game AND category != 0;
+
 -- name: SelectCharactersWithModsAndTags :many
 SELECT 
     c.*,
@@ -33,6 +38,7 @@ WHERE c.game = :game
 AND (
         (
             m.fname LIKE '%' || :modFileName || '%'
+            OR m.notes LIKE '%' || :modFileName || '%'
             OR c.name LIKE '%' || :characterName || '%'
             OR t.tag_name LIKE '%' || :tagName || '%'
         ) OR (
@@ -48,6 +54,13 @@ ORDER BY
 SELECT * FROM character WHERE LOWER(name) LIKE '%' || LOWER(:name) || '%' AND game = :game LIMIT 1;
 
 
+-- name: UpdateCharacterExclusive :exec
+UPDATE character SET
+    exclusive = :exclusive
+WHERE id = :id AND game = :game;
+
 -- name: DeleteCharacterById :exec
 DELETE FROM character 
-WHERE id = :id;
\ No newline at end of file
+WHERE id = :id;
+-- name: SelectCharactersByIds :many
+SELECT * FROM character WHERE game = :game AND id IN (sqlc.slice('ids')) ORDER BY name;
@@ -0,0 +1,41 @@
+-- mod_dependency(
+--     mod_id INTEGER NOT NULL,
+--     kind INTEGER NOT NULL,
+--     dep_id INTEGER NOT NULL,
+--     source INTEGER NOT NULL DEFAULT 0,
+--     PRIMARY KEY(mod_id, kind, dep_id),
+--     FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+-- );
+
+-- name: InsertModDependency :exec
+INSERT OR IGNORE INTO mod_dependency(mod_id, kind, dep_id, source) VALUES(:modId, :kind, :depId, :source);
+
+-- name: SelectModDependencies :many
+SELECT * FROM mod_dependency WHERE mod_id = :modId ORDER BY kind, dep_id;
+
+-- name: SelectModDependenciesByGame :many
+SELECT mod_dependency.* FROM mod_dependency
+JOIN mod ON mod.id = mod_dependency.mod_id
+WHERE mod.game = :game;
+
+-- name: DeleteModDependency :exec
+DELETE FROM mod_dependency WHERE mod_id = :modId AND kind = :kind AND dep_id = :depId;
+
+-- name: DeleteModDependenciesBySource :exec
+DELETE FROM mod_dependency WHERE mod_id = :modId AND source = :source;
+
+-- name: DeleteModDependenciesByModId :exec
+DELETE FROM mod_dependency WHERE mod_id = :modId OR (kind = 0 AND dep_id = :modId);
+
+-- name: MergeModDependencies :exec
+INSERT OR IGNORE INTO mod_dependency(mod_id, kind, dep_id, source)
+SELECT :keepId, d.kind, d.dep_id, d.source FROM mod_dependency d
+WHERE d.mod_id = :removeId AND (d.kind != 0 OR d.dep_id != :keepId);
+
+-- name: MergeModDependents :exec
+INSERT OR IGNORE INTO mod_dependency(mod_id, kind, dep_id, source)
+SELECT d.mod_id, d.kind, :keepId, d.source FROM mod_dependency d
+WHERE d.kind = 0 AND d.dep_id = :removeId AND d.mod_id != :keepId;
+
+-- name: SelectModDependenciesInvolving :many
+SELECT * FROM mod_dependency WHERE mod_id = :modId OR (kind = 0 AND dep_id = :modId);
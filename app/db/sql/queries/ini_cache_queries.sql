@@ -1,15 +1,23 @@
-
 -- CREATE TABLE IF NOT EXISTS inicache (
---     mod_id INTEGER PRIMARY KEY NOT NULL,
+--     mod_id INTEGER NOT NULL,
 --     fname TEXT NOT NULL,
+--     fingerprint TEXT NOT NULL DEFAULT '',
+--     PRIMARY KEY (mod_id, fname),
 --     FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
 -- );
 
--- name: SelectIniCacheByModId :one
-SELECT * FROM inicache WHERE mod_id = :modId;
+-- name: SelectIniCacheByModId :many
+SELECT * FROM inicache WHERE mod_id = :modId ORDER BY fname;
+
+-- name: InsertIniCacheEntry :exec
+INSERT INTO inicache(mod_id, fname, fingerprint)
+VALUES (:modId, :fName, :fingerprint)
+ON CONFLICT(mod_id, fname) DO UPDATE SET
+  fingerprint = excluded.fingerprint;
+
+-- name: SelectIniCacheWithMods :many
+SELECT sqlc.embed(mod), inicache.fname AS ini_fname FROM inicache
+JOIN mod ON mod.id = inicache.mod_id;
 
--- name: InsertOrUpdateEntry :exec
-INSERT INTO inicache(mod_id, fname)
-VALUES (:modId, :fName)
-ON CONFLICT(mod_id) DO UPDATE SET
-  fname = excluded.fname;
\ No newline at end of file
+-- name: DeleteIniCacheByModId :exec
+DELETE FROM inicache WHERE mod_id = :modId;
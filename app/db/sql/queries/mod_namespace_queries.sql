@@ -0,0 +1,20 @@
+-- CREATE TABLE IF NOT EXISTS mod_namespace(
+--     mod_id INTEGER PRIMARY KEY NOT NULL,
+--     fingerprint TEXT NOT NULL DEFAULT '',
+--     declared TEXT NOT NULL DEFAULT '',
+--     referenced TEXT NOT NULL DEFAULT '',
+--     FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+-- );
+
+-- name: SelectModNamespacesByGame :many
+SELECT mod_namespace.* FROM mod_namespace
+JOIN mod ON mod.id = mod_namespace.mod_id
+WHERE mod.game = :game;
+
+-- name: UpsertModNamespace :exec
+INSERT INTO mod_namespace(mod_id, fingerprint, declared, referenced)
+VALUES (:modId, :fingerprint, :declared, :referenced)
+ON CONFLICT(mod_id) DO UPDATE SET
+  fingerprint = excluded.fingerprint,
+  declared = excluded.declared,
+  referenced = excluded.referenced;
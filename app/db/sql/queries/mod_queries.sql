@@ -11,6 +11,20 @@
 --    mod_link TEXT,
 --    gb_file_name TEXT,
 --    gb_download_link TEXT,
+--    flags INTEGER NOT NULL DEFAULT 0,
+--    fingerprint TEXT NOT NULL DEFAULT '',
+--    author TEXT NOT NULL DEFAULT '',
+--    version TEXT NOT NULL DEFAULT '',
+--    description TEXT NOT NULL DEFAULT '',
+--    content_ratings TEXT NOT NULL DEFAULT '',
+--    added_at INTEGER NOT NULL DEFAULT 0,
+--    last_enabled_at INTEGER NOT NULL DEFAULT 0,
+--    size INTEGER NOT NULL DEFAULT 0,
+--    gb_updated_at INTEGER NOT NULL DEFAULT 0,
+--    favorite BOOLEAN NOT NULL DEFAULT FALSE,
+--    rating INTEGER NOT NULL DEFAULT 0,
+--    notes TEXT NOT NULL DEFAULT '',
+--    gb_name TEXT NOT NULL DEFAULT '',
 --    UNIQUE(fname, char_id, char_name),
 --    FOREIGN KEY (char_id) REFERENCES character(id) ON DELETE CASCADE
 -- );
@@ -25,7 +39,9 @@ INSERT INTO mod (
     preview_images, 
     gb_id, mod_link, 
     gb_file_name, 
-    gb_download_link
+    gb_download_link,
+    flags,
+    added_at
 ) VALUES(
     :modFilename,
     :game,
@@ -36,7 +52,9 @@ INSERT INTO mod (
     :gbId,
     :modLink,
     :gbFilename,
-    :gbDownloadLink
+    :gbDownloadLink,
+    :flags,
+    :addedAt
 )
 ON CONFLICT(fname, char_id, char_name) DO NOTHING
 RETURNING id;
@@ -45,7 +63,10 @@ RETURNING id;
 SELECT * FROM mod WHERE mod.char_name = :name AND mod.game = :game;
 
 -- name: SelectModsByCharacterId :many
-SELECT * FROM mod WHERE mod.char_id = :name AND mod.game = :game;
+SELECT * FROM mod WHERE mod.char_id = :charId AND mod.game = :game;
+
+-- name: SelectModsByGame :many
+SELECT * FROM mod WHERE mod.game = :game;
 
 -- name: SelectModById :one
 SELECT * FROM mod WHERE mod.id = :id LIMIT 1;
@@ -65,6 +86,14 @@ DELETE FROM mod WHERE mod.id = :id;
 -- name: DeleteUnusedMods :exec
 DELETE FROM mod WHERE fname NOT IN sqlc.slice('files') AND game = :game;
 
+-- name: DeleteUnusedModsByCharacter :exec
+DELETE FROM mod WHERE fname NOT IN sqlc.slice('files') AND char_name = :characterName AND game = :game;
+
+-- name: UpdateModFilename :exec
+UPDATE mod SET
+    fname = :fname
+WHERE mod.id = :id;
+
 -- name: UpdateModGbId :exec
 UPDATE mod SET
     gb_id = :gbId
@@ -92,4 +121,148 @@ UPDATE mod SET
         THEN TRUE
         ELSE FALSE
     END
-WHERE mod.game = ?;
\ No newline at end of file
+WHERE mod.game = ?;
+
+-- name: UpdateModMetadata :exec
+UPDATE mod SET
+    selected = :selected,
+    preview_images = :previewImages,
+    gb_id = :gbId,
+    mod_link = :modLink,
+    gb_file_name = :gbFilename,
+    gb_download_link = :gbDownloadLink
+WHERE mod.id = :id;
+
+-- name: UpdateModLocation :exec
+UPDATE mod SET
+    fname = :fname,
+    char_name = :charName,
+    char_id = :charId,
+    flags = (flags & ~2) | :categoryFlag
+WHERE mod.id = :id;
+
+-- name: UpdateModFingerprint :exec
+UPDATE mod SET
+    fingerprint = :fingerprint
+WHERE mod.id = :id;
+
+-- name: SelectModFingerprintsByGame :many
+SELECT id, fingerprint FROM mod WHERE mod.game = :game AND fingerprint != '';
+
+-- name: SelectModFingerprintById :one
+SELECT fingerprint FROM mod WHERE mod.id = :id;
+
+-- name: UpdateModCharacterAndGame :exec
+UPDATE mod SET
+    char_name = :charName,
+    char_id = :charId,
+    game = :game,
+    flags = (flags & ~2) | :categoryFlag
+WHERE mod.id = :id;
+
+-- name: RestoreMod :one
+INSERT INTO mod (
+    id,
+    fname,
+    game,
+    char_name,
+    char_id,
+    selected,
+    preview_images,
+    gb_id,
+    mod_link,
+    gb_file_name,
+    gb_download_link,
+    flags,
+    fingerprint,
+    author,
+    version,
+    description,
+    content_ratings,
+    added_at,
+    last_enabled_at,
+    size,
+    gb_updated_at,
+    favorite,
+    rating,
+    notes,
+    gb_name
+) VALUES(
+    sqlc.narg('id'),
+    :fname,
+    :game,
+    :charName,
+    :charId,
+    :selected,
+    :previewImages,
+    :gbId,
+    :modLink,
+    :gbFilename,
+    :gbDownloadLink,
+    :flags,
+    :fingerprint,
+    :author,
+    :version,
+    :description,
+    :contentRatings,
+    :addedAt,
+    :lastEnabledAt,
+    :size,
+    :gbUpdatedAt,
+    :favorite,
+    :rating,
+    :notes,
+    :gbName
+)
+RETURNING id;
+
+-- name: UpdateModsEnabledByIds :exec
+UPDATE mod SET
+    selected = :selected
+WHERE mod.id IN (sqlc.slice('ids'));
+
+-- name: UpdateModGbMetadata :exec
+UPDATE mod SET
+    gb_name = :gbName,
+    author = :author,
+    version = :version,
+    description = :description,
+    content_ratings = :contentRatings,
+    gb_updated_at = :gbUpdatedAt
+WHERE mod.id = :id;
+
+-- name: UpdateModSize :exec
+UPDATE mod SET
+    size = :size
+WHERE mod.id = :id;
+
+-- name: UpdateModsLastEnabledAt :exec
+UPDATE mod SET
+    last_enabled_at = :lastEnabledAt
+WHERE mod.id IN (sqlc.slice('ids'));
+
+-- name: UpdateModFavorite :exec
+UPDATE mod SET
+    favorite = :favorite
+WHERE mod.id = :id;
+
+-- name: UpdateModRating :exec
+UPDATE mod SET
+    rating = :rating
+WHERE mod.id = :id;
+
+-- name: UpdateModNotes :exec
+UPDATE mod SET
+    notes = :notes
+WHERE mod.id = :id;
+
+-- name: SelectModsWithGbIdByGame :many
+SELECT * FROM mod WHERE mod.game = :game AND mod.gb_id IS NOT NULL AND mod.gb_id > 0;
+
+-- name: SelectModsByIds :many
+SELECT * FROM mod WHERE mod.id IN (sqlc.slice('ids'));
+
+-- name: SelectModsWithoutCharacter :many
+SELECT * FROM mod WHERE NOT EXISTS (
+    SELECT 1 FROM character WHERE character.id = mod.char_id AND character.game = mod.game
+);
@@ -64,4 +64,38 @@ UPDATE playlist SET
 WHERE id = :id;
 
 -- name: DeletePlaylistById :exec
-DELETE FROM playlist WHERE id = :id;
\ No newline at end of file
+DELETE FROM playlist WHERE id = :id;
+
+-- name: MergePlaylistModCrossRefs :exec
+INSERT OR IGNORE INTO playlist_mod_cross_ref(playlist_id, mod_id)
+SELECT playlist_id, :keepId FROM playlist_mod_cross_ref WHERE playlist_mod_cross_ref.mod_id = :removeId;
+
+-- name: DeletePlaylistModCrossRefsByModId :exec
+DELETE FROM playlist_mod_cross_ref WHERE mod_id = :modId;
+
+-- name: SelectPlaylistIdsByModId :many
+SELECT playlist_id FROM playlist_mod_cross_ref WHERE mod_id = :modId;
+
+-- name: RestorePlaylistModCrossRef :exec
+INSERT OR IGNORE INTO playlist_mod_cross_ref(playlist_id, mod_id)
+SELECT id, :modId FROM playlist WHERE playlist.id = :playlistId;
+
+-- name: SelectPlaylistById :one
+SELECT * FROM playlist WHERE id = :id;
+
+-- name: RestorePlaylist :exec
+INSERT INTO playlist(id, playlist_name, game) VALUES(:id, :name, :game);
+
+-- name: DeletePlaylistModCrossRefsByPlaylistId :exec
+DELETE FROM playlist_mod_cross_ref WHERE playlist_id = :playlistId;
+
+-- name: SelectPlaylistModIds :many
+SELECT mod_id FROM playlist_mod_cross_ref WHERE playlist_id = :playlistId;
+
+-- name: SelectPlaylistModCrossRefsWithoutMod :many
+SELECT playlist.id, playlist.playlist_name, playlist.game, playlist_mod_cross_ref.mod_id FROM playlist_mod_cross_ref
+JOIN playlist ON playlist.id = playlist_mod_cross_ref.playlist_id
+WHERE NOT EXISTS (SELECT 1 FROM mod WHERE mod.id = playlist_mod_cross_ref.mod_id);
+
+-- name: DeletePlaylistModCrossRef :exec
+DELETE FROM playlist_mod_cross_ref WHERE playlist_id = :playlistId AND mod_id = :modId;
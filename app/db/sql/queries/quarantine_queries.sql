@@ -0,0 +1,29 @@
+-- quarantine(
+--     mod_id INTEGER NOT NULL,
+--     path TEXT NOT NULL,
+--     allowed BOOLEAN NOT NULL DEFAULT FALSE,
+--     PRIMARY KEY(mod_id, path),
+--     FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+-- );
+
+-- name: InsertQuarantinedFile :exec
+INSERT OR IGNORE INTO quarantine(mod_id, path) VALUES(:modId, :path);
+
+-- name: SelectQuarantinedFiles :many
+SELECT * FROM quarantine WHERE mod_id = :modId ORDER BY path;
+
+-- name: UpdateQuarantineAllowed :exec
+UPDATE quarantine SET
+    allowed = :allowed
+WHERE mod_id = :modId AND path = :path;
+
+-- name: UpdateModQuarantineFlag :exec
+UPDATE mod SET
+    flags = CASE WHEN EXISTS(SELECT 1 FROM quarantine WHERE quarantine.mod_id = mod.id AND NOT quarantine.allowed)
+        THEN flags | 1
+        ELSE flags & ~1
+    END
+WHERE mod.id = :modId;
+
+-- name: DeleteQuarantinedFilesByModId :exec
+DELETE FROM quarantine WHERE mod_id = :modId;
@@ -0,0 +1,46 @@
+-- sync_pending_delete(
+--     kind INTEGER NOT NULL,
+--     row_id INTEGER NOT NULL,
+--     game INTEGER NOT NULL,
+--     since INTEGER NOT NULL,
+--     PRIMARY KEY(kind, row_id)
+-- );
+
+-- name: InsertPendingDelete :exec
+INSERT OR IGNORE INTO sync_pending_delete(kind, row_id, game, since) VALUES(:kind, :rowId, :game, :since);
+
+-- name: SelectPendingDeletesByGame :many
+SELECT * FROM sync_pending_delete WHERE game = :game;
+
+-- name: DeletePendingDelete :exec
+DELETE FROM sync_pending_delete WHERE kind = :kind AND row_id = :rowId;
+
+-- name: SelectModIdsWithUserData :many
+SELECT mod.id FROM mod WHERE mod.game = :game AND (
+    mod.gb_id IS NOT NULL
+    OR mod.mod_link IS NOT NULL
+    OR mod.preview_images != ''
+    OR EXISTS(SELECT 1 FROM tag WHERE tag.mod_id = mod.id)
+    OR EXISTS(SELECT 1 FROM playlist_mod_cross_ref WHERE playlist_mod_cross_ref.mod_id = mod.id)
+    OR EXISTS(SELECT 1 FROM inicache WHERE inicache.mod_id = mod.id)
+    OR EXISTS(
+        SELECT 1 FROM texture WHERE texture.mod_id = mod.id AND (
+            texture.gb_id IS NOT NULL OR texture.mod_link IS NOT NULL OR texture.preview_images != ''
+        )
+    )
+);
+
+-- name: SelectTextureIdsWithUserData :many
+SELECT texture.id FROM texture
+JOIN mod ON mod.id = texture.mod_id
+WHERE mod.game = :game AND (
+    texture.gb_id IS NOT NULL
+    OR texture.mod_link IS NOT NULL
+    OR texture.preview_images != ''
+);
+
+-- name: UpdatePendingDeletesGameByModId :exec
+UPDATE sync_pending_delete SET
+    game = :game
+WHERE (kind = 0 AND row_id = :modId)
+    OR (kind = 1 AND row_id IN (SELECT id FROM texture WHERE texture.mod_id = :modId));
@@ -18,4 +18,14 @@ INSERT OR IGNORE INTO tag(tag_name, mod_id) VALUES(:tagName, :modId);
 DELETE FROM tag WHERE tag_name = :name AND mod_id = :modId;
 
 -- name: SelectTagsByModId :many
-SELECT * FROM tag WHERE mod_id = :modId;
\ No newline at end of file
+SELECT * FROM tag WHERE mod_id = :modId;
+
+-- name: MergeModTags :exec
+INSERT OR IGNORE INTO tag(tag_name, mod_id)
+SELECT tag_name, :keepId FROM tag WHERE tag.mod_id = :removeId;
+
+-- name: DeleteTagsByModId :exec
+DELETE FROM tag WHERE mod_id = :modId;
+
+-- name: SelectTagsByModIds :many
+SELECT * FROM tag WHERE mod_id IN (sqlc.slice('ids')) ORDER BY mod_id, tag_name;
@@ -38,6 +38,11 @@ RETURNING id;
 -- name: SelectTexturesByModId :many
 SELECT * FROM texture WHERE mod_id = :modId;
 
+-- name: SelectTexturesByGame :many
+SELECT texture.* FROM texture
+JOIN mod ON mod.id = texture.mod_id
+WHERE mod.game = :game;
+
 -- name: SelectEnabledTexturesByModId :many
 SELECT * FROM texture WHERE (mod_id = :modId AND selected);
 
@@ -62,3 +67,50 @@ DELETE FROM texture WHERE texture.id = :id;
 
 -- name: DeleteUnusedTextures :exec
 DELETE FROM texture WHERE fname NOT IN sqlc.slice('files') AND mod_id = :modId;
+
+
+-- name: UpdateTextureMetadata :exec
+UPDATE texture SET
+    selected = :selected,
+    preview_images = :previewImages,
+    gb_id = :gbId,
+    mod_link = :modLink,
+    gb_file_name = :gbFilename,
+    gb_download_link = :gbDownloadLink
+WHERE texture.id = :id;
+
+-- name: UpdateTextureModId :exec
+UPDATE texture SET
+    mod_id = :modId,
+    fname = :fname
+WHERE texture.id = :id;
+
+-- name: RestoreTexture :one
+INSERT INTO texture (
+    id,
+    mod_id,
+    fname,
+    selected,
+    preview_images,
+    gb_id,
+    mod_link,
+    gb_file_name,
+    gb_download_link
+) VALUES(
+    sqlc.narg('id'),
+    :modId,
+    :fname,
+    :selected,
+    :previewImages,
+    :gbId,
+    :modLink,
+    :gbFilename,
+    :gbDownloadLink
+)
+RETURNING id;
+
+-- name: DeleteTexturesByModId :exec
+DELETE FROM texture WHERE mod_id = :modId;
+
+-- name: SelectTexturesByModIds :many
+SELECT * FROM texture WHERE mod_id IN (sqlc.slice('ids')) ORDER BY mod_id, fname;
@@ -0,0 +1,50 @@
+-- mod_usage(
+--     mod_id INTEGER PRIMARY KEY NOT NULL,
+--     generate_count INTEGER NOT NULL DEFAULT 0,
+--     enabled_seconds INTEGER NOT NULL DEFAULT 0,
+--     active_since INTEGER NOT NULL DEFAULT 0,
+--     last_exported_at INTEGER NOT NULL DEFAULT 0,
+--     FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+-- );
+
+-- name: CloseModUsageByGame :exec
+UPDATE mod_usage SET
+    enabled_seconds = enabled_seconds + MAX(:now - active_since, 0),
+    active_since = 0
+WHERE active_since != 0 AND mod_id IN (SELECT mod.id FROM mod WHERE mod.game = :game);
+
+-- name: InsertModGenerated :exec
+INSERT INTO mod_usage(mod_id, generate_count, active_since)
+VALUES(:modId, 1, :activeSince)
+ON CONFLICT(mod_id) DO UPDATE SET
+    generate_count = generate_count + 1,
+    active_since = excluded.active_since;
+
+-- name: UpdateModsLastExportedAt :exec
+UPDATE mod_usage SET
+    last_exported_at = :lastExportedAt
+WHERE mod_id IN (sqlc.slice('ids'));
+
+-- name: SelectModUsageById :many
+SELECT * FROM mod_usage WHERE mod_id = :modId;
+
+-- name: RestoreModUsage :exec
+INSERT OR REPLACE INTO mod_usage(mod_id, generate_count, enabled_seconds, active_since, last_exported_at)
+VALUES(:modId, :generateCount, :enabledSeconds, 0, :lastExportedAt);
+
+-- name: DeleteModUsageByModId :exec
+DELETE FROM mod_usage WHERE mod_id = :modId;
+
+-- name: SelectModUsage :many
+SELECT
+    sqlc.embed(mod),
+    COALESCE(u.generate_count, 0) AS generate_count,
+    COALESCE(u.enabled_seconds, 0) AS enabled_seconds,
+    COALESCE(u.active_since, 0) AS active_since,
+    COALESCE(u.last_exported_at, 0) AS last_exported_at
+FROM mod
+LEFT JOIN mod_usage u ON u.mod_id = mod.id
+WHERE mod.game = :game
+AND (CAST(:charId AS INTEGER) = 0 OR mod.char_id = :charId)
+AND (CAST(:tagName AS TEXT) = '' OR mod.id IN (SELECT tag.mod_id FROM tag WHERE tag.tag_name = :tagName))
+ORDER BY mod.id;
@@ -10,6 +10,20 @@ CREATE TABLE IF NOT EXISTS mod(
     mod_link TEXT,
     gb_file_name TEXT,
     gb_download_link TEXT,
+    flags INTEGER NOT NULL DEFAULT 0,
+    fingerprint TEXT NOT NULL DEFAULT '',
+    author TEXT NOT NULL DEFAULT '',
+    version TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    content_ratings TEXT NOT NULL DEFAULT '',
+    added_at INTEGER NOT NULL DEFAULT 0,
+    last_enabled_at INTEGER NOT NULL DEFAULT 0,
+    size INTEGER NOT NULL DEFAULT 0,
+    gb_updated_at INTEGER NOT NULL DEFAULT 0,
+    favorite BOOLEAN NOT NULL DEFAULT FALSE,
+    rating INTEGER NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    gb_name TEXT NOT NULL DEFAULT '',
     UNIQUE(fname, char_id, char_name),
     FOREIGN KEY (char_id) REFERENCES character(id) ON DELETE CASCADE
 );
@@ -56,11 +70,74 @@ CREATE TABLE IF NOT EXISTS character(
     avatar_url TEXT NOT NULL DEFAULT '',
     element TEXT NOT NULL,
     flags INT NOT NULL DEFAULT 0,
+    category INTEGER NOT NULL DEFAULT 0,
+    exclusive INTEGER NOT NULL DEFAULT 0,
     PRIMARY KEY(id, game)
 );
 
 CREATE TABLE IF NOT EXISTS inicache (
-    mod_id INTEGER PRIMARY KEY NOT NULL,
+    mod_id INTEGER NOT NULL,
     fname TEXT NOT NULL,
+    fingerprint TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (mod_id, fname),
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS archive_password(
+    scope INTEGER NOT NULL,
+    scope_id INTEGER NOT NULL,
+    password TEXT NOT NULL,
+    PRIMARY KEY(scope, scope_id, password)
+);
+
+CREATE TABLE IF NOT EXISTS quarantine(
+    mod_id INTEGER NOT NULL,
+    path TEXT NOT NULL,
+    allowed BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY(mod_id, path),
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS sync_pending_delete(
+    kind INTEGER NOT NULL,
+    row_id INTEGER NOT NULL,
+    game INTEGER NOT NULL,
+    since INTEGER NOT NULL,
+    PRIMARY KEY(kind, row_id)
+);
+
+CREATE TABLE IF NOT EXISTS mod_dependency(
+    mod_id INTEGER NOT NULL,
+    kind INTEGER NOT NULL,
+    dep_id INTEGER NOT NULL,
+    source INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(mod_id, kind, dep_id),
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS mod_usage(
+    mod_id INTEGER PRIMARY KEY NOT NULL,
+    generate_count INTEGER NOT NULL DEFAULT 0,
+    enabled_seconds INTEGER NOT NULL DEFAULT 0,
+    active_since INTEGER NOT NULL DEFAULT 0,
+    last_exported_at INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS character_alias(
+    game INTEGER NOT NULL,
+    char_id INTEGER NOT NULL,
+    alias TEXT NOT NULL COLLATE NOCASE,
+    custom BOOLEAN NOT NULL DEFAULT FALSE,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY(game, alias),
+    FOREIGN KEY (char_id, game) REFERENCES character(id, game) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS mod_namespace(
+    mod_id INTEGER PRIMARY KEY NOT NULL,
+    fingerprint TEXT NOT NULL DEFAULT '',
+    declared TEXT NOT NULL DEFAULT '',
+    referenced TEXT NOT NULL DEFAULT '',
     FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
 );
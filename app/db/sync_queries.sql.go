@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: sync_queries.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deletePendingDelete = `-- name: DeletePendingDelete :exec
+DELETE FROM sync_pending_delete WHERE kind = ?1 AND row_id = ?2
+`
+
+type DeletePendingDeleteParams struct {
+	Kind  int64
+	RowId int64
+}
+
+func (q *Queries) DeletePendingDelete(ctx context.Context, arg DeletePendingDeleteParams) error {
+	_, err := q.db.ExecContext(ctx, deletePendingDelete, arg.Kind, arg.RowId)
+	return err
+}
+
+const insertPendingDelete = `-- name: InsertPendingDelete :exec
+
+INSERT OR IGNORE INTO sync_pending_delete(kind, row_id, game, since) VALUES(?1, ?2, ?3, ?4)
+`
+
+type InsertPendingDeleteParams struct {
+	Kind  int64
+	RowId int64
+	Game  int64
+	Since int64
+}
+
+// sync_pending_delete(
+//
+//	kind INTEGER NOT NULL,
+//	row_id INTEGER NOT NULL,
+//	game INTEGER NOT NULL,
+//	since INTEGER NOT NULL,
+//	PRIMARY KEY(kind, row_id)
+//
+// );
+func (q *Queries) InsertPendingDelete(ctx context.Context, arg InsertPendingDeleteParams) error {
+	_, err := q.db.ExecContext(ctx, insertPendingDelete,
+		arg.Kind,
+		arg.RowId,
+		arg.Game,
+		arg.Since,
+	)
+	return err
+}
+
+const selectModIdsWithUserData = `-- name: SelectModIdsWithUserData :many
+SELECT mod.id FROM mod WHERE mod.game = ?1 AND (
+    mod.gb_id IS NOT NULL
+    OR mod.mod_link IS NOT NULL
+    OR mod.preview_images != ''
+    OR EXISTS(SELECT 1 FROM tag WHERE tag.mod_id = mod.id)
+    OR EXISTS(SELECT 1 FROM playlist_mod_cross_ref WHERE playlist_mod_cross_ref.mod_id = mod.id)
+    OR EXISTS(SELECT 1 FROM inicache WHERE inicache.mod_id = mod.id)
+    OR EXISTS(
+        SELECT 1 FROM texture WHERE texture.mod_id = mod.id AND (
+            texture.gb_id IS NOT NULL OR texture.mod_link IS NOT NULL OR texture.preview_images != ''
+        )
+    )
+)
+`
+
+func (q *Queries) SelectModIdsWithUserData(ctx context.Context, game int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, selectModIdsWithUserData, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectPendingDeletesByGame = `-- name: SelectPendingDeletesByGame :many
+SELECT kind, row_id, game, since FROM sync_pending_delete WHERE game = ?1
+`
+
+func (q *Queries) SelectPendingDeletesByGame(ctx context.Context, game int64) ([]SyncPendingDelete, error) {
+	rows, err := q.db.QueryContext(ctx, selectPendingDeletesByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SyncPendingDelete
+	for rows.Next() {
+		var i SyncPendingDelete
+		if err := rows.Scan(
+			&i.Kind,
+			&i.RowID,
+			&i.Game,
+			&i.Since,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectTextureIdsWithUserData = `-- name: SelectTextureIdsWithUserData :many
+SELECT texture.id FROM texture
+JOIN mod ON mod.id = texture.mod_id
+WHERE mod.game = ?1 AND (
+    texture.gb_id IS NOT NULL
+    OR texture.mod_link IS NOT NULL
+    OR texture.preview_images != ''
+)
+`
+
+func (q *Queries) SelectTextureIdsWithUserData(ctx context.Context, game int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, selectTextureIdsWithUserData, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePendingDeletesGameByModId = `-- name: UpdatePendingDeletesGameByModId :exec
+UPDATE sync_pending_delete SET
+    game = ?1
+WHERE (kind = 0 AND row_id = ?2)
+    OR (kind = 1 AND row_id IN (SELECT id FROM texture WHERE texture.mod_id = ?2))
+`
+
+type UpdatePendingDeletesGameByModIdParams struct {
+	Game  int64
+	ModId int64
+}
+
+func (q *Queries) UpdatePendingDeletesGameByModId(ctx context.Context, arg UpdatePendingDeletesGameByModIdParams) error {
+	_, err := q.db.ExecContext(ctx, updatePendingDeletesGameByModId, arg.Game, arg.ModId)
+	return err
+}
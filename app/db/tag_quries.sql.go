@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"strings"
 )
 
 const deleteTag = `-- name: DeleteTag :exec
@@ -23,6 +24,15 @@ func (q *Queries) DeleteTag(ctx context.Context, arg DeleteTagParams) error {
 	return err
 }
 
+const deleteTagsByModId = `-- name: DeleteTagsByModId :exec
+DELETE FROM tag WHERE mod_id = ?1
+`
+
+func (q *Queries) DeleteTagsByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTagsByModId, modid)
+	return err
+}
+
 const insertTag = `-- name: InsertTag :exec
 INSERT OR IGNORE INTO tag(tag_name, mod_id) VALUES(?1, ?2)
 `
@@ -37,6 +47,21 @@ func (q *Queries) InsertTag(ctx context.Context, arg InsertTagParams) error {
 	return err
 }
 
+const mergeModTags = `-- name: MergeModTags :exec
+INSERT OR IGNORE INTO tag(tag_name, mod_id)
+SELECT tag_name, ?1 FROM tag WHERE tag.mod_id = ?2
+`
+
+type MergeModTagsParams struct {
+	KeepId   int64
+	RemoveId int64
+}
+
+func (q *Queries) MergeModTags(ctx context.Context, arg MergeModTagsParams) error {
+	_, err := q.db.ExecContext(ctx, mergeModTags, arg.KeepId, arg.RemoveId)
+	return err
+}
+
 const selectTagsByModId = `-- name: SelectTagsByModId :many
 SELECT mod_id, tag_name FROM tag WHERE mod_id = ?1
 `
@@ -64,6 +89,43 @@ func (q *Queries) SelectTagsByModId(ctx context.Context, modid int64) ([]Tag, er
 	return items, nil
 }
 
+const selectTagsByModIds = `-- name: SelectTagsByModIds :many
+SELECT mod_id, tag_name FROM tag WHERE mod_id IN (/*SLICE:ids*/?) ORDER BY mod_id, tag_name
+`
+
+func (q *Queries) SelectTagsByModIds(ctx context.Context, ids []int64) ([]Tag, error) {
+	query := selectTagsByModIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(&i.ModID, &i.TagName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTagName = `-- name: UpdateTagName :exec
 
 UPDATE tag SET 
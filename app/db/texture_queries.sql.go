@@ -20,6 +20,15 @@ func (q *Queries) DeleteTextureById(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteTexturesByModId = `-- name: DeleteTexturesByModId :exec
+DELETE FROM texture WHERE mod_id = ?1
+`
+
+func (q *Queries) DeleteTexturesByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTexturesByModId, modid)
+	return err
+}
+
 const deleteUnusedTextures = `-- name: DeleteUnusedTextures :exec
 DELETE FROM texture WHERE fname NOT IN /*SLICE:files*/? AND mod_id = ?2
 `
@@ -112,6 +121,60 @@ func (q *Queries) InsertTexture(ctx context.Context, arg InsertTextureParams) (i
 	return id, err
 }
 
+const restoreTexture = `-- name: RestoreTexture :one
+INSERT INTO texture (
+    id,
+    mod_id,
+    fname,
+    selected,
+    preview_images,
+    gb_id,
+    mod_link,
+    gb_file_name,
+    gb_download_link
+) VALUES(
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5,
+    ?6,
+    ?7,
+    ?8,
+    ?9
+)
+RETURNING id
+`
+
+type RestoreTextureParams struct {
+	ID             sql.NullInt64
+	ModId          int64
+	Fname          string
+	Selected       bool
+	PreviewImages  string
+	GbId           sql.NullInt64
+	ModLink        sql.NullString
+	GbFilename     sql.NullString
+	GbDownloadLink sql.NullString
+}
+
+func (q *Queries) RestoreTexture(ctx context.Context, arg RestoreTextureParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, restoreTexture,
+		arg.ID,
+		arg.ModId,
+		arg.Fname,
+		arg.Selected,
+		arg.PreviewImages,
+		arg.GbId,
+		arg.ModLink,
+		arg.GbFilename,
+		arg.GbDownloadLink,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const selectEnabledTexturesByModId = `-- name: SelectEnabledTexturesByModId :many
 SELECT id, mod_id, fname, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM texture WHERE (mod_id = ?1 AND selected)
 `
@@ -170,6 +233,45 @@ func (q *Queries) SelectTextureById(ctx context.Context, id int64) (Texture, err
 	return i, err
 }
 
+const selectTexturesByGame = `-- name: SelectTexturesByGame :many
+SELECT texture.id, texture.mod_id, texture.fname, texture.selected, texture.preview_images, texture.gb_id, texture.mod_link, texture.gb_file_name, texture.gb_download_link FROM texture
+JOIN mod ON mod.id = texture.mod_id
+WHERE mod.game = ?1
+`
+
+func (q *Queries) SelectTexturesByGame(ctx context.Context, game int64) ([]Texture, error) {
+	rows, err := q.db.QueryContext(ctx, selectTexturesByGame, game)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Texture
+	for rows.Next() {
+		var i Texture
+		if err := rows.Scan(
+			&i.ID,
+			&i.ModID,
+			&i.Fname,
+			&i.Selected,
+			&i.PreviewImages,
+			&i.GbID,
+			&i.ModLink,
+			&i.GbFileName,
+			&i.GbDownloadLink,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const selectTexturesByModId = `-- name: SelectTexturesByModId :many
 SELECT id, mod_id, fname, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM texture WHERE mod_id = ?1
 `
@@ -207,6 +309,53 @@ func (q *Queries) SelectTexturesByModId(ctx context.Context, modid int64) ([]Tex
 	return items, nil
 }
 
+const selectTexturesByModIds = `-- name: SelectTexturesByModIds :many
+SELECT id, mod_id, fname, selected, preview_images, gb_id, mod_link, gb_file_name, gb_download_link FROM texture WHERE mod_id IN (/*SLICE:ids*/?) ORDER BY mod_id, fname
+`
+
+func (q *Queries) SelectTexturesByModIds(ctx context.Context, ids []int64) ([]Texture, error) {
+	query := selectTexturesByModIds
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Texture
+	for rows.Next() {
+		var i Texture
+		if err := rows.Scan(
+			&i.ID,
+			&i.ModID,
+			&i.Fname,
+			&i.Selected,
+			&i.PreviewImages,
+			&i.GbID,
+			&i.ModLink,
+			&i.GbFileName,
+			&i.GbDownloadLink,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTextureEnabledById = `-- name: UpdateTextureEnabledById :exec
 UPDATE texture SET
     selected = ?1
@@ -223,6 +372,58 @@ func (q *Queries) UpdateTextureEnabledById(ctx context.Context, arg UpdateTextur
 	return err
 }
 
+const updateTextureMetadata = `-- name: UpdateTextureMetadata :exec
+UPDATE texture SET
+    selected = ?1,
+    preview_images = ?2,
+    gb_id = ?3,
+    mod_link = ?4,
+    gb_file_name = ?5,
+    gb_download_link = ?6
+WHERE texture.id = ?7
+`
+
+type UpdateTextureMetadataParams struct {
+	Selected       bool
+	PreviewImages  string
+	GbId           sql.NullInt64
+	ModLink        sql.NullString
+	GbFilename     sql.NullString
+	GbDownloadLink sql.NullString
+	ID             int64
+}
+
+func (q *Queries) UpdateTextureMetadata(ctx context.Context, arg UpdateTextureMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateTextureMetadata,
+		arg.Selected,
+		arg.PreviewImages,
+		arg.GbId,
+		arg.ModLink,
+		arg.GbFilename,
+		arg.GbDownloadLink,
+		arg.ID,
+	)
+	return err
+}
+
+const updateTextureModId = `-- name: UpdateTextureModId :exec
+UPDATE texture SET
+    mod_id = ?1,
+    fname = ?2
+WHERE texture.id = ?3
+`
+
+type UpdateTextureModIdParams struct {
+	ModId int64
+	Fname string
+	ID    int64
+}
+
+func (q *Queries) UpdateTextureModId(ctx context.Context, arg UpdateTextureModIdParams) error {
+	_, err := q.db.ExecContext(ctx, updateTextureModId, arg.ModId, arg.Fname, arg.ID)
+	return err
+}
+
 const updateTextureNameById = `-- name: UpdateTextureNameById :exec
 UPDATE texture SET
     fname = ?1
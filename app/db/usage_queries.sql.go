@@ -0,0 +1,230 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: usage_queries.sql
+
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+const closeModUsageByGame = `-- name: CloseModUsageByGame :exec
+
+UPDATE mod_usage SET
+    enabled_seconds = enabled_seconds + MAX(?1 - active_since, 0),
+    active_since = 0
+WHERE active_since != 0 AND mod_id IN (SELECT mod.id FROM mod WHERE mod.game = ?2)
+`
+
+type CloseModUsageByGameParams struct {
+	Now  int64
+	Game int64
+}
+
+// mod_usage(
+//
+//	mod_id INTEGER PRIMARY KEY NOT NULL,
+//	generate_count INTEGER NOT NULL DEFAULT 0,
+//	enabled_seconds INTEGER NOT NULL DEFAULT 0,
+//	active_since INTEGER NOT NULL DEFAULT 0,
+//	last_exported_at INTEGER NOT NULL DEFAULT 0,
+//	FOREIGN KEY (mod_id) REFERENCES mod(id) ON DELETE CASCADE
+//
+// );
+func (q *Queries) CloseModUsageByGame(ctx context.Context, arg CloseModUsageByGameParams) error {
+	_, err := q.db.ExecContext(ctx, closeModUsageByGame, arg.Now, arg.Game)
+	return err
+}
+
+const deleteModUsageByModId = `-- name: DeleteModUsageByModId :exec
+DELETE FROM mod_usage WHERE mod_id = ?1
+`
+
+func (q *Queries) DeleteModUsageByModId(ctx context.Context, modid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteModUsageByModId, modid)
+	return err
+}
+
+const insertModGenerated = `-- name: InsertModGenerated :exec
+INSERT INTO mod_usage(mod_id, generate_count, active_since)
+VALUES(?1, 1, ?2)
+ON CONFLICT(mod_id) DO UPDATE SET
+    generate_count = generate_count + 1,
+    active_since = excluded.active_since
+`
+
+type InsertModGeneratedParams struct {
+	ModId       int64
+	ActiveSince int64
+}
+
+func (q *Queries) InsertModGenerated(ctx context.Context, arg InsertModGeneratedParams) error {
+	_, err := q.db.ExecContext(ctx, insertModGenerated, arg.ModId, arg.ActiveSince)
+	return err
+}
+
+const restoreModUsage = `-- name: RestoreModUsage :exec
+INSERT OR REPLACE INTO mod_usage(mod_id, generate_count, enabled_seconds, active_since, last_exported_at)
+VALUES(?1, ?2, ?3, 0, ?4)
+`
+
+type RestoreModUsageParams struct {
+	ModId          int64
+	GenerateCount  int64
+	EnabledSeconds int64
+	LastExportedAt int64
+}
+
+func (q *Queries) RestoreModUsage(ctx context.Context, arg RestoreModUsageParams) error {
+	_, err := q.db.ExecContext(ctx, restoreModUsage,
+		arg.ModId,
+		arg.GenerateCount,
+		arg.EnabledSeconds,
+		arg.LastExportedAt,
+	)
+	return err
+}
+
+const selectModUsage = `-- name: SelectModUsage :many
+SELECT
+    mod.id, mod.fname, mod.game, mod.char_name, mod.char_id, mod.selected, mod.preview_images, mod.gb_id, mod.mod_link, mod.gb_file_name, mod.gb_download_link, mod.flags, mod.fingerprint, mod.author, mod.version, mod.description, mod.content_ratings, mod.added_at, mod.last_enabled_at, mod.size, mod.gb_updated_at, mod.favorite, mod.rating, mod.notes, mod.gb_name,
+    COALESCE(u.generate_count, 0) AS generate_count,
+    COALESCE(u.enabled_seconds, 0) AS enabled_seconds,
+    COALESCE(u.active_since, 0) AS active_since,
+    COALESCE(u.last_exported_at, 0) AS last_exported_at
+FROM mod
+LEFT JOIN mod_usage u ON u.mod_id = mod.id
+WHERE mod.game = ?1
+AND (CAST(?2 AS INTEGER) = 0 OR mod.char_id = ?2)
+AND (CAST(?3 AS TEXT) = '' OR mod.id IN (SELECT tag.mod_id FROM tag WHERE tag.tag_name = ?3))
+ORDER BY mod.id
+`
+
+type SelectModUsageParams struct {
+	Game    int64
+	CharId  int64
+	TagName string
+}
+
+type SelectModUsageRow struct {
+	Mod            Mod
+	GenerateCount  int64
+	EnabledSeconds int64
+	ActiveSince    int64
+	LastExportedAt int64
+}
+
+func (q *Queries) SelectModUsage(ctx context.Context, arg SelectModUsageParams) ([]SelectModUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectModUsage, arg.Game, arg.CharId, arg.TagName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectModUsageRow
+	for rows.Next() {
+		var i SelectModUsageRow
+		if err := rows.Scan(
+			&i.Mod.ID,
+			&i.Mod.Fname,
+			&i.Mod.Game,
+			&i.Mod.CharName,
+			&i.Mod.CharID,
+			&i.Mod.Selected,
+			&i.Mod.PreviewImages,
+			&i.Mod.GbID,
+			&i.Mod.ModLink,
+			&i.Mod.GbFileName,
+			&i.Mod.GbDownloadLink,
+			&i.Mod.Flags,
+			&i.Mod.Fingerprint,
+			&i.Mod.Author,
+			&i.Mod.Version,
+			&i.Mod.Description,
+			&i.Mod.ContentRatings,
+			&i.Mod.AddedAt,
+			&i.Mod.LastEnabledAt,
+			&i.Mod.Size,
+			&i.Mod.GbUpdatedAt,
+			&i.Mod.Favorite,
+			&i.Mod.Rating,
+			&i.Mod.Notes,
+			&i.Mod.GbName,
+			&i.GenerateCount,
+			&i.EnabledSeconds,
+			&i.ActiveSince,
+			&i.LastExportedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectModUsageById = `-- name: SelectModUsageById :many
+SELECT mod_id, generate_count, enabled_seconds, active_since, last_exported_at FROM mod_usage WHERE mod_id = ?1
+`
+
+func (q *Queries) SelectModUsageById(ctx context.Context, modid int64) ([]ModUsage, error) {
+	rows, err := q.db.QueryContext(ctx, selectModUsageById, modid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModUsage
+	for rows.Next() {
+		var i ModUsage
+		if err := rows.Scan(
+			&i.ModID,
+			&i.GenerateCount,
+			&i.EnabledSeconds,
+			&i.ActiveSince,
+			&i.LastExportedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateModsLastExportedAt = `-- name: UpdateModsLastExportedAt :exec
+UPDATE mod_usage SET
+    last_exported_at = ?1
+WHERE mod_id IN (/*SLICE:ids*/?)
+`
+
+type UpdateModsLastExportedAtParams struct {
+	LastExportedAt int64
+	Ids            []int64
+}
+
+func (q *Queries) UpdateModsLastExportedAt(ctx context.Context, arg UpdateModsLastExportedAtParams) error {
+	query := updateModsLastExportedAt
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.LastExportedAt)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
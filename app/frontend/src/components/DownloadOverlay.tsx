@@ -1,11 +1,13 @@
 import { formatBytes } from "@/lib/tsutils";
-import { useDownloadStore, Download, DownloadProgress, dlStates, dlSortPriority } from "@/state/downloadStore";
-import { ChevronUpIcon, CheckCircle2Icon, RefreshCwIcon, XIcon } from "lucide-react";
+import { useDownloadStore, Download, DownloadProgress, dlStates, dlSortPriority, doneStates } from "@/state/downloadStore";
+import { ChevronUpIcon, CheckCircle2Icon, KeyRoundIcon, RefreshCwIcon, XIcon } from "lucide-react";
 import { useShallow } from "zustand/shallow";
 import { Button } from "./ui/button";
 import { Card } from "./ui/card";
 import { Progress } from "./ui/progress";
 import { cn } from "@/lib/utils";
+import { PasswordDialog } from "./PasswordDialog";
+import { useState } from "react";
 
 export function DownloadOverlay() {
   const onClear = useDownloadStore((state) => state.remove);
@@ -33,7 +35,7 @@ export function DownloadOverlay() {
             />
             <div className="flex flex-row space-x-2 mx-2">
               <Button onClick={() => {
-                for (const done of downloads.filter((d) => d.state === "finished" || d.state === "error")) {
+                for (const done of downloads.filter((d) => doneStates.includes(d.state))) {
                   onClear(done.link)
                 }
               }}>
@@ -77,9 +79,17 @@ export function DownloadOverlay() {
   )
 }
 
+const errorMessages = {
+  error: (filename: string) => `An error occured while trying to download ${filename}`,
+  password: (filename: string) => `${filename} is encrypted and needs a password`,
+  unsupported: (filename: string) => `${filename} uses an encryption that can not be extracted`,
+};
+
 function DownloadItem({ download }: { download: Download }) {
   const onClear = useDownloadStore((state) => state.remove);
   const retry = useDownloadStore((state) => state.retry);
+  const submitPassword = useDownloadStore((state) => state.submitPassword);
+  const [passwordOpen, setPasswordOpen] = useState(false);
 
   if (download.state === "finished") {
     return (
@@ -102,21 +112,31 @@ function DownloadItem({ download }: { download: Download }) {
         </Button>
       </div>
     );
-  } else if (download.state === "error") {
+  } else if (download.state === "error" || download.state === "password" || download.state === "unsupported") {
     return (
       <div className="flex flex-row items-center justify-between pb-2">
         <div className="flex flex-col space-y-1 px-4">
           <b>{download.filename}</b>
-          <div className="text-sm">{`An error occured while trying to download ${download.filename}`}</div>
+          <div className="text-sm">{errorMessages[download.state](download.filename)}</div>
         </div>
         <div className="flex flex-row items-center">
-          <Button
-            size="icon"
-            className="me-12"
-            onClick={() => retry(download.link)}
-          >
-            <RefreshCwIcon />
-          </Button>
+          {download.state === "password" ? (
+            <Button
+              size="icon"
+              className="me-12"
+              onClick={() => setPasswordOpen(true)}
+            >
+              <KeyRoundIcon />
+            </Button>
+          ) : (
+            <Button
+              size="icon"
+              className="me-12"
+              onClick={() => retry(download.link)}
+            >
+              <RefreshCwIcon />
+            </Button>
+          )}
           <Button
             size="icon"
             className="me-12"
@@ -125,6 +145,12 @@ function DownloadItem({ download }: { download: Download }) {
             <XIcon />
           </Button>
         </div>
+        <PasswordDialog
+          filename={download.filename}
+          open={passwordOpen}
+          onOpenChange={setPasswordOpen}
+          onSuccess={(password, remember) => submitPassword(download.link, password, remember)}
+        />
       </div>
     );
   }
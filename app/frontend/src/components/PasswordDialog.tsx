@@ -0,0 +1,57 @@
+import { useState } from "react";
+import { Button } from "./ui/button";
+import { Checkbox } from "./ui/checkbox";
+import { Dialog, DialogClose, DialogContent, DialogDescription, DialogFooter, DialogHeader, DialogTitle } from "./ui/dialog";
+import { Input } from "./ui/input";
+
+export function PasswordDialog(props: {
+    filename: string;
+    onSuccess: (password: string, remember: boolean) => void;
+    open: boolean;
+    onOpenChange: (open: boolean) => void;
+}) {
+    const [password, setPassword] = useState("");
+    const [remember, setRemember] = useState(false);
+
+    return (
+        <Dialog open={props.open} onOpenChange={props.onOpenChange}>
+            <DialogContent className="sm:max-w-md">
+                <DialogHeader>
+                    <DialogTitle>Archive password</DialogTitle>
+                    <DialogDescription>{`${props.filename} is encrypted, enter its password to extract it.`}</DialogDescription>
+                </DialogHeader>
+                <div className="grid flex-1 gap-2">
+                    <Input
+                        type="password"
+                        value={password}
+                        onChange={(event) => setPassword(event.target.value)}
+                    />
+                    <label className="flex items-center space-x-2 text-sm">
+                        <Checkbox
+                            checked={remember}
+                            onCheckedChange={(v) => setRemember(v as boolean)}
+                        />
+                        <span>Remember for other mods by this submitter</span>
+                    </label>
+                </div>
+                <DialogFooter className="sm:justify-start">
+                    <DialogClose asChild>
+                        <Button type="button" variant="secondary">
+                            Cancel
+                        </Button>
+                    </DialogClose>
+                    <DialogClose asChild>
+                        <Button
+                            disabled={password === ""}
+                            onPointerDown={() => props.onSuccess(password, remember)}
+                            type="button"
+                            variant="secondary"
+                        >
+                            Confirm
+                        </Button>
+                    </DialogClose>
+                </DialogFooter>
+            </DialogContent>
+        </Dialog>
+    );
+}
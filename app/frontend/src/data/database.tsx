@@ -14,12 +14,12 @@ import {
   RenameMod,
   RenameTexture,
   SelectCharactersByGame,
-  SelectCharacterWithModsTagsAndTextures,
   SelectClosestCharacter,
   SelectModById,
   SelectModsByCharacterName,
   SelectModsByGbId,
   SelectPlaylistWithModsAndTags,
+  SelectSortedCharacterMods,
   SelectTagsByModId,
   UpdateDisableAllModsByGame,
   UpdateModEnabledById,
@@ -31,6 +31,7 @@ import {
 } from "wailsjs/go/dbh/DbHelper";
 import { SplitTexture } from "wailsjs/go/main/App";
 import { EventsEmit, EventsOn, LogDebug } from "wailsjs/runtime/runtime";
+import { librarySortPref } from "./prefs";
 
 type DBKey = "characters" | "mods" | "tags" | "playlist" | "all";
 
@@ -170,11 +171,12 @@ const DB = {
       characterFilter: string,
       tagFilter: string,
     ) => {
-      return SelectCharacterWithModsTagsAndTextures(
+      return SelectSortedCharacterMods(
         game,
         modFilter,
         characterFilter,
         tagFilter,
+        await librarySortPref.Get(),
       );
     },
     selectCharactersByGame: async (game: number) => {
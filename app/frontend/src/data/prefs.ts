@@ -6,6 +6,7 @@ import * as ZZZDirPref from "../../wailsjs/go/core/ZZZDirPref";
 import * as WuwaDirPref from "../../wailsjs/go/core/WuwaDirPref";
 import * as IgnorePref from "../../wailsjs/go/core/IgnoreDirPref";
 import * as SortModPref from "../../wailsjs/go/core/SortModPref";
+import * as LibrarySortPref from "../../wailsjs/go/core/LibrarySortPref";
 import * as ModsAvailablePref from "../../wailsjs/go/core/ModsAvailablePref";
 import * as GenshinElementPref from "../../wailsjs/go/core/GenshinElementPref";
 import * as HonkaiElementPref from "../../wailsjs/go/core/HonkaiElementPref";
@@ -51,6 +52,7 @@ const wuwaDirPref = WuwaDirPref as GoPref<string>;
 const ignorePref = IgnorePref as GoPref<string[]>;
 const pluginsPref = EnabledPluginsPref as GoPref<string[]>;
 const sortModPref = SortModPref as GoPref<string>;
+const librarySortPref = LibrarySortPref as GoPref<string>;
 
 const modsAvailablePref = ModsAvailablePref as GoPref<boolean>;
 const genshinElementPref = GenshinElementPref as GoPref<string[]>;
@@ -119,6 +121,7 @@ export {
   wuwaDirPref,
   ignorePref,
   sortModPref,
+  librarySortPref,
   modsAvailablePref,
   genshinElementPref,
   honkaiElementPref,
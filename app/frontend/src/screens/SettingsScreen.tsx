@@ -9,6 +9,7 @@ import {
   serverPasswordPref,
   serverAuthTypePref,
   cleanModDirPref,
+  librarySortPref,
   usePrefQuery,
 } from "@/data/prefs";
 import {
@@ -81,6 +82,13 @@ const AuthType: { [keyof: number]: string } = {
   1: "Basic",
 } as const;
 
+const LibrarySort: Record<string, string> = {
+  "": "Name",
+  Rating: "Rating",
+  Favorite: "Favorite",
+  Added: "Newest",
+} as const;
+
 export default function SettingsScreen() {
   const [{ data: discover }, setDiscover] = usePrefQuery(discoverGamePref);
   const [{ data: ignore }, setIgnore] = usePrefQuery(ignorePref);
@@ -110,6 +118,7 @@ export default function SettingsScreen() {
     maxDownloadWorkersPref,
   );
   const [{ data: cleanModDir }, setCleanModDir] = usePrefQuery(cleanModDirPref);
+  const [{ data: librarySort }, setLibrarySort] = usePrefQuery(librarySortPref);
 
   const [dialog, setDialog] = useState<SettingsDialog | undefined>(undefined);
   const [sliderValue, setSliderValue] = useState(maxDownloadWorkers ?? 1);
@@ -277,6 +286,16 @@ export default function SettingsScreen() {
           checked={cleanModDir ?? false}
           onCheckedChange={() => setCleanModDir((prev) => !prev)}
         />
+        <SettingsDropDownItem
+          items={Object.keys(LibrarySort)}
+          selectedLabel={
+            <text>{librarySort !== undefined ? LibrarySort[librarySort] : undefined}</text>
+          }
+          title="Library sort"
+          description="order of the mods of each character, also used by the http server"
+          onChange={(item) => setLibrarySort(() => item)}
+          itemContent={(item) => <text>{LibrarySort[item]}</text>}
+        />
       </div>
       <h2 className="mt-4 text-lg font-semibold tracking-tight">Http server</h2>
       <div className="flex flex-row justify-between px-4">
@@ -7,10 +7,12 @@ export type DownloadProgress = {
   progress: number;
 };
 
-export type State = "download" | "queued" | "finished" | "unzip" | "error" | "compress"
+export type State = "download" | "queued" | "finished" | "unzip" | "error" | "compress" | "password" | "unsupported"
 
 export const dlSortPriority: Record<State, number> = {
   error: 0,
+  password: 0,
+  unsupported: 0,
   download: 1,
   unzip: 2,
   compress: 3,
@@ -37,10 +39,13 @@ export type DownloadState = {
   subscribe: () => CancelFn,
   updateQueue: () => Promise<void>,
   retry: (key: string) => Promise<void>,
+  submitPassword: (key: string, password: string, remember: boolean) => Promise<void>,
   toggleExpanded: () => void,
 }
 
-const runningCount = (q: Record<string, Download>) => Object.values<Download>(q).filter((item) => item.state !== "finished" && item.state !== "error").length
+export const doneStates: State[] = ["finished", "error", "password", "unsupported"];
+
+const runningCount = (q: Record<string, Download>) => Object.values<Download>(q).filter((item) => !doneStates.includes(item.state)).length
 
 export const dlStates: State[] = ["download", "queued", "unzip", "compress"];
 
@@ -75,6 +80,10 @@ export const useDownloadStore = create<DownloadState>((set, get) => ({
     await Downloader.Retry(key).catch((e) => LogDebug(e))
     get().updateQueue()
   },
+  submitPassword: async (key: string, password: string, remember: boolean) => {
+    await Downloader.SubmitPassword(key, password, remember).catch((e) => LogDebug(e))
+    get().updateQueue()
+  },
   toggleExpanded: () => set((state) => ({ expanded: !state.expanded })),
   remove: async (key: string) => {
     await Downloader.RemoveFromQueue(key)
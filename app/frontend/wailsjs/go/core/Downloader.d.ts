@@ -14,3 +14,5 @@ export function RemoveFromQueue(arg1:string):Promise<void>;
 export function Retry(arg1:string):Promise<void>;
 
 export function Stop():Promise<void>;
+
+export function SubmitPassword(arg1:string,arg2:string,arg3:boolean):Promise<void>;
@@ -25,3 +25,7 @@ export function Retry(arg1) {
 export function Stop() {
   return window['go']['core']['Downloader']['Stop']();
 }
+
+export function SubmitPassword(arg1, arg2, arg3) {
+  return window['go']['core']['Downloader']['SubmitPassword'](arg1, arg2, arg3);
+}
@@ -39,6 +39,8 @@ export function RenameTexture(arg1:number,arg2:string):Promise<void>;
 
 export function SelectCharacterWithModsTagsAndTextures(arg1:types.Game,arg2:string,arg3:string,arg4:string):Promise<Array<types.CharacterWithModsAndTags>>;
 
+export function SelectSortedCharacterMods(arg1:types.Game,arg2:string,arg3:string,arg4:string,arg5:string):Promise<Array<types.CharacterWithModsAndTags>>;
+
 export function SelectCharactersByGame(arg1:types.Game):Promise<Array<types.Character>>;
 
 export function SelectClosestCharacter(arg1:string,arg2:types.Game):Promise<types.Character>;
@@ -74,6 +74,10 @@ export function SelectCharacterWithModsTagsAndTextures(arg1, arg2, arg3, arg4) {
   return window['go']['dbh']['DbHelper']['SelectCharacterWithModsTagsAndTextures'](arg1, arg2, arg3, arg4);
 }
 
+export function SelectSortedCharacterMods(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['dbh']['DbHelper']['SelectSortedCharacterMods'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function SelectCharactersByGame(arg1) {
   return window['go']['dbh']['DbHelper']['SelectCharactersByGame'](arg1);
 }
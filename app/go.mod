@@ -4,9 +4,12 @@ go 1.24.1
 
 require (
 	github.com/alitto/pond/v2 v2.0.4
+	github.com/bodgit/sevenzip v1.6.0
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/klauspost/compress v1.18.0
 	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/mholt/archives v0.1.1
+	github.com/nwaples/rardecode/v2 v2.1.0
 	github.com/peterbourgon/diskv v2.0.1+incompatible
 	github.com/pressly/goose/v3 v3.24.2
 	github.com/wailsapp/wails/v2 v2.10.1
@@ -18,7 +21,6 @@ require (
 	github.com/STARRY-S/zip v0.2.1 // indirect
 	github.com/andybalholm/brotli v1.1.1 // indirect
 	github.com/bodgit/plumbing v1.3.0 // indirect
-	github.com/bodgit/sevenzip v1.6.0 // indirect
 	github.com/bodgit/windows v1.0.1 // indirect
 	github.com/bwmarrin/snowflake v0.3.0 // indirect
 	github.com/dsnet/compress v0.0.2-0.20230904184137-39efe44ab707 // indirect
@@ -30,7 +32,6 @@ require (
 	github.com/klauspost/pgzip v1.2.6 // indirect
 	github.com/mfridman/interpolate v0.0.2 // indirect
 	github.com/minio/minlz v1.0.0 // indirect
-	github.com/nwaples/rardecode/v2 v2.1.0 // indirect
 	github.com/pierrec/lz4/v4 v4.1.22 // indirect
 	github.com/robfig/cron/v3 v3.0.0 // indirect
 	github.com/rosedblabs/wal v1.3.8 // indirect
@@ -87,13 +87,16 @@ func main() {
 	}
 
 	go api.CleanCache()
-	queries, dbSql := dbh.InitDbAndRunMigrations(ctx, embedMigrations, ddl)
+	_, dbSql := dbh.InitDbAndRunMigrations(ctx, embedMigrations, ddl)
 
 	// CORE
-	dbHelper := dbh.NewDbHelper(queries, dbSql)
+	dbHelper := dbh.NewDbHelper(dbSql)
+	dbHelper.SetWarnFn(toastEmitter.Warn)
 
 	appPrefs := core.NewAppPrefs(pref.NewPrefs(store))
 	util.SetRootModDirFn(appPrefs.RootModDirPref.Get)
+	dbHelper.SetExclusiveGamesPref(appPrefs.ExclusiveModGamesPref.Preference)
+	dbHelper.SetRecycleRetentionPref(appPrefs.RecycleRetentionPref.Preference)
 
 	genshinApi := api.ApiList[types.Genshin]
 	starRailApi := api.ApiList[types.StarRail]
@@ -112,11 +115,22 @@ func main() {
 		dbHelper,
 		appPrefs.MaxDownloadWorkersPref.Preference,
 		appPrefs.SpaceSaverPref.Preference,
+		appPrefs.CompressionFormatPref.Preference,
+		appPrefs.CompressionLevelPref.Preference,
 		defaultEmitter,
 	)
 
-	sync := core.NewSyncHelper(dbHelper, defaultEmitter, toastEmitter)
+	sync := core.NewSyncHelper(
+		dbHelper,
+		defaultEmitter,
+		toastEmitter,
+		appPrefs.SyncDeleteGracePref.Preference,
+		appPrefs.CharacterDataPref.Preference,
+	)
+	watcher := core.NewLibraryWatcher(sync)
 	keymapper := core.NewKeymapper(dbHelper)
+	recovery := core.NewRecovery(dbHelper, sync, defaultEmitter, embedMigrations, ddl)
+	enricher := core.NewMetadataEnricher(dbHelper, gbApi)
 
 	generator := core.NewGenerator(
 		dbHelper,
@@ -126,7 +140,7 @@ func main() {
 		defaultEmitter,
 	)
 
-	serverManager := server.NewServerManager(appPrefs, dbHelper, generator, toastEmitter)
+	serverManager := server.NewServerManager(appPrefs, dbHelper, generator, sync, toastEmitter)
 	transfer := core.NewTransfer(sync, defaultEmitter, appPrefs.RootModDirPref.Preference)
 
 	app := NewApp(appPrefs, core.NewUpdator(gbApi, preferenceDirs), transfer, dbHelper)
@@ -156,7 +170,15 @@ func main() {
 			defaultEmitter.Bind(ctx)
 			serverManager.Listen(ctx)
 			app.startup(ctx)
-			go sync.RunAll(core.StartupRequest)
+			go func() {
+				if err := dbHelper.PurgeRecycleBin(); err != nil {
+					log.LogError(err.Error())
+				}
+				sync.RunAll(core.StartupRequest)
+				go enricher.EnrichAll()
+				// started after the initial sync so created character dirs are not reported
+				watcher.Watch(ctx)
+			}()
 		},
 		OnDomReady:       app.domReady,
 		OnBeforeClose:    app.beforeClose,
@@ -176,6 +198,8 @@ func main() {
 			downloader,
 			generator,
 			keymapper,
+			recovery,
+			enricher,
 			// SERVER
 			serverManager,
 			// PREFRENCES - LocalStorage replacement to acces from go
@@ -187,6 +211,7 @@ func main() {
 			appPrefs.ZZZDirPref,
 			appPrefs.IgnoreDirPref,
 			appPrefs.SortModPref,
+			appPrefs.LibrarySortPref,
 			appPrefs.ModsAvailablePref,
 			appPrefs.GenshinElementPref,
 			appPrefs.HonkaiElementPref,
@@ -200,12 +225,17 @@ func main() {
 			appPrefs.ServerPasswordPref,
 			appPrefs.ServerAuthTypePref,
 			appPrefs.SpaceSaverPref,
+			appPrefs.CompressionFormatPref,
+			appPrefs.CompressionLevelPref,
 			appPrefs.CleanModExportDirPref,
 			appPrefs.EnabledPluginsPref,
 			appPrefs.RootModDirPref,
 			appPrefs.UseViewTransitions,
 			appPrefs.Oneko,
 			appPrefs.ToastLevelPref,
+			appPrefs.SyncDeleteGracePref,
+			appPrefs.ExclusiveModGamesPref,
+			appPrefs.RecycleRetentionPref,
 		},
 		// Windows platform specific options
 		Windows: &windows.Options{
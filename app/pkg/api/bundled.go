@@ -0,0 +1,161 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"strings"
+	"sync"
+)
+
+// characters of each game keyed by game name, used when the network fails and
+// merged under the characters fetched from the network
+//
+//go:embed data/characters.json
+var charactersJson []byte
+
+type bundledCharacter struct {
+	// characters of games whose ids are not known use util.HashForName
+	Id      int      `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	Element string   `json:"element"`
+	// file formatted into the avatarUrl of the game or a full url
+	Avatar string `json:"avatar"`
+}
+
+type bundledGame struct {
+	AvatarUrl  string             `json:"avatarUrl"`
+	Characters []bundledCharacter `json:"characters"`
+}
+
+type bundledData struct {
+	// increased when characters are added so existing libraries pick them up
+	Version int                    `json:"version"`
+	Games   map[string]bundledGame `json:"games"`
+}
+
+var bundled = sync.OnceValue(func() bundledData {
+	data := bundledData{Games: map[string]bundledGame{}}
+	if err := json.Unmarshal(charactersJson, &data); err != nil {
+		log.LogError("failed to read bundled characters: " + err.Error())
+	}
+	return data
+})
+
+func (c bundledCharacter) id() int {
+	if c.Id != 0 {
+		return c.Id
+	}
+	return util.HashForName(c.Name)
+}
+
+func (c bundledCharacter) isNamed(name string) bool {
+	if strings.EqualFold(c.Name, name) {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g bundledGame) avatarUrl(c bundledCharacter) string {
+	if c.Avatar == "" || strings.HasPrefix(c.Avatar, "http") {
+		return c.Avatar
+	}
+	return fmt.Sprintf(g.AvatarUrl, c.Avatar)
+}
+
+func (g bundledGame) find(name string) (bundledCharacter, bool) {
+	for _, c := range g.Characters {
+		if c.isNamed(name) {
+			return c, true
+		}
+	}
+	return bundledCharacter{}, false
+}
+
+// CharacterDataVersion is the version of the bundled characters
+func CharacterDataVersion() int {
+	return bundled().Version
+}
+
+// BundledCharacters returns the characters of the game that are built into the app
+func BundledCharacters(game types.Game) []types.Character {
+	g := bundled().Games[game.Name()]
+
+	characters := make([]types.Character, 0, len(g.Characters))
+	for _, c := range g.Characters {
+		characters = append(characters, types.Character{
+			Id:        c.id(),
+			Game:      game,
+			Name:      c.Name,
+			AvatarUrl: g.avatarUrl(c),
+			Element:   c.Element,
+		})
+	}
+	return characters
+}
+
+// CharacterAliases returns the bundled aliases of the characters of the game keyed by character name
+func CharacterAliases(game types.Game) map[string][]string {
+	g := bundled().Games[game.Name()]
+
+	aliases := make(map[string][]string, len(g.Characters))
+	for _, c := range g.Characters {
+		if len(c.Aliases) > 0 {
+			aliases[c.Name] = c.Aliases
+		}
+	}
+	return aliases
+}
+
+// SameCharacter reports whether both names are of the same bundled character,
+// like Miyabi and Hoshimi Miyabi
+func SameCharacter(game types.Game, a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	c, ok := bundled().Games[game.Name()].find(a)
+	return ok && c.isNamed(b)
+}
+
+// mergeBundled adds the bundled characters that were not fetched, fetched characters
+// without an element or avatar get the bundled one
+func mergeBundled(game types.Game, fetched []types.Character) []types.Character {
+	g := bundled().Games[game.Name()]
+	if len(fetched) == 0 {
+		log.LogPrint(fmt.Sprintf("no characters fetched for %s, using the bundled characters", game.Name()))
+	}
+
+	merged := make([]types.Character, 0, max(len(fetched), len(g.Characters)))
+	used := map[string]bool{}
+	for _, c := range fetched {
+		for _, b := range g.Characters {
+			if b.id() != c.Id && !b.isNamed(c.Name) {
+				continue
+			}
+			used[b.Name] = true
+			if c.Element == "" {
+				c.Element = b.Element
+			}
+			if c.AvatarUrl == "" {
+				c.AvatarUrl = g.avatarUrl(b)
+			}
+		}
+		merged = append(merged, c)
+	}
+
+	for _, c := range BundledCharacters(game) {
+		if !used[c.Name] {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBundledCharacters(t *testing.T) {
+	if CharacterDataVersion() <= 0 {
+		t.Fatal("bundled characters have no version")
+	}
+	for _, game := range types.Games {
+		characters := BundledCharacters(game)
+		if len(characters) == 0 {
+			t.Errorf("no bundled characters for %s", game.Name())
+		}
+		for _, c := range characters {
+			if c.Id == 0 || c.Name == "" || c.Game != game {
+				t.Errorf("invalid bundled character %+v", c)
+			}
+		}
+	}
+
+	if url := avatarIconUrl("Raiden Shogun"); !strings.HasSuffix(url, "RaidenShogun/UI_AvatarIcon_Shougun.png") {
+		t.Errorf("expected the bundled avatar of Raiden Shogun got %s", url)
+	}
+	if !SameCharacter(types.ZZZ, "anby", "Anby Demara") || SameCharacter(types.ZZZ, "Anby", "Nicole") {
+		t.Error("names were not matched by the bundled aliases")
+	}
+}
+
+func TestMergeBundled(t *testing.T) {
+	fetched := []types.Character{{Id: util.HashForName("Anby"), Game: types.ZZZ, Name: "Anby"}}
+
+	merged := mergeBundled(types.ZZZ, fetched)
+	if len(merged) != len(BundledCharacters(types.ZZZ)) {
+		t.Errorf("expected the fetched Anby to replace the bundled one got %d characters", len(merged))
+	}
+	if merged[0].Name != "Anby" || merged[0].Element == "" {
+		t.Errorf("expected the fetched character to be kept with the bundled element got %+v", merged[0])
+	}
+	if slices.ContainsFunc(merged, func(c types.Character) bool { return c.Name == "Anby Demara" }) {
+		t.Error("the bundled Anby Demara was added next to the fetched Anby")
+	}
+
+	if offline := mergeBundled(types.StarRail, nil); len(offline) != len(BundledCharacters(types.StarRail)) {
+		t.Errorf("expected the bundled characters without the network got %d", len(offline))
+	}
+}
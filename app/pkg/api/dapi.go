@@ -9,7 +9,9 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gregjones/httpcache"
 	"github.com/gregjones/httpcache/diskcache"
@@ -21,6 +23,7 @@ type DataApi interface {
 	GetGame() types.Game
 	Elements() []string
 	Characters() []types.Character
+	Categories() []types.Character
 }
 
 var ApiList map[types.Game]DataApi = map[types.Game]DataApi{
@@ -30,6 +33,65 @@ var ApiList map[types.Game]DataApi = map[types.Game]DataApi{
 	types.WuWa:     NewWutherWavesApi(),
 }
 
+// categories for mods that do not belong to a character, the
+// name of the category is also its dir name
+func defaultCategories(game types.Game) []types.Character {
+	categories := make([]types.Character, 0, len(types.CategoryKinds))
+	for _, kind := range types.CategoryKinds {
+		categories = append(categories, types.Character{
+			Id:       util.CategoryId(kind.Name()),
+			Game:     game,
+			Name:     kind.Name(),
+			Category: kind,
+		})
+	}
+	return categories
+}
+
+// words in GameBanana category names mapped to the category they belong to
+var gbCategoryKeywords = map[types.CategoryKind][]string{
+	types.CATEGORY_WEAPON:      {"weapon", "weapons", "w-engine", "w-engines", "light cone", "light cones"},
+	types.CATEGORY_UI:          {"ui", "hud", "interface", "menu", "menus", "icons"},
+	types.CATEGORY_NPC:         {"npc", "npcs", "bangboo", "bangboos", "enemy", "enemies", "monster", "monsters"},
+	types.CATEGORY_ENVIRONMENT: {"environment", "environments", "map", "maps", "world", "scenery"},
+	types.CATEGORY_SHADER:      {"shader", "shaders", "reshade", "shaderfixes"},
+	types.CATEGORY_OTHER:       {"other", "others", "misc", "miscellaneous"},
+}
+
+// CategoryKindForGb maps the name of a GameBanana category to a category kind,
+// skins and unknown categories return types.CATEGORY_NONE
+func CategoryKindForGb(name string) types.CategoryKind {
+	name = strings.ToLower(name)
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+
+	for _, kind := range types.CategoryKinds {
+		for _, keyword := range gbCategoryKeywords[kind] {
+			if slices.Contains(words, keyword) || (strings.Contains(keyword, " ") && strings.Contains(name, keyword)) {
+				return kind
+			}
+		}
+	}
+	return types.CATEGORY_NONE
+}
+
+// MatchGbCategory returns the category of the game a GameBanana category maps to
+func MatchGbCategory(game types.Game, gbCategoryName string) (types.Character, bool) {
+	dataApi, ok := ApiList[game]
+	kind := CategoryKindForGb(gbCategoryName)
+	if !ok || kind == types.CATEGORY_NONE {
+		return types.Character{}, false
+	}
+
+	for _, c := range dataApi.Categories() {
+		if c.Category == kind {
+			return c, true
+		}
+	}
+	return types.Character{}, false
+}
+
 const CacheSizeBytes = 200 * 1024 * 1024 // 200 mb will be cleaned at 100
 
 func newCache() *diskv.Diskv {
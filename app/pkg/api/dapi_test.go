@@ -56,3 +56,29 @@ func TestZZZStarRailApi(t *testing.T) {
 
 	t.Fail()
 }
+
+func TestCategoryKindForGb(t *testing.T) {
+	cases := map[string]types.CategoryKind{
+		"Skins":        types.CATEGORY_NONE,
+		"Weapons":      types.CATEGORY_WEAPON,
+		"W-Engines":    types.CATEGORY_WEAPON,
+		"Light Cones":  types.CATEGORY_WEAPON,
+		"UI":           types.CATEGORY_UI,
+		"Builds":       types.CATEGORY_NONE,
+		"NPCs":         types.CATEGORY_NPC,
+		"Bangboo":      types.CATEGORY_NPC,
+		"Environments": types.CATEGORY_ENVIRONMENT,
+		"Shaders":      types.CATEGORY_SHADER,
+		"Other/Misc":   types.CATEGORY_OTHER,
+	}
+
+	for name, want := range cases {
+		if got := CategoryKindForGb(name); got != want {
+			t.Errorf("%s: expected %s got %s", name, want.Name(), got.Name())
+		}
+	}
+
+	if c, ok := MatchGbCategory(types.ZZZ, "W-Engines"); !ok || c.Game != types.ZZZ || c.Category != types.CATEGORY_WEAPON {
+		t.Errorf("expected the zzz weapon category got %+v", c)
+	}
+}
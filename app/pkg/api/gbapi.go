@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"hmm/pkg/log"
+	"hmm/pkg/types"
 	"io"
 	"strings"
 )
@@ -164,6 +165,15 @@ func (g *GbApi) Categories(id int) ([]CategoryListResponseItem, error) {
 	return items, nil
 }
 
+// CategoryFor returns the mod category a GameBanana category is browsed into
+func (g *GbApi) CategoryFor(game types.Game, gbCategoryName string) (types.Character, error) {
+	category, ok := MatchGbCategory(game, gbCategoryName)
+	if !ok {
+		return category, fmt.Errorf("%s is not a mod category", gbCategoryName)
+	}
+	return category, nil
+}
+
 func (g *GbApi) SubmitterItems(id int) (SubmissionPageResponse, error) {
 	url := fmt.Sprintf("%s/Member/%d/Submissions/Sublog?_nPage=1&_sSort=p_date&_sDirection=DESC&_sNameOperator=contains", GB_URL, id)
 	resp, err := client.Get(url)
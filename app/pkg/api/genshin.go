@@ -37,71 +37,36 @@ func (g *genshinApi) Elements() []string {
 	return []string{"Anemo", "Cryo", "Dendro", "Electro", "Geo", "Hydro", "Pyro"}
 }
 
+func (g *genshinApi) Categories() []types.Character {
+	return defaultCategories(g.Game)
+}
+
 func genshinCharUrl(path string) string {
 	return "https://raw.githubusercontent.com/theBowja/genshin-db/main/src/data/English/characters/" + path
 }
 
+// avatarIconUrl uses the bundled avatar of the character and falls back to
+// the folder and icon names genshin-optimizer uses for most characters
 func avatarIconUrl(name string) string {
-
-	avatar := func(folder string, path string) string {
-		return fmt.Sprintf("https://raw.githubusercontent.com/frzyc/genshin-optimizer/master/libs/gi/assets/src/gen/chars/%s/UI_AvatarIcon_%s.png", folder, path)
+	g := bundled().Games[types.Genshin.Name()]
+	if c, ok := g.find(name); ok && c.Avatar != "" {
+		return g.avatarUrl(c)
 	}
 
-	switch strings.ToLower(name) {
-	case "aether":
-		return avatar("TravelerM", "PlayerBoy")
-	case "lumine":
-		return avatar("TravelerF", "PlayerGirl")
-	case "shikanoin heizou":
-		return avatar("ShikanoinHeizou", "Heizo")
-	case "raiden shogun":
-		return avatar("RaidenShogun", "Shougun")
-	case "yae miko":
-		return avatar("YaeMiko", "Yae")
-	case "yun jin":
-		return avatar("YunJin", "Yunjin")
-	case "yanfei":
-		return avatar("Yanfei", "Feiyan")
-	case "jean":
-		return avatar("Jean", "Qin")
-	case "lyney":
-		return avatar("Lyney", "Liney")
-	case "xianyun":
-		return avatar("Xianyun", "Liuyun")
-	case "thoma":
-		return avatar("Thoma", "Tohma")
-	case "hu tao":
-		return avatar("HuTao", "Hutao")
-	case "kirara":
-		return avatar("Kirara", "Momoka")
-	case "baizhu":
-		return avatar("Baizhu", "Baizhuer")
-	case "alhaitham":
-		return avatar("Alhaitham", "Alhatham")
-	case "amber":
-		return avatar("Amber", "Ambor")
-	case "lynette":
-		return avatar("Lynette", "Linette")
-	case "noelle":
-		return avatar("Noelle", "Noel")
-	case "charlotte":
-		return "https://keqingmains.com/wp-content/uploads/2023/11/Charlotte_Icon.webp"
-	case "lan yan":
-		return avatar("LanYan", "Lanyan")
-	case "ororon":
-		return avatar("Ororon", "Olorun")
-	default:
-		split := strings.Split(name, " ")
-		last := split[len(split)-1]
-
-		sanitized := strings.ToUpper(string(last[0])) + strings.ReplaceAll(strings.ToLower(last[1:]), " ", "")
-		folder := strings.ReplaceAll(name, " ", "")
-
-		return avatar(folder, sanitized)
-	}
+	split := strings.Split(name, " ")
+	last := split[len(split)-1]
+
+	sanitized := strings.ToUpper(string(last[0])) + strings.ReplaceAll(strings.ToLower(last[1:]), " ", "")
+	folder := strings.ReplaceAll(name, " ", "")
+
+	return fmt.Sprintf(g.AvatarUrl, fmt.Sprintf("%s/UI_AvatarIcon_%s.png", folder, sanitized))
 }
 
 func (g *genshinApi) Characters() []types.Character {
+	return mergeBundled(g.Game, g.fetchCharacters())
+}
+
+func (g *genshinApi) fetchCharacters() []types.Character {
 	resp, err := client.Get("https://api.github.com/repos/theBowja/genshin-db/contents/src/data/English/characters")
 
 	if err != nil {
@@ -37,6 +37,10 @@ func (s *starRailApi) Elements() []string {
 	return []string{"Ice", "Physical", "Fire", "Lightning", "Wind", "Quantum", "Imaginary"}
 }
 
+func (s *starRailApi) Categories() []types.Character {
+	return defaultCategories(s.Game)
+}
+
 type Characters map[string]struct {
 	Id         int      `json:"id,string"`
 	Name       string   `json:"name"`
@@ -54,6 +58,10 @@ type Characters map[string]struct {
 }
 
 func (s *starRailApi) Characters() []types.Character {
+	return mergeBundled(s.Game, s.fetchCharacters())
+}
+
+func (s *starRailApi) fetchCharacters() []types.Character {
 	resp, err := client.Get("https://raw.githubusercontent.com/Mar-7th/StarRailRes/master/index_new/en/characters.json")
 
 	if err != nil {
@@ -39,7 +39,15 @@ func (w *wutheringWavesApi) Elements() []string {
 	return []string{"Aero", "Electro", "Fusion", "Glacio", "Havoc", "Spectro"}
 }
 
+func (w *wutheringWavesApi) Categories() []types.Character {
+	return defaultCategories(w.Game)
+}
+
 func (w *wutheringWavesApi) Characters() []types.Character {
+	return mergeBundled(w.Game, w.fetchCharacters())
+}
+
+func (w *wutheringWavesApi) fetchCharacters() []types.Character {
 	r, err := client.Get(fmt.Sprintf("%s/wuthering-waves/characters/", PRYDWEN_URL))
 	if err != nil {
 		return []types.Character{}
@@ -40,7 +40,15 @@ func (z *zenlessZoneZeroApi) Elements() []string {
 	return []string{"Electric", "Ether", "Fire", "Ice", "Physical"}
 }
 
+func (z *zenlessZoneZeroApi) Categories() []types.Character {
+	return defaultCategories(z.Game)
+}
+
 func (z *zenlessZoneZeroApi) Characters() []types.Character {
+	return mergeBundled(z.Game, z.fetchCharacters())
+}
+
+func (z *zenlessZoneZeroApi) fetchCharacters() []types.Character {
 	r, err := client.Get(fmt.Sprintf("%s/zenless/characters/", PRYDWEN_URL))
 	if err != nil {
 		return []types.Character{}
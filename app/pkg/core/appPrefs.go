@@ -17,6 +17,7 @@ type AppPrefs struct {
 	WuwaDirPref            *WuwaDirPref
 	IgnoreDirPref          *IgnoreDirPref
 	SortModPref            *SortModPref
+	LibrarySortPref        *LibrarySortPref
 	ModsAvailablePref      *ModsAvailablePref
 	GenshinElementPref     *GenshinElementPref
 	HonkaiElementPref      *HonkaiElementPref
@@ -30,6 +31,8 @@ type AppPrefs struct {
 	ServerUsernamePref     *ServerUsernamePref
 	ServerPasswordPref     *ServerPasswordPref
 	SpaceSaverPref         *SpaceSaverPref
+	CompressionFormatPref  *CompressionFormatPref
+	CompressionLevelPref   *CompressionLevelPref
 	CleanModExportDirPref  *CleanModExportDirPref
 	EnabledPluginsPref     *EnabledPluginsPref
 	RootModDirPref         *RootModDirPref
@@ -38,6 +41,10 @@ type AppPrefs struct {
 	Oneko                  *Oneko
 	EllenFix               *EllenFix
 	ToastLevelPref         *ToastLevelPref
+	SyncDeleteGracePref    *SyncDeleteGracePref
+	ExclusiveModGamesPref  *ExclusiveModGamesPref
+	RecycleRetentionPref   *RecycleRetentionPref
+	CharacterDataPref      *CharacterDataPref
 }
 
 func NewAppPrefs(store pref.PreferenceStore) *AppPrefs {
@@ -67,6 +74,9 @@ func NewAppPrefs(store pref.PreferenceStore) *AppPrefs {
 		&SortModPref{
 			Preference: store.GetString("sortModPref", ""),
 		},
+		&LibrarySortPref{
+			Preference: store.GetString("library_sort", string(types.SORT_MOD_NAME)),
+		},
 		&ModsAvailablePref{
 			Preference: store.GetBoolean("modsAvailable", false),
 		},
@@ -107,6 +117,12 @@ func NewAppPrefs(store pref.PreferenceStore) *AppPrefs {
 		&SpaceSaverPref{
 			Preference: store.GetBoolean("space_saver", true),
 		},
+		&CompressionFormatPref{
+			Preference: store.GetString("compression_format", string(ZipDeflate)),
+		},
+		&CompressionLevelPref{
+			Preference: store.GetInt("compression_level", 0),
+		},
 		&CleanModExportDirPref{
 			Preference: store.GetBoolean("clean_mod_dir", false),
 		},
@@ -131,6 +147,18 @@ func NewAppPrefs(store pref.PreferenceStore) *AppPrefs {
 		&ToastLevelPref{
 			Preference: store.GetInt("toast_level", 0),
 		},
+		&SyncDeleteGracePref{
+			Preference: store.GetInt("sync_delete_grace_days", 7),
+		},
+		&ExclusiveModGamesPref{
+			Preference: store.GetStringSlice("exclusive_mod_games", []string{}),
+		},
+		&RecycleRetentionPref{
+			Preference: store.GetInt("recycle_retention_days", 30),
+		},
+		&CharacterDataPref{
+			Preference: store.GetStringSlice("character_data_versions", []string{}),
+		},
 	}
 }
 
@@ -141,6 +169,9 @@ type StartScreenPref struct{ pref.Preference[string] }
 
 type SortModPref struct{ pref.Preference[string] }
 
+// a types.ModSort for the mods of each character in the library
+type LibrarySortPref struct{ pref.Preference[string] }
+
 type ModsAvailablePref struct{ pref.Preference[bool] }
 
 type MaxDownloadWorkersPref struct{ pref.Preference[int] }
@@ -169,9 +200,39 @@ type ServerPasswordPref struct{ pref.Preference[string] }
 type ServerAuthTypePref struct{ pref.Preference[int] }
 
 type SpaceSaverPref struct{ pref.Preference[bool] }
+type CompressionFormatPref struct{ pref.Preference[string] }
+type CompressionLevelPref struct{ pref.Preference[int] }
+
+// falls back to the default format if the saved one can not be written
+func (a *AppPrefs) CompressionOptions() CompressionOptions {
+	return compressionOptionsFrom(a.CompressionFormatPref.Preference, a.CompressionLevelPref.Preference)
+}
+
+func compressionOptionsFrom(format pref.Preference[string], level pref.Preference[int]) CompressionOptions {
+	opts := CompressionOptions{
+		Format: CompressionFormat(format.Get()),
+		Level:  level.Get(),
+	}
+	if !opts.Format.Supported() {
+		opts.Format = DefaultCompressionOptions.Format
+	}
+	return opts
+}
 
 type EnabledPluginsPref struct{ pref.Preference[[]string] }
 
+// days before a missing mod with user data is removed by sync, 0 waits for confirmation
+type SyncDeleteGracePref struct{ pref.Preference[int] }
+
+// names of the games where enabling a mod disables the other mods of the character
+type ExclusiveModGamesPref struct{ pref.Preference[[]string] }
+
+// days deleted mods are kept in the recycle bin, 0 keeps them until it is emptied
+type RecycleRetentionPref struct{ pref.Preference[int] }
+
+// Game=version of the bundled characters each game was seeded with
+type CharacterDataPref struct{ pref.Preference[[]string] }
+
 type LastReleaseAckedDate struct{ pref.Preference[string] }
 
 type UseViewTransitions struct{ pref.Preference[bool] }
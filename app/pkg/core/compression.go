@@ -0,0 +1,382 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hmm/pkg/log"
+	"hmm/pkg/util"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/alitto/pond/v2"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/zstd"
+	"github.com/mholt/archives"
+)
+
+type CompressionFormat string
+
+const (
+	ZipDeflate CompressionFormat = "zip-deflate"
+	ZipZstd    CompressionFormat = "zip-zstd"
+	SevenZip   CompressionFormat = "7z"
+	TarZstd    CompressionFormat = "tar.zst"
+
+	convertStateFile = "storage_convert.json"
+	convertTmpPrefix = ".hmm-convert-"
+)
+
+var CompressionFormats = []CompressionFormat{ZipDeflate, ZipZstd, SevenZip, TarZstd}
+
+// level 0 uses the best compression available for the format
+var DefaultCompressionOptions = CompressionOptions{Format: ZipDeflate, Level: 0}
+
+type CompressionOptions struct {
+	Format CompressionFormat `json:"format"`
+	Level  int               `json:"level"`
+}
+
+func (f CompressionFormat) Extension() string {
+	switch f {
+	case SevenZip:
+		return ".7z"
+	case TarZstd:
+		return ".tar.zst"
+	default:
+		return ".zip"
+	}
+}
+
+// 7z can be extracted but archives has no writer for it
+func (f CompressionFormat) Supported() bool {
+	switch f {
+	case ZipDeflate, ZipZstd, TarZstd:
+		return true
+	default:
+		return false
+	}
+}
+
+// Level is on a 1 (fastest) - 9 (smallest) scale for every format
+func (o CompressionOptions) deflateLevel() int {
+	if o.Level <= 0 || o.Level > flate.BestCompression {
+		return flate.BestCompression
+	}
+	return o.Level
+}
+
+func (o CompressionOptions) zstdLevel() zstd.EncoderLevel {
+	if o.Level <= 0 || o.Level > 9 {
+		return zstd.SpeedBestCompression
+	}
+	return zstd.EncoderLevelFromZstd(o.Level * 22 / 9)
+}
+
+// trims the archive extension including multi part ones like .tar.zst
+func trimArchiveExt(path string) string {
+	lower := strings.ToLower(path)
+	for ext := range unarrExtensions {
+		if strings.Count(ext, ".") > 1 && strings.HasSuffix(lower, ext) {
+			return path[:len(path)-len(ext)]
+		}
+	}
+	if strings.HasSuffix(lower, TarZstd.Extension()) {
+		return path[:len(path)-len(TarZstd.Extension())]
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+func isLibraryArchive(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return unarrSupported(ext) || ext == ".zst"
+}
+
+func hashFile(open func() (io.ReadCloser, error)) (string, error) {
+	f, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// VerifyArchive checks that every file in srcDir exists in the archive with the same content
+func VerifyArchive(ctx context.Context, archive string, srcDir string) error {
+
+	expected := map[string]string{}
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		sum, err := hashFile(func() (io.ReadCloser, error) { return os.Open(path) })
+		if err != nil {
+			return err
+		}
+		expected[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fsys, err := archives.FileSystem(ctx, archive, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		want, ok := expected[path]
+		if !ok {
+			return fmt.Errorf("unexpected file in archive %s", path)
+		}
+		sum, err := hashFile(func() (io.ReadCloser, error) { return fsys.Open(path) })
+		if err != nil {
+			return err
+		}
+		if sum != want {
+			return fmt.Errorf("content mismatch for %s", path)
+		}
+		delete(expected, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(expected) > 0 {
+		return fmt.Errorf("archive %s is missing %d files", archive, len(expected))
+	}
+	return nil
+}
+
+type convertState struct {
+	Options CompressionOptions `json:"options"`
+	Done    []string           `json:"done"`
+	// archives that could not be converted, skipped and reported by later runs
+	Failed []string `json:"failed"`
+	// the last run went through every archive, only failed ones were left
+	Finished bool `json:"finished"`
+}
+
+func convertStatePath() string {
+	return filepath.Join(util.GetCacheDir(), convertStateFile)
+}
+
+func loadConvertState() (convertState, bool) {
+	var state convertState
+	b, err := os.ReadFile(convertStatePath())
+	if err != nil {
+		return state, false
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		log.LogError(err.Error())
+		return state, false
+	}
+	return state, true
+}
+
+func saveConvertState(state convertState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(convertStatePath(), b, 0644)
+}
+
+// PendingStorageConversion returns the options of a conversion that was
+// interrupted before finishing, so it can be resumed after a restart
+func PendingStorageConversion() (CompressionOptions, bool) {
+	state, ok := loadConvertState()
+	return state.Options, ok && !state.Finished
+}
+
+// ClearStorageConversion forgets the progress of a conversion the user cancelled
+// so it is not resumed on the next start
+func ClearStorageConversion() {
+	if err := os.Remove(convertStatePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.LogError(err.Error())
+	}
+}
+
+// converts a single archive extracting it and verifying the new archive
+// before the original is replaced
+func convertArchive(ctx context.Context, path string, opts CompressionOptions) error {
+
+	extracted, err := os.MkdirTemp(os.TempDir(), "")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extracted)
+
+	if _, err = ArchiveExtract(path, extracted, false, true, nil); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// written next to the original so the final rename stays on the same drive
+	work, err := os.MkdirTemp(filepath.Dir(path), convertTmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	newPath := trimArchiveExt(path) + opts.Format.Extension()
+	if newPath != path {
+		if exists, _ := util.FileExists(newPath); exists {
+			return fmt.Errorf("can not convert %s %s already exists", path, newPath)
+		}
+	}
+
+	dest := filepath.Join(work, filepath.Base(newPath))
+	if err = CompressFolder(extracted, dest, opts, func(total, complete int) {}); err != nil {
+		return err
+	}
+
+	if err = VerifyArchive(ctx, dest, extracted); err != nil {
+		return fmt.Errorf("verification failed for %s: %w", path, err)
+	}
+
+	if err = os.Rename(dest, newPath); err != nil {
+		return err
+	}
+
+	if newPath != path {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// ConvertLibraryStorage re-compresses every archive under root to the given format.
+// progress is saved after each archive so an interrupted job can be resumed
+// by calling this again with the same options
+func ConvertLibraryStorage(
+	ctx context.Context,
+	root string,
+	opts CompressionOptions,
+	workers int,
+	onProgress func(total int, complete int),
+) error {
+
+	if !opts.Format.Supported() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCompression, opts.Format)
+	}
+
+	state, ok := loadConvertState()
+	if !ok || state.Options != opts {
+		state = convertState{Options: opts, Done: []string{}, Failed: []string{}}
+	}
+	state.Finished = false
+	if err := saveConvertState(state); err != nil {
+		return err
+	}
+
+	failed := []error{}
+	for _, path := range state.Failed {
+		failed = append(failed, fmt.Errorf("skipped %s it could not be converted before", path))
+	}
+
+	paths := []string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// left over from a conversion that was interrupted
+		if d.IsDir() && strings.HasPrefix(d.Name(), convertTmpPrefix) {
+			os.RemoveAll(path)
+			return fs.SkipDir
+		}
+		if !d.IsDir() && isLibraryArchive(path) && !slices.Contains(state.Done, path) && !slices.Contains(state.Failed, path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if workers <= 0 {
+		workers = max(1, runtime.NumCPU()/2)
+	}
+
+	pool := pond.NewPool(workers, pond.WithContext(ctx))
+	defer pool.StopAndWait()
+
+	mutex := sync.Mutex{}
+	total := len(paths)
+	complete := 0
+	onProgress(total, complete)
+
+	group := pool.NewGroup()
+	for _, path := range paths {
+		group.Submit(func() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			err := convertArchive(ctx, path, opts)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case err != nil && ctx.Err() != nil:
+				// cancelled archives are converted when the job is resumed
+			case err != nil:
+				log.LogError(err.Error())
+				failed = append(failed, err)
+				state.Failed = append(state.Failed, path)
+			default:
+				state.Done = append(state.Done, trimArchiveExt(path)+opts.Format.Extension())
+			}
+			if err := saveConvertState(state); err != nil {
+				log.LogError(err.Error())
+			}
+			complete += 1
+			onProgress(total, complete)
+		})
+	}
+
+	group.Wait()
+
+	if ctx.Err() != nil {
+		return errors.Join(ctx.Err(), errors.Join(failed...))
+	}
+
+	// failed archives are kept in the state so the next run with the same
+	// options skips them, the job is not resumed on start
+	if len(state.Failed) == 0 {
+		os.Remove(convertStatePath())
+	} else {
+		state.Finished = true
+		if err := saveConvertState(state); err != nil {
+			log.LogError(err.Error())
+		}
+	}
+
+	return errors.Join(failed...)
+}
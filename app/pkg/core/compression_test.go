@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressFolderFormats(t *testing.T) {
+
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "nested"), os.ModePerm)
+	os.WriteFile(filepath.Join(src, "mod.ini"), []byte("[Constants]\nswapvar = 1\n"), 0644)
+	os.WriteFile(filepath.Join(src, "nested", "body.buf"), []byte("buffer data"), 0644)
+
+	for _, format := range CompressionFormats {
+		opts := CompressionOptions{Format: format, Level: 3}
+		dest := filepath.Join(t.TempDir(), "mod"+format.Extension())
+
+		err := CompressFolder(src, dest, opts, func(total, complete int) {})
+		if !format.Supported() {
+			if err == nil {
+				t.Errorf("expected %s to be unsupported", format)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if err := VerifyArchive(context.Background(), dest, src); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+}
+
+func TestTrimArchiveExt(t *testing.T) {
+	cases := map[string]string{
+		"mod.zip":     "mod",
+		"mod.tar.zst": "mod",
+		"mod.tar.gz":  "mod",
+		"mod.v2.7z":   "mod.v2",
+	}
+	for in, want := range cases {
+		if got := trimArchiveExt(in); got != want {
+			t.Errorf("trimArchiveExt(%s) = %s want %s", in, got, want)
+		}
+	}
+}
+
+func TestConvertLibraryStorageSkipsFailed(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	os.MkdirAll(filepath.Dir(convertStatePath()), os.ModePerm)
+
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "mod.ini"), []byte("[Constants]"), 0644)
+
+	root := t.TempDir()
+	if err := CompressFolder(src, filepath.Join(root, "good.zip"), DefaultCompressionOptions, func(total, complete int) {}); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(root, "bad.zip")
+	os.WriteFile(bad, []byte("not an archive"), 0644)
+
+	opts := CompressionOptions{Format: TarZstd, Level: 3}
+	if err := ConvertLibraryStorage(context.Background(), root, opts, 1, func(total, complete int) {}); err == nil {
+		t.Fatal("expected the broken archive to fail")
+	}
+	if _, ok := PendingStorageConversion(); ok {
+		t.Error("a conversion that went through every archive should not be resumed")
+	}
+	state, _ := loadConvertState()
+	if len(state.Failed) != 1 || state.Failed[0] != bad {
+		t.Errorf("expected the failed archive in the state got %+v", state)
+	}
+
+	converted := 0
+	err := ConvertLibraryStorage(context.Background(), root, opts, 1, func(total, complete int) { converted = total })
+	if err == nil || converted != 0 {
+		t.Errorf("expected the failed archive to be skipped and reported got %d %v", converted, err)
+	}
+
+	ClearStorageConversion()
+	if _, ok := loadConvertState(); ok {
+		t.Error("expected the state to be removed")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"hmm/pkg/util"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -79,9 +80,21 @@ func (cs *ConfigSaver) saveConfig(g types.Game) ([]types.Mod, error) {
 		}
 		defer file.Close()
 
-		file.WriteString("[Constants]\n")
+		// one section of constants for each ini of the mod, see configForIni
+		sections := []string{}
+		bySection := map[string][]D3dxEntry{}
 		for _, entry := range group {
-			file.WriteString(entry.ivar + " = " + entry.ival + "\n")
+			section := entry.iniPath()
+			if _, ok := bySection[section]; !ok {
+				sections = append(sections, section)
+			}
+			bySection[section] = append(bySection[section], entry)
+		}
+		for _, section := range sections {
+			file.WriteString("[" + section + "]\n")
+			for _, entry := range bySection[section] {
+				file.WriteString(entry.ivar + " = " + entry.ival + "\n")
+			}
 		}
 		created = append(created, group[0].mod)
 	}
@@ -108,6 +121,37 @@ func GetEnabledConfig(m types.Mod) (*ini.File, error) {
 	return ini.Load(b)
 }
 
+// configForIni gets the constants saved for the ini at relPath of the generated mod as
+// a Constants section. files saved before mods had more than one ini only have a
+// Constants section which is used for every ini
+func configForIni(config *ini.File, relPath string) *ini.File {
+	if config == nil {
+		return nil
+	}
+	sec, err := config.GetSection(savedConfSection(relPath))
+	if err != nil {
+		sec, err = config.GetSection("Constants")
+		if err != nil {
+			return nil
+		}
+	}
+
+	cfg := ini.Empty()
+	constants, err := cfg.NewSection("Constants")
+	if err != nil {
+		return nil
+	}
+	for _, key := range sec.Keys() {
+		constants.NewKey(key.Name(), key.Value())
+	}
+	return cfg
+}
+
+// d3dx_user.ini has lowercase paths
+func savedConfSection(relPath string) string {
+	return strings.ToLower(filepath.ToSlash(relPath))
+}
+
 type D3dxEntry struct {
 	mod      types.Mod
 	modFname string
@@ -118,6 +162,11 @@ type D3dxEntry struct {
 	ival     string
 }
 
+// path of the ini relative to the generated mod
+func (e D3dxEntry) iniPath() string {
+	return savedConfSection(path.Join(strings.ReplaceAll(e.relpath, "\\", "/"), e.ini))
+}
+
 // reads config from d3dxUser Ini ignoring non mod entries
 func (cs *ConfigSaver) readD3dxUserIni(g types.Game) ([]D3dxEntry, error) {
 
@@ -148,6 +197,8 @@ func (cs *ConfigSaver) readD3dxUserIni(g types.Game) ([]D3dxEntry, error) {
 		// IMPORTANT all entries should have the mod id as a prefix
 		if strings.HasPrefix(line, "$\\mods") {
 			trimmed := strings.TrimPrefix(line, "$\\mods\\")
+			// category mods are exported into util.CategoriesDir
+			trimmed = strings.TrimPrefix(trimmed, strings.ToLower(util.CategoriesDir)+"\\")
 
 			parts := strings.Split(trimmed, "\\")
 			if len(parts) == 0 {
@@ -183,7 +234,7 @@ func (cs *ConfigSaver) readD3dxUserIni(g types.Game) ([]D3dxEntry, error) {
 				}
 			}
 
-			if rel == "" || ini == "" {
+			if ini == "" {
 				continue
 			}
 
@@ -3,13 +3,13 @@ package core
 import (
 	"context"
 	"database/sql"
-	"hmm/db"
 	"hmm/pkg/core/dbh"
 	"hmm/pkg/pref"
 	"hmm/pkg/types"
 	"hmm/pkg/util"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gopkg.in/ini.v1"
@@ -97,8 +97,7 @@ func TestReadConfig(t *testing.T) {
 		panic(err)
 	}
 
-	queries := db.New(dbSql)
-	dbHelper := dbh.NewDbHelper(queries, dbSql)
+	dbHelper := dbh.NewDbHelper(dbSql)
 
 	dirs := map[types.Game]string{
 		types.Genshin:  genshinDir,
@@ -153,3 +152,60 @@ func TestReadConfig(t *testing.T) {
 
 	t.Fail()
 }
+
+func TestOverwriteMultipleInis(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	s.db.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	mod := types.Mod{Filename: "outfit", Game: types.Genshin, Character: "Nahida", CharacterId: 1}
+	id, err := s.db.InsertMod(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod.Id = int(id)
+
+	exportDir := filepath.Join(root, "export", "Mods")
+	outputDir := filepath.Join(exportDir, "1_outfit")
+	constants := "[Constants]\nglobal persist $swapvar = 0\n"
+	for _, file := range []string{"Root/Body.ini", "Root/face/face.ini", "Root/DISABLED_old.ini"} {
+		os.MkdirAll(filepath.Dir(filepath.Join(outputDir, file)), os.ModePerm)
+		os.WriteFile(filepath.Join(outputDir, file), []byte(constants), 0666)
+	}
+	os.WriteFile(filepath.Join(root, "export", d3dxUserFile), []byte(
+		"[Constants]\n"+
+			"$\\mods\\1_outfit\\root\\body.ini\\swapvar = 1\n"+
+			"$\\mods\\1_outfit\\root\\face\\face.ini\\swapvar = 2\n",
+	), 0666)
+
+	prefs := pref.NewPrefs(pref.NewInMemoryStore(context.Background()))
+	dirs := map[types.Game]pref.Preference[string]{types.Genshin: prefs.GetString("genshin_dir", "")}
+	dirs[types.Genshin].Set(exportDir)
+	if _, err := NewConfigSaver(dirs, s.db).saveConfig(types.Genshin); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := overwriteMergedIniIfneeded(mod, outputDir, nil, s.db); err != nil {
+		t.Fatal(err)
+	}
+	for file, value := range map[string]string{"Root/Body.ini": "= 1", "Root/face/face.ini": "= 2", "Root/DISABLED_old.ini": "= 0"} {
+		b, _ := os.ReadFile(filepath.Join(outputDir, file))
+		if !strings.Contains(string(b), value) {
+			t.Errorf("expected %s in %s got %s", value, file, b)
+		}
+	}
+
+	// new files are only found once the cache is invalidated by a change of the textures
+	os.WriteFile(filepath.Join(outputDir, "Root", "added.ini"), []byte(constants), 0666)
+	paths, _ := GetRelativeIniPaths(mod, outputDir, nil, s.db)
+	if len(paths) != 2 {
+		t.Errorf("expected the cached inis got %v", paths)
+	}
+	paths, _ = GetRelativeIniPaths(mod, outputDir, []types.Texture{{Id: 1, Filename: "texture", Enabled: true}}, s.db)
+	if len(paths) != 3 {
+		t.Errorf("expected the cache to be invalidated got %v", paths)
+	}
+}
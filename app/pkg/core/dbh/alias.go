@@ -0,0 +1,127 @@
+package dbh
+
+import (
+	"database/sql"
+	"errors"
+	"hmm/db"
+	"hmm/pkg/api"
+	"hmm/pkg/types"
+	"strings"
+)
+
+type CharacterAliasDao interface {
+	SelectCharacterAliases(game types.Game) ([]types.CharacterAlias, error)
+	AddCharacterAlias(game types.Game, characterId int, alias string) error
+	DeleteCharacterAlias(game types.Game, alias string) error
+	SeedCharacterAliases(game types.Game, aliases map[string][]string) error
+	SelectCharacterForGbCategory(game types.Game, gbCategoryName string) (types.Character, error)
+}
+
+var _ CharacterAliasDao = (*DbHelper)(nil)
+
+func characterAliasFromDb(a db.CharacterAlias) types.CharacterAlias {
+	return types.CharacterAlias{
+		CharacterId: int(a.CharID),
+		Game:        types.Game(a.Game),
+		Alias:       a.Alias,
+		Custom:      a.Custom,
+		Disabled:    a.Disabled,
+	}
+}
+
+func (h *DbHelper) SelectCharacterAliases(game types.Game) ([]types.CharacterAlias, error) {
+	rows, err := h.queries.SelectCharacterAliasesByGame(h.ctx, game.Int64())
+	if err != nil {
+		return []types.CharacterAlias{}, err
+	}
+
+	aliases := make([]types.CharacterAlias, 0, len(rows))
+	for _, row := range rows {
+		aliases = append(aliases, characterAliasFromDb(row))
+	}
+	return aliases, nil
+}
+
+// AddCharacterAlias saves a user alias, an alias of another character is moved to this one
+func (h *DbHelper) AddCharacterAlias(game types.Game, characterId int, alias string) error {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return errors.New("alias was empty")
+	}
+	if _, err := h.SelectCharacterById(characterId, game); err != nil {
+		return err
+	}
+	return h.queries.UpsertCustomCharacterAlias(h.ctx, db.UpsertCustomCharacterAliasParams{
+		Game:   game.Int64(),
+		CharId: int64(characterId),
+		Alias:  alias,
+	})
+}
+
+// DeleteCharacterAlias deletes a user alias and disables a bundled one
+func (h *DbHelper) DeleteCharacterAlias(game types.Game, alias string) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		if err := q.DeleteCustomCharacterAlias(h.ctx, db.DeleteCustomCharacterAliasParams{
+			Game:  game.Int64(),
+			Alias: alias,
+		}); err != nil {
+			return err
+		}
+		return q.DisableCharacterAlias(h.ctx, db.DisableCharacterAliasParams{
+			Game:  game.Int64(),
+			Alias: alias,
+		})
+	})
+}
+
+// SeedCharacterAliases adds the bundled aliases keyed by character name, aliases that are
+// already saved keep the character and state they have
+func (h *DbHelper) SeedCharacterAliases(game types.Game, aliases map[string][]string) error {
+	characters, err := h.SelectCharactersByGame(game)
+	if err != nil {
+		return err
+	}
+
+	return h.withTransaction(func(q *db.Queries) error {
+		for _, c := range characters {
+			for _, alias := range aliases[c.Name] {
+				err := q.InsertBundledCharacterAlias(h.ctx, db.InsertBundledCharacterAliasParams{
+					Game:   game.Int64(),
+					CharId: int64(c.Id),
+					Alias:  alias,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// SelectClosestCharacter matches the name against the names and enabled aliases of the
+// characters of the game, see matchCharacterName
+func (h *DbHelper) SelectClosestCharacter(name string, game types.Game) (types.Character, error) {
+	characters, err := h.SelectCharactersByGame(game)
+	if err != nil {
+		return types.Character{}, err
+	}
+	aliases, err := h.SelectCharacterAliases(game)
+	if err != nil {
+		return types.Character{}, err
+	}
+
+	if c, ok := matchCharacterName(name, characters, aliases); ok {
+		return c, nil
+	}
+	return types.Character{}, sql.ErrNoRows
+}
+
+// SelectCharacterForGbCategory maps the name of a GameBanana category to the mod category it
+// is browsed into or the character it is named after
+func (h *DbHelper) SelectCharacterForGbCategory(game types.Game, gbCategoryName string) (types.Character, error) {
+	if category, ok := api.MatchGbCategory(game, gbCategoryName); ok {
+		return category, nil
+	}
+	return h.SelectClosestCharacter(gbCategoryName, game)
+}
@@ -16,6 +16,9 @@ type CharacterDao interface {
 	deleteCharcterById(id int64) error
 	UpsertCharacter(c types.Character) error
 	SelectCharactersByGame(game types.Game) ([]types.Character, error)
+	SelectCharacterById(id int, game types.Game) (types.Character, error)
+	SelectCategoriesByGame(game types.Game) ([]types.Character, error)
+	UpdateCharacterExclusive(id int, game types.Game, mode types.ExclusiveMode) error
 	SelectCharacterWithModsTagsAndTextures(game types.Game, modFileName string, characterName string, tagName string) ([]types.CharacterWithModsAndTags, error)
 }
 
@@ -29,6 +32,8 @@ func characterFromDb(c db.Character) types.Character {
 		AvatarUrl: c.AvatarUrl,
 		Element:   c.Element,
 		Custom:    c.Flags&CHAR_FLAG_IS_CUSTOM != 0,
+		Category:  types.CategoryKind(c.Category),
+		Exclusive: types.ExclusiveMode(c.Exclusive),
 	}
 }
 
@@ -48,12 +53,25 @@ func (h *DbHelper) SelectCharactersByGame(game types.Game) ([]types.Character, e
 	return result, nil
 }
 
-func (h *DbHelper) SelectClosestCharacter(name string, game types.Game) (types.Character, error) {
-	value, err := h.queries.SelectClosestCharacter(h.ctx, db.SelectClosestCharacterParams{Name: name, Game: int64(game)})
+func (h *DbHelper) SelectCharacterById(id int, game types.Game) (types.Character, error) {
+	c, err := h.queries.SelectCharacterById(h.ctx, db.SelectCharacterByIdParams{ID: int64(id), Game: game.Int64()})
 	if err != nil {
 		return types.Character{}, err
 	}
-	return characterFromDb(value), nil
+	return characterFromDb(c), nil
+}
+
+func (h *DbHelper) SelectCategoriesByGame(game types.Game) ([]types.Character, error) {
+	categories, err := h.queries.SelectCategoriesByGame(h.ctx, game.Int64())
+	if err != nil {
+		return make([]types.Character, 0), err
+	}
+
+	result := make([]types.Character, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, characterFromDb(c))
+	}
+	return result, nil
 }
 
 func (h *DbHelper) deleteCharacterById(id int64) error {
@@ -63,14 +81,15 @@ func (h *DbHelper) deleteCharacterById(id int64) error {
 func (h *DbHelper) UpsertCharacter(c types.Character) error {
 
 	const upsertCharacterQuery = `
-		INSERT INTO character(id, game, name, avatar_url, element, flags) 
-		VALUES(?, ?, ?, ?, ?, ?) 
+		INSERT INTO character(id, game, name, avatar_url, element, flags, category) 
+		VALUES(?, ?, ?, ?, ?, ?, ?) 
 		ON CONFLICT (id, game) 
 		DO UPDATE SET 
 			avatar_url = ?,
 			name = ?,
 			element = ?,
-			flags = ?
+			flags = ?,
+			category = ?
 	`
 
 	if c.Name != "" && c.Id != 0 {
@@ -88,10 +107,12 @@ func (h *DbHelper) UpsertCharacter(c types.Character) error {
 			c.AvatarUrl,
 			c.Element,
 			flags,
+			c.Category,
 			c.AvatarUrl,
 			c.Name,
 			c.Element,
 			flags,
+			c.Category,
 		)
 		return err
 	}
@@ -121,6 +142,8 @@ func (h *DbHelper) SelectCharacterWithModsTagsAndTextures(game types.Game, modFi
 			AvatarUrl: item.AvatarUrl,
 			Element:   item.Element,
 			Custom:    item.Flags&CHAR_FLAG_IS_CUSTOM != 0,
+			Category:  types.CategoryKind(item.Category),
+			Exclusive: types.ExclusiveMode(item.Exclusive),
 		}
 
 		if _, exists := charMap[char]; !exists {
@@ -142,7 +165,21 @@ func (h *DbHelper) SelectCharacterWithModsTagsAndTextures(game types.Game, modFi
 						ModLink:        item.ModLink.String,
 						GbFileName:     item.GbFileName.String,
 						GbDownloadLink: item.GbDownloadLink.String,
+						Quarantined:    item.Flags_2.Int64&MOD_FLAG_QUARANTINED != 0,
+						Category:       item.Flags_2.Int64&MOD_FLAG_CATEGORY != 0,
 						Id:             modId,
+						GbName:         item.GbName.String,
+						Author:         item.Author.String,
+						Version:        item.Version.String,
+						Description:    item.Description.String,
+						ContentRatings: splitContentRatings(item.ContentRatings.String),
+						AddedAt:        item.AddedAt.Int64,
+						LastEnabledAt:  item.LastEnabledAt.Int64,
+						GbUpdatedAt:    item.GbUpdatedAt.Int64,
+						Size:           item.Size.Int64,
+						Favorite:       item.Favorite.Bool,
+						Rating:         int(item.Rating.Int64),
+						Notes:          item.Notes.String,
 					},
 					Tags:     []types.Tag{},
 					Textures: []types.Texture{},
@@ -0,0 +1,56 @@
+package dbh
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// dbConn is the connection every query of the helper goes through. RecreateDatabase
+// swaps the db behind it, the lock is only held for a single statement so queries
+// made inside a transaction can not deadlock with a swap
+type dbConn struct {
+	mutex sync.RWMutex
+	db    *sql.DB
+}
+
+func (c *dbConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.db.ExecContext(ctx, query, args...)
+}
+
+func (c *dbConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.db.PrepareContext(ctx, query)
+}
+
+func (c *dbConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.db.QueryContext(ctx, query, args...)
+}
+
+func (c *dbConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+
+func (c *dbConn) Begin() (*sql.Tx, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.db.Begin()
+}
+
+// swap runs replace once no statement is running and no new one can start,
+// the db it returns is used from then on
+func (c *dbConn) swap(replace func(old *sql.DB) (*sql.DB, error)) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	db, err := replace(c.db)
+	c.db = db
+	return err
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"hmm/db"
 	"hmm/pkg/log"
+	"hmm/pkg/pref"
 	"hmm/pkg/types"
 	"hmm/pkg/util"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/pressly/goose/v3"
@@ -20,18 +24,55 @@ import (
 
 const (
 	CHAR_FLAG_IS_CUSTOM = 1 << 0
+
+	// set while the mod has quarantined files that were not allowed
+	MOD_FLAG_QUARANTINED = 1 << 0
+	// set while the mod is stored in a category dir
+	MOD_FLAG_CATEGORY = 1 << 1
 )
 
+func categoryFlag(c types.Character) int64 {
+	if c.IsCategory() {
+		return MOD_FLAG_CATEGORY
+	}
+	return 0
+}
+
 type DbHelper struct {
+	conn            *dbConn
 	queries         *db.Queries
 	ctx             context.Context
 	db              db.DBTX
 	withTransaction func(func(*db.Queries) error) error
+	// shows warnings for changes that were saved but may not do what the user expects
+	warn func(err error)
+	// names of the games where characters only have one mod enabled
+	exclusiveGames pref.Preference[[]string]
+	// days deleted mods and textures are kept in the recycle bin
+	recycleRetention pref.Preference[int]
+	journal          journal
+	writes           appWrites
+	// the mod_search full text index exists, see initSearch
+	fts atomic.Bool
 	ModDao
 	TagDao
 	TextureDao
 	CharacterDao
+	CharacterAliasDao
 	IniCacheEntry
+	PasswordDao
+	QuarantineDao
+	SyncDao
+	SidecarDao
+	DependencyDao
+	MergeDao
+	RecycleDao
+	JournalDao
+	PersonalDao
+	UsageDao
+	SearchDao
+	PagingDao
+	DoctorDao
 }
 
 func BackupDatabase() error {
@@ -46,31 +87,43 @@ func BackupDatabase() error {
 	}
 	defer db.Close()
 
-	backupDir := filepath.Join(util.GetCacheDir(), "backup")
-	os.MkdirAll(backupDir, os.ModePerm)
-	backup, err := os.Create(filepath.Join(backupDir, backupFileName+".db"))
+	os.MkdirAll(GetBackupDir(), os.ModePerm)
+	backup, err := os.Create(filepath.Join(GetBackupDir(), backupFileName+".db"))
 	if err != nil {
 		return err
 	}
 	defer backup.Close()
 
-	_, err = io.Copy(db, backup)
+	_, err = io.Copy(backup, db)
 	return err
 }
 
+func GetBackupDir() string {
+	return filepath.Join(util.GetCacheDir(), "backup")
+}
+
 func InitDbAndRunMigrations(ctx context.Context, migrations embed.FS, ddl string) (*db.Queries, *sql.DB) {
-	dbfile := util.GetDbFile()
-	os.MkdirAll(filepath.Dir(dbfile), os.ModePerm)
+	queries, dbSql, err := OpenDb(ctx, util.GetDbFile(), migrations, ddl)
+	if err != nil {
+		panic(err)
+	}
+	return queries, dbSql
+}
+
+// OpenDb creates the tables and runs the migrations for the db file at path
+func OpenDb(ctx context.Context, path string, migrations fs.FS, ddl string) (*db.Queries, *sql.DB, error) {
+	os.MkdirAll(filepath.Dir(path), os.ModePerm)
 
-	util.CreateFileIfNotExists(dbfile)
+	util.CreateFileIfNotExists(path)
 
-	dbSql, err := sql.Open("sqlite3", dbfile)
+	dbSql, err := sql.Open("sqlite3", path)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 	// create tables
 	if _, err := dbSql.ExecContext(ctx, ddl); err != nil {
-		panic(err)
+		dbSql.Close()
+		return nil, nil, err
 	}
 
 	goose.SetBaseFS(migrations)
@@ -79,27 +132,67 @@ func InitDbAndRunMigrations(ctx context.Context, migrations embed.FS, ddl string
 		log.LogError(err.Error())
 	}
 
-	if err := goose.Up(dbSql, "db/migrations"); err != nil {
+	if err := runMigrations(dbSql); err != nil {
 		log.LogError(err.Error())
 	}
 
 	queries := db.New(dbSql)
 
-	return queries, dbSql
+	return queries, dbSql, nil
 }
 
-func NewDbHelper(queries *db.Queries, dbsql *sql.DB) *DbHelper {
+// databases created from the ddl already have the columns later migrations add.
+// those migrations fail with a duplicate column and are marked as applied so
+// the migrations after them still run
+func runMigrations(dbSql *sql.DB) error {
+	for {
+		err := goose.UpByOne(dbSql, "db/migrations")
+		switch {
+		case errors.Is(err, goose.ErrNoNextVersion):
+			return nil
+		case err == nil:
+			continue
+		case !strings.Contains(err.Error(), "duplicate column name"):
+			return err
+		}
+
+		current, verr := goose.GetDBVersion(dbSql)
+		if verr != nil {
+			return errors.Join(err, verr)
+		}
+		migrations, verr := goose.CollectMigrations("db/migrations", 0, goose.MaxVersion)
+		if verr != nil {
+			return errors.Join(err, verr)
+		}
+		next, verr := migrations.Next(current)
+		if verr != nil {
+			return errors.Join(err, verr)
+		}
 
-	ctx := context.Background()
+		log.LogPrintf("migration %d already applied by ddl: %s", next.Version, err.Error())
+		_, verr = dbSql.Exec(
+			fmt.Sprintf("INSERT INTO %s (version_id, is_applied) VALUES (?, ?)", goose.TableName()),
+			next.Version,
+			true,
+		)
+		if verr != nil {
+			return errors.Join(err, verr)
+		}
+	}
+}
 
-	withTransaction := func(transact func(qtx *db.Queries) error) error {
-		tx, err := dbsql.Begin()
+func NewDbHelper(dbsql *sql.DB) *DbHelper {
+	h := &DbHelper{ctx: context.Background(), conn: &dbConn{db: dbsql}}
+	h.queries = db.New(h.conn)
+	h.db = h.conn
+	h.withTransaction = func(transact func(qtx *db.Queries) error) error {
+		tx, err := h.conn.Begin()
 		if err != nil {
 			return err
 		}
 		defer tx.Rollback()
 
-		qtx := queries.WithTx(tx)
+		qtx := h.queries.WithTx(tx)
 
 		if err := transact(qtx); err != nil {
 			return err
@@ -107,17 +200,28 @@ func NewDbHelper(queries *db.Queries, dbsql *sql.DB) *DbHelper {
 
 		return tx.Commit()
 	}
+	h.connected(dbsql)
+	return h
+}
+
+func (h *DbHelper) SetWarnFn(warn func(err error)) {
+	h.warn = warn
+}
 
-	return &DbHelper{
-		ctx:             ctx,
-		queries:         queries,
-		db:              dbsql,
-		withTransaction: withTransaction,
+// state that belongs to a single db file, ids in the journal are only valid in one db
+func (h *DbHelper) connected(dbsql *sql.DB) {
+	h.clearJournal()
+	if err := h.initSearch(dbsql); err != nil {
+		log.LogError(err.Error())
 	}
 }
 
+// DeleteTextureById moves the texture dir and row into the recycle bin
 func (d *DbHelper) DeleteTextureById(textureId int) error {
-	return d.withTransaction(func(q *db.Queries) error {
+	var path, entryDir string
+	done := func() {}
+	defer func() { done() }()
+	err := d.withTransaction(func(q *db.Queries) error {
 		dbTexture, err := q.SelectTextureById(d.ctx, int64(textureId))
 		if err != nil {
 			log.LogError(err.Error())
@@ -132,38 +236,107 @@ func (d *DbHelper) DeleteTextureById(textureId int) error {
 		}
 		mod := modFromDb(dbMod)
 
-		path := filepath.Join(util.GetModDir(mod), "textures", texture.Filename)
-		if err = os.RemoveAll(path); err != nil {
-			log.LogError(err.Error())
+		if err = q.DeleteTextureById(d.ctx, int64(textureId)); err != nil {
 			return err
 		}
 
-		return q.DeleteTextureById(d.ctx, int64(textureId))
+		path = filepath.Join(util.GetModDir(mod), "textures", texture.Filename)
+		done = d.BeginWrite(path)
+		entryDir, err = recycle(recycledRows{
+			Entry: types.RecycleEntry{
+				Kind:      types.RECYCLE_TEXTURE,
+				Game:      mod.Game,
+				Character: mod.Character,
+				Mod:       mod.Filename,
+				Texture:   texture.Filename,
+			},
+			Textures: []db.Texture{dbTexture},
+		}, path)
+		return err
 	})
+	if err != nil {
+		unrecycle(entryDir, path)
+		return err
+	}
+
+	d.PurgeRecycleBin()
+	return nil
 }
 
+// DeleteModById moves the mod dir and the rows that belong to it into the recycle bin
 func (d *DbHelper) DeleteModById(modId int) error {
-	return d.withTransaction(func(q *db.Queries) error {
+	var path, entryDir string
+	done := func() {}
+	defer func() { done() }()
+	err := d.withTransaction(func(q *db.Queries) error {
 		dbMod, err := q.SelectModById(d.ctx, int64(modId))
 		if err != nil {
 			log.LogPrint(err.Error())
 			return err
 		}
-		mod := modFromDb(dbMod)
+		path = util.GetModDir(modFromDb(dbMod))
 
-		path := filepath.Join(util.GetCharacterDir(mod.Character, mod.Game), mod.Filename)
-		log.LogPrint(path)
-		if err = os.RemoveAll(path); err != nil {
+		rows, err := d.recycleMod(q, dbMod)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteArchivePasswordsByScope(d.ctx, db.DeleteArchivePasswordsByScopeParams{
+			Scope:   int64(PASSWORD_SCOPE_MOD),
+			ScopeId: int64(modId),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = q.DeleteQuarantinedFilesByModId(d.ctx, int64(modId)); err != nil {
 			return err
 		}
 
-		return q.DeleteModById(d.ctx, int64(modId))
+		if err = q.DeleteModDependenciesByModId(d.ctx, int64(modId)); err != nil {
+			return err
+		}
+
+		if err = q.DeleteTagsByModId(d.ctx, int64(modId)); err != nil {
+			return err
+		}
+
+		if err = q.DeleteModUsageByModId(d.ctx, int64(modId)); err != nil {
+			return err
+		}
+
+		if err = q.DeletePlaylistModCrossRefsByModId(d.ctx, int64(modId)); err != nil {
+			return err
+		}
+
+		if err = q.DeleteTexturesByModId(d.ctx, int64(modId)); err != nil {
+			return err
+		}
+
+		if err = q.DeleteModById(d.ctx, int64(modId)); err != nil {
+			return err
+		}
+
+		log.LogPrint(path)
+		done = d.BeginWrite(path)
+		entryDir, err = recycle(rows, path)
+		return err
 	})
+	if err != nil {
+		unrecycle(entryDir, path)
+		return err
+	}
+
+	d.PurgeRecycleBin()
+	return nil
 }
 
 func (h *DbHelper) DeleteCharacter(name string, id int64, game types.Game) error {
 
 	dir := util.GetCharacterDir(name, game)
+	if c, err := h.SelectCharacterById(int(id), game); err == nil {
+		dir = util.GetCharacterOrCategoryDir(c)
+	}
 
 	if err := os.RemoveAll(dir); err != nil {
 		return err
@@ -190,27 +363,70 @@ func (h *DbHelper) CreateCustomCharacter(name, img, element string, game types.G
 	)
 }
 
+// CreateCustomCategory adds a category that is not supplied by the data api
+func (h *DbHelper) CreateCustomCategory(name string, kind types.CategoryKind, game types.Game) error {
+	if kind == types.CATEGORY_NONE {
+		return errors.New("category kind was not set")
+	}
+
+	category := types.Character{
+		Id:       util.CategoryId(name),
+		Game:     game,
+		Name:     name,
+		Custom:   true,
+		Category: kind,
+	}
+	if err := os.MkdirAll(util.GetCharacterOrCategoryDir(category), os.ModePerm); err != nil {
+		return err
+	}
+
+	return h.UpsertCharacter(category)
+}
+
 func (h *DbHelper) RenameTexture(id int, name string) error {
 	texture, err := h.SelectTextureById(id)
 	if err != nil {
 		return err
 	}
-	mod, err := h.SelectModById(texture.ModId)
-	if err != nil {
+	if err := h.renameTexture(texture.ModId, texture.Filename, name); err != nil {
 		return err
 	}
+	h.record(
+		"Rename "+texture.Filename,
+		func() error { return h.renameTexture(texture.ModId, name, texture.Filename) },
+		func() error { return h.renameTexture(texture.ModId, texture.Filename, name) },
+	)
+	return nil
+}
 
-	currDir := filepath.Join(util.GetModDir(mod), "textures", texture.Filename)
-	newDir := filepath.Join(filepath.Dir(currDir), name)
-	err = os.Rename(currDir, newDir)
+// only the dir is renamed, the row is updated by sync when it picks up the renamed dir
+func (h *DbHelper) renameTexture(modId int, from, to string) error {
+	mod, err := h.SelectModById(modId)
 	if err != nil {
 		return err
 	}
 
-	return err
+	texturesDir := filepath.Join(util.GetModDir(mod), "textures")
+	return os.Rename(filepath.Join(texturesDir, from), filepath.Join(texturesDir, to))
 }
 
 func (h *DbHelper) RenameMod(id int64, name string) error {
+	mod, err := h.SelectModById(int(id))
+	if err != nil {
+		return err
+	}
+	if err := h.renameMod(id, name); err != nil {
+		return err
+	}
+	h.record(
+		"Rename "+mod.Filename,
+		func() error { return h.renameMod(id, mod.Filename) },
+		func() error { return h.renameMod(id, name) },
+	)
+	return nil
+}
+
+func (h *DbHelper) renameMod(id int64, name string) error {
 	dbmod, err := h.queries.SelectModById(h.ctx, id)
 	if err != nil {
 		return err
@@ -222,8 +438,75 @@ func (h *DbHelper) RenameMod(id int64, name string) error {
 	if err != nil {
 		return err
 	}
-	query := fmt.Sprintf("UPDATE mod SET fname = '%s' WHERE id = %d;", name, id)
-	log.LogDebug(query)
-	_, err = h.db.ExecContext(h.ctx, query)
+
+	err = h.queries.UpdateModFilename(h.ctx, db.UpdateModFilenameParams{
+		Fname: name,
+		ID:    id,
+	})
+	if err != nil {
+		os.Rename(newDir, currDir)
+	}
 	return err
 }
+
+// MoveMod moves the mod dir with its textures, keymaps and config to the character and
+// updates the row in place so tags and playlists keep pointing to it
+func (h *DbHelper) MoveMod(modId int, characterId int, game types.Game) error {
+	dbmod, err := h.queries.SelectModById(h.ctx, int64(modId))
+	if err != nil {
+		return err
+	}
+	mod := modFromDb(dbmod)
+
+	dbCharacter, err := h.queries.SelectCharacterById(h.ctx, db.SelectCharacterByIdParams{
+		ID:   int64(characterId),
+		Game: game.Int64(),
+	})
+	if err != nil {
+		return err
+	}
+	character := characterFromDb(dbCharacter)
+
+	currDir := util.GetModDir(mod)
+	newDir := filepath.Join(util.GetCharacterOrCategoryDir(character), mod.Filename)
+	if currDir == newDir {
+		return nil
+	}
+	if exists, _ := util.FileExists(newDir); exists {
+		return fmt.Errorf("%s already has a mod named %s", character.Name, mod.Filename)
+	}
+
+	defer h.BeginWrite(currDir, newDir)()
+
+	if err = os.MkdirAll(filepath.Dir(newDir), os.ModePerm); err != nil {
+		return err
+	}
+	if err = os.Rename(currDir, newDir); err != nil {
+		return err
+	}
+
+	err = h.withTransaction(func(q *db.Queries) error {
+		err := q.UpdateModCharacterAndGame(h.ctx, db.UpdateModCharacterAndGameParams{
+			CharName:     character.Name,
+			CharId:       int64(character.Id),
+			Game:         game.Int64(),
+			CategoryFlag: categoryFlag(character),
+			ID:           int64(modId),
+		})
+		if err != nil {
+			return err
+		}
+		// textures waiting for confirmation are confirmed per game
+		return q.UpdatePendingDeletesGameByModId(h.ctx, db.UpdatePendingDeletesGameByModIdParams{
+			Game:  game.Int64(),
+			ModId: int64(modId),
+		})
+	})
+	if err != nil {
+		os.Rename(newDir, currDir)
+		return err
+	}
+
+	h.writeModSidecars(modId)
+	return nil
+}
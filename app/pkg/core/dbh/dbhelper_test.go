@@ -0,0 +1,792 @@
+package dbh
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hmm/pkg/pref"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pressly/goose/v3"
+)
+
+func TestMigrationsAfterDdl(t *testing.T) {
+
+	ddl, err := os.ReadFile("../../../db/sql/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbSql, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "hmm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbSql.Close()
+
+	if _, err = dbSql.Exec(string(ddl)); err != nil {
+		t.Fatal(err)
+	}
+
+	goose.SetBaseFS(os.DirFS("../../../"))
+	defer goose.SetBaseFS(nil)
+	if err = goose.SetDialect(string(goose.DialectSQLite3)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = runMigrations(dbSql); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := goose.CollectMigrations("db/migrations", 0, goose.MaxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, _ := migrations.Last()
+
+	version, err := goose.GetDBVersion(dbSql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != last.Version {
+		t.Errorf("expected version %d got %d", last.Version, version)
+	}
+}
+
+func TestBackupAndRecreateDatabase(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	ddl, err := os.ReadFile("../../../db/sql/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations := os.DirFS("../../../")
+	defer goose.SetBaseFS(nil)
+
+	_, dbSql, err := OpenDb(context.Background(), util.GetDbFile(), migrations, string(ddl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewDbHelper(dbSql)
+	defer dbSql.Close()
+
+	if err = h.InsertPlaylistWithMods(types.Genshin, "favorites", []int{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = BackupDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := BackupFiles()
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup got %v", backups)
+	}
+
+	backup, close, err := OpenBackup(backups[0], migrations, string(ddl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+
+	if playlists, _ := backup.SelectPlaylists(); len(playlists) != 1 {
+		t.Errorf("expected the backup to contain the playlist got %v", playlists)
+	}
+
+	// queries from other goroutines wait for the switch instead of using the closed db
+	ctx, stop := context.WithCancel(context.Background())
+	queryErrs := make(chan error, 1)
+	go func() {
+		for ctx.Err() == nil {
+			if _, err := h.SelectPlaylists(); err != nil {
+				queryErrs <- err
+				return
+			}
+		}
+		queryErrs <- nil
+	}()
+
+	moved, err := h.RecreateDatabase(migrations, string(ddl))
+	stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-queryErrs; err != nil {
+		t.Errorf("query failed during the switch: %v", err)
+	}
+	if exists, _ := util.FileExists(moved); !exists {
+		t.Errorf("expected old db at %s", moved)
+	}
+	if playlists, _ := h.SelectPlaylists(); len(playlists) != 0 {
+		t.Errorf("expected an empty db got %v", playlists)
+	}
+
+	if err = h.InsertPlaylistWithMods(types.Genshin, "kept", []int{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.RecreateDatabase(migrations, "NOT SQL"); err == nil {
+		t.Fatal("expected a db that can not be created to fail")
+	}
+	if playlists, err := h.SelectPlaylists(); err != nil || len(playlists) != 1 {
+		t.Errorf("expected the old db to be kept got %v %v", playlists, err)
+	}
+}
+
+func TestMoveMod(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	for _, c := range []types.Character{
+		{Id: 1, Game: types.Genshin, Name: "Nahida"},
+		{Id: 2, Game: types.ZZZ, Name: "Ellen"},
+	} {
+		if err := h.UpsertCharacter(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mod := types.Mod{Filename: "mod", Game: types.Genshin, Character: "Nahida", CharacterId: 1}
+	os.MkdirAll(filepath.Join(util.GetModDir(mod), "keymaps"), os.ModePerm)
+
+	id, err := h.InsertMod(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.InsertTag("outfit", int(id))
+
+	if err = h.MoveMod(int(id), 2, types.ZZZ); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := h.SelectModById(int(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Character != "Ellen" || moved.CharacterId != 2 || moved.Game != types.ZZZ {
+		t.Errorf("row was not moved %+v", moved)
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetModDir(moved), "keymaps")); !exists {
+		t.Error("mod dir was not moved")
+	}
+	if tags, _ := h.SelectTagsByModId(id); len(tags) != 1 {
+		t.Errorf("expected tags to be kept got %v", tags)
+	}
+
+	if err = h.RenameMod(id, "it's renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if renamed, _ := h.SelectModById(int(id)); renamed.Filename != "it's renamed" {
+		t.Errorf("expected name with a quote to be saved got %s", renamed.Filename)
+	}
+}
+
+func TestExclusiveCharacterDisablesOtherMods(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	prefs := pref.NewPrefs(pref.NewInMemoryStore(context.Background()))
+	h.SetExclusiveGamesPref(prefs.GetStringSlice("exclusive_mod_games", []string{}))
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	ids := map[string]int{}
+	for _, name := range []string{"a", "b", "c"} {
+		mod := types.Mod{Filename: name, Game: types.Genshin, Character: "Nahida", CharacterId: 1, Enabled: true}
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = int(id)
+	}
+	// a mod the enabled mod depends on is kept
+	h.InsertModDependency(types.ModDependency{ModId: ids["a"], Kind: types.DEPENDENCY_MOD, DependsOn: ids["c"]})
+
+	if disabled, _ := h.UpdateModEnabledById(true, ids["a"]); len(disabled) != 0 {
+		t.Errorf("expected no mods to be disabled without the policy got %+v", disabled)
+	}
+
+	h.UpdateCharacterExclusive(1, types.Genshin, types.EXCLUSIVE_ON)
+	disabled, err := h.UpdateModEnabledById(true, ids["a"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disabled) != 1 || disabled[0].Id != ids["b"] {
+		t.Fatalf("expected b to be disabled got %+v", disabled)
+	}
+	if b, _ := h.SelectModById(ids["b"]); b.Enabled {
+		t.Error("b is still enabled")
+	}
+
+	// the character overrides the game setting
+	prefs.GetStringSlice("exclusive_mod_games", []string{}).Set([]string{types.Genshin.Name()})
+	h.UpdateCharacterExclusive(1, types.Genshin, types.EXCLUSIVE_OFF)
+	if disabled, _ := h.UpdateModEnabledById(true, ids["b"]); len(disabled) != 0 {
+		t.Errorf("expected no mods to be disabled got %+v", disabled)
+	}
+}
+
+func TestRecycleAndRestoreMod(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	mod := types.Mod{Filename: "mod", Game: types.Genshin, Character: "Nahida", CharacterId: 1, GbId: 5}
+	os.MkdirAll(filepath.Join(util.GetModDir(mod), "textures", "recolor"), os.ModePerm)
+	id, err := h.InsertMod(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	textureId, _ := h.InsertTexture(types.Texture{Filename: "recolor", ModId: int(id)})
+	h.InsertTag("outfit", int(id))
+	h.InsertPlaylistWithMods(types.Genshin, "favorites", []int{int(id)})
+
+	if err = h.DeleteModById(int(id)); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := util.FileExists(util.GetModDir(mod)); exists {
+		t.Error("mod dir was not moved to the recycle bin")
+	}
+	if textures, _ := h.SelectTexturesByModId(int(id)); len(textures) != 0 {
+		t.Errorf("expected the textures to be deleted got %v", textures)
+	}
+
+	entries, err := h.SelectRecycleBin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Kind != types.RECYCLE_MOD || entries[0].Mod != "mod" {
+		t.Fatalf("unexpected recycle bin %+v", entries)
+	}
+
+	if err = h.RestoreRecycleEntry(entries[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := h.SelectModById(int(id))
+	if err != nil || restored.GbId != 5 {
+		t.Fatalf("expected the mod to be restored with its id got %+v %v", restored, err)
+	}
+	if texture, err := h.SelectTextureById(int(textureId)); err != nil || texture.ModId != int(id) {
+		t.Errorf("expected the texture to be restored got %+v %v", texture, err)
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetModDir(restored), "textures", "recolor")); !exists {
+		t.Error("mod dir was not restored")
+	}
+	if tags, _ := h.SelectTagsByModId(id); len(tags) != 1 {
+		t.Errorf("expected the tag to be restored got %v", tags)
+	}
+	if playlists, _ := h.SelectPlaylistWithModsAndTags(types.Genshin); len(playlists) != 1 || len(playlists[0].ModsWithTags) != 1 {
+		t.Errorf("expected the playlist to be restored got %+v", playlists)
+	}
+	if entries, _ := h.SelectRecycleBin(); len(entries) != 0 {
+		t.Errorf("expected the entry to be removed got %+v", entries)
+	}
+
+	// a texture is restored into its mod
+	if err = h.DeleteTextureById(int(textureId)); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = h.SelectRecycleBin()
+	if len(entries) != 1 || entries[0].Kind != types.RECYCLE_TEXTURE {
+		t.Fatalf("unexpected recycle bin %+v", entries)
+	}
+	if err = h.RestoreRecycleEntry(entries[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.SelectTextureById(int(textureId)); err != nil {
+		t.Error(err)
+	}
+
+	h.DeleteModById(int(id))
+	if err = h.EmptyRecycleBin(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := h.SelectRecycleBin(); len(entries) != 0 {
+		t.Errorf("expected an empty recycle bin got %+v", entries)
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	ids := []int{}
+	for _, name := range []string{"a", "b"} {
+		mod := types.Mod{Filename: name, Game: types.Genshin, Character: "Nahida", CharacterId: 1, Enabled: true}
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, int(id))
+	}
+	h.InsertPlaylistWithMods(types.Genshin, "favorites", ids)
+
+	if _, err := h.Undo(); err != ErrNothingToUndo {
+		t.Errorf("expected nothing to undo got %v", err)
+	}
+
+	h.UpdateDisableAllModsByGame(types.Genshin)
+	h.InsertTag("outfit", ids[0])
+	h.RenameMod(int64(ids[1]), "renamed")
+	playlists, _ := h.SelectPlaylists()
+	h.DeletePlaylistById(int64(playlists[0].Id))
+
+	if j := h.SelectJournal(); len(j.Undo) != 4 || j.Undo[0] != "Delete playlist favorites" {
+		t.Fatalf("unexpected journal %+v", j)
+	}
+
+	for range 4 {
+		if _, err := h.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if enabled, _ := h.SelectEnabledModsByGame(types.Genshin); len(enabled) != 2 {
+		t.Errorf("expected the mods to be enabled again got %+v", enabled)
+	}
+	if tags, _ := h.SelectTagsByModId(int64(ids[0])); len(tags) != 0 {
+		t.Errorf("expected the tag to be removed got %v", tags)
+	}
+	if mod, _ := h.SelectModById(ids[1]); mod.Filename != "b" {
+		t.Errorf("expected the rename to be undone got %s", mod.Filename)
+	}
+	if playlists, _ := h.SelectPlaylistWithModsAndTags(types.Genshin); len(playlists) != 1 || len(playlists[0].ModsWithTags) != 2 {
+		t.Errorf("expected the playlist to be restored got %+v", playlists)
+	}
+
+	if name, err := h.Redo(); err != nil || name != "Disable all mods" {
+		t.Fatalf("expected disable all mods to be redone got %s %v", name, err)
+	}
+	if enabled, _ := h.SelectEnabledModsByGame(types.Genshin); len(enabled) != 0 {
+		t.Errorf("expected the mods to be disabled again got %+v", enabled)
+	}
+
+	// a new operation drops the operations that were undone
+	h.UpdateModEnabledById(true, ids[0])
+	if _, err := h.Redo(); err != ErrNothingToRedo {
+		t.Errorf("expected nothing to redo got %v", err)
+	}
+
+	// an operation that can not be reverted is dropped instead of blocking the journal
+	h.record("broken", func() error { return os.ErrNotExist }, func() error { return nil })
+	if name, err := h.Undo(); err == nil || name != "broken" {
+		t.Errorf("expected the broken operation to fail got %s %v", name, err)
+	}
+	if name, err := h.Undo(); err != nil || name != "Enable a" {
+		t.Errorf("expected the operation below to be undone got %s %v", name, err)
+	}
+}
+
+func TestFavoritesRatingsAndNotes(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	ids := map[string]int{}
+	for _, name := range []string{"a", "b", "c"} {
+		mod := types.Mod{Filename: name, Game: types.Genshin, Character: "Nahida", CharacterId: 1}
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = int(id)
+	}
+
+	if err := h.UpdateModRating(ids["a"], 6); err != ErrInvalidRating {
+		t.Errorf("expected an invalid rating error got %v", err)
+	}
+	h.UpdateModRating(ids["a"], 2)
+	h.UpdateModRating(ids["b"], 5)
+	h.UpdateModFavorite(ids["c"], true)
+	h.UpdateModNotes(ids["c"], "toggle with numpad 5")
+
+	mod, _ := h.SelectModById(ids["c"])
+	if !mod.Favorite || mod.Notes != "toggle with numpad 5" {
+		t.Errorf("favorite and notes were not saved %+v", mod)
+	}
+	if sidecar, _ := ReadModSidecar(util.GetModDir(mod)); !sidecar.Favorite || sidecar.Notes != mod.Notes {
+		t.Errorf("sidecar does not match row %+v", sidecar)
+	}
+
+	found, _ := h.SelectCharacterWithModsTagsAndTextures(types.Genshin, "numpad", "", "")
+	if len(found) != 1 || len(found[0].ModWithTags) != 1 || found[0].ModWithTags[0].Mod.Id != ids["c"] {
+		t.Errorf("expected notes to be searchable got %+v", found)
+	}
+
+	order := func(sort types.ModSort) []string {
+		characters, _ := h.SelectSortedCharacterMods(types.Genshin, "", "", "", sort)
+		names := []string{}
+		for _, m := range characters[0].ModWithTags {
+			names = append(names, m.Mod.Filename)
+		}
+		return names
+	}
+	if o := order(types.SORT_MOD_RATING); !slices.Equal(o, []string{"b", "a", "c"}) {
+		t.Errorf("unexpected rating order %v", o)
+	}
+	if o := order(types.SORT_MOD_FAVORITE); !slices.Equal(o, []string{"c", "a", "b"}) {
+		t.Errorf("unexpected favorite order %v", o)
+	}
+}
+
+func TestModUsage(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+	h.UpsertCharacter(types.Character{Id: 2, Game: types.Genshin, Name: "Furina"})
+
+	ids := map[string]int{}
+	for _, mod := range []types.Mod{
+		{Filename: "a", Character: "Nahida", CharacterId: 1},
+		{Filename: "b", Character: "Nahida", CharacterId: 1},
+		{Filename: "c", Character: "Furina", CharacterId: 2},
+	} {
+		mod.Game = types.Genshin
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[mod.Filename] = int(id)
+	}
+	h.InsertTag("outfit", ids["b"])
+
+	if err := h.RecordGeneration(types.Genshin, []int{ids["a"], ids["b"]}, []int{ids["a"]}); err != nil {
+		t.Fatal(err)
+	}
+	// the first set stayed exported for an hour
+	if _, err := h.db.ExecContext(h.ctx, "UPDATE mod_usage SET active_since = active_since - 3600"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RecordGeneration(types.Genshin, []int{ids["a"]}, []int{ids["a"]}); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := func(filter types.UsageFilter) map[string]types.ModUsage {
+		filter.Game = types.Genshin
+		rows, err := h.SelectModUsage(filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]types.ModUsage{}
+		for _, u := range rows {
+			m[u.Mod.Filename] = u
+		}
+		return m
+	}
+
+	all := usage(types.UsageFilter{})
+	if all["a"].GenerateCount != 2 || all["b"].GenerateCount != 1 || all["c"].GenerateCount != 0 {
+		t.Errorf("unexpected generate counts %+v", all)
+	}
+	if all["b"].EnabledSeconds < 3600 || all["b"].LastExportedAt != 0 || all["a"].LastExportedAt == 0 {
+		t.Errorf("unexpected enabled time or export time %+v", all)
+	}
+
+	if recent := usage(types.UsageFilter{View: types.USAGE_RECENT}); len(recent) != 1 || recent["a"].Mod.Id == 0 {
+		t.Errorf("expected only a to be recent got %v", recent)
+	}
+	if never := usage(types.UsageFilter{View: types.USAGE_NEVER}); len(never) != 1 || never["c"].Mod.Id == 0 {
+		t.Errorf("expected only c to be never used got %v", never)
+	}
+	if stale := usage(types.UsageFilter{View: types.USAGE_STALE}); len(stale) != 0 {
+		t.Errorf("recently added mods should not be stale got %v", stale)
+	}
+	if nahida := usage(types.UsageFilter{CharacterId: 1}); len(nahida) != 2 {
+		t.Errorf("expected the mods of Nahida got %v", nahida)
+	}
+	if tagged := usage(types.UsageFilter{Tag: "outfit"}); len(tagged) != 1 || tagged["b"].Mod.Id == 0 {
+		t.Errorf("expected the tagged mod got %v", tagged)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+	h.UpsertCharacter(types.Character{Id: 2, Game: types.Genshin, Name: "Furina"})
+
+	ids := map[string]int{}
+	for _, mod := range []types.Mod{
+		{Filename: "nahida_dress", Character: "Nahida", CharacterId: 1},
+		{Filename: "swim", Character: "Furina", CharacterId: 2},
+		{Filename: "other", Character: "Furina", CharacterId: 2},
+	} {
+		mod.Game = types.Genshin
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[mod.Filename] = int(id)
+	}
+	h.InsertTag("outfit", ids["nahida_dress"])
+	h.InsertTag("outfit", ids["swim"])
+	h.InsertTexture(types.Texture{Filename: "red", ModId: ids["swim"]})
+	h.UpdateModGbMetadata(ids["swim"], types.Mod{GbName: "Furina Swimsuit"})
+	h.UpdateModNotes(ids["other"], "mentions nahida")
+
+	search := func(query string) []string {
+		results, err := h.Search(types.Genshin, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, r := range results {
+			names = append(names, r.ModWithTags.Mod.Filename)
+		}
+		return names
+	}
+
+	if found := search("swimsu*"); !slices.Equal(found, []string{"swim"}) {
+		t.Errorf("expected a prefix match on the GameBanana name got %v", found)
+	}
+	if found := search("outfit NOT red"); !slices.Equal(found, []string{"nahida_dress"}) {
+		t.Errorf("expected the texture to exclude swim got %v", found)
+	}
+	if found := search("nahida"); len(found) != 2 || (h.fts.Load() && found[0] != "nahida_dress") {
+		t.Errorf("expected the filename match first got %v", found)
+	}
+	if found := search(`"unterminated`); len(found) != 0 {
+		t.Errorf("expected no results got %v", found)
+	}
+
+	h.DeleteTag("outfit", ids["nahida_dress"])
+	h.DeleteTag("outfit", ids["swim"])
+	if found := search("outfit"); len(found) != 0 {
+		t.Errorf("deleted tags are still indexed %v", found)
+	}
+}
+
+func TestSelectModPage(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida", Element: "Dendro"})
+	h.UpsertCharacter(types.Character{Id: 2, Game: types.Genshin, Name: "Furina", Element: "Hydro"})
+
+	names := []string{}
+	for i := range 7 {
+		mod := types.Mod{Filename: fmt.Sprintf("mod%d", i), Game: types.Genshin, Character: "Nahida", CharacterId: 1, Enabled: i%2 == 0}
+		if i >= 5 {
+			mod.Character, mod.CharacterId = "Furina", 2
+		}
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.UpdateModRating(int(id), i%3)
+		if i == 1 {
+			h.InsertTexture(types.Texture{Filename: "red", ModId: int(id)})
+			h.InsertTag("outfit", int(id))
+		}
+		names = append(names, mod.Filename)
+	}
+
+	pages := func(filter types.ModPageFilter, limit int) []string {
+		filter.Game = types.Genshin
+		found := []string{}
+		cursor := ""
+		for {
+			page, err := h.SelectModPage(filter, cursor, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range page.Mods {
+				found = append(found, m.Mod.Filename)
+			}
+			if page.NextCursor == "" {
+				return found
+			}
+			if len(page.Mods) != limit {
+				t.Fatalf("expected full pages before the last got %d", len(page.Mods))
+			}
+			cursor = page.NextCursor
+		}
+	}
+
+	if found := pages(types.ModPageFilter{}, 3); !slices.Equal(found, names) {
+		t.Errorf("expected every mod in name order got %v", found)
+	}
+	// ratings 0 1 2 0 1 2 0, ties are in id order
+	if found := pages(types.ModPageFilter{Sort: types.SORT_MOD_RATING}, 2); !slices.Equal(found, []string{"mod2", "mod5", "mod1", "mod4", "mod0", "mod3", "mod6"}) {
+		t.Errorf("unexpected rating order %v", found)
+	}
+	enabled := true
+	if found := pages(types.ModPageFilter{Element: "dendro", Enabled: &enabled}, 2); !slices.Equal(found, []string{"mod0", "mod2", "mod4"}) {
+		t.Errorf("expected enabled dendro mods got %v", found)
+	}
+	hasTextures := true
+	if found := pages(types.ModPageFilter{Tag: "outfit", HasTextures: &hasTextures}, 2); !slices.Equal(found, []string{"mod1"}) {
+		t.Errorf("expected the tagged mod with textures got %v", found)
+	}
+
+	page, _ := h.SelectModPage(types.ModPageFilter{Game: types.Genshin, Tag: "outfit"}, "", 10)
+	if len(page.Mods) != 1 || len(page.Mods[0].Textures) != 1 || len(page.Mods[0].Tags) != 1 || len(page.Characters) != 1 {
+		t.Errorf("expected the tags, textures and character of the mod got %+v", page)
+	}
+
+	if _, err := h.SelectModPage(types.ModPageFilter{}, "not a cursor", 10); err != ErrInvalidCursor {
+		t.Errorf("expected an invalid cursor error got %v", err)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	h.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	mod := types.Mod{Filename: "mod", Game: types.Genshin, Character: "Nahida", CharacterId: 1}
+	os.MkdirAll(filepath.Join(util.GetModDir(mod), "root"), os.ModePerm)
+	os.WriteFile(filepath.Join(util.GetModDir(mod), "root", "mod.ini"), []byte("[Constants]"), 0666)
+	id, err := h.InsertMod(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphanId, err := h.InsertMod(types.Mod{Filename: "orphan", Game: types.Genshin, Character: "Removed", CharacterId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.InsertTexture(types.Texture{Filename: "texture", ModId: int(id)}); err != nil {
+		t.Fatal(err)
+	}
+	h.ReplaceIniEntries(int(id), "", []string{"renamed.ini"})
+	h.InsertTag("outfit", 100)
+	if err := h.InsertPlaylistWithMods(types.Genshin, "playlist", []int{int(id), 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := h.Doctor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := []types.DoctorIssueKind{}
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	expected := []types.DoctorIssueKind{
+		types.DOCTOR_FOREIGN_KEY,
+		types.DOCTOR_MISSING_TEXTURE_DIR,
+		types.DOCTOR_MISSING_CHARACTER,
+		types.DOCTOR_STALE_INI_CACHE,
+		types.DOCTOR_PLAYLIST_MISSING_MOD,
+	}
+	if !slices.Equal(kinds, expected) {
+		t.Fatalf("expected one issue of each kind got %+v", issues)
+	}
+	if issues[0].Table != "tag" || issues[2].ModId != int(orphanId) || issues[4].ModId != 100 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+
+	for _, issue := range issues {
+		if err := h.FixDoctorIssue(issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if issues, _ = h.Doctor(); len(issues) != 0 {
+		t.Errorf("expected every issue to be fixed got %+v", issues)
+	}
+
+	if character, err := h.SelectCharacterById(2, types.Genshin); err != nil || character.Name != "Removed" || !character.Custom {
+		t.Errorf("expected the character of the orphan to be added got %+v %v", character, err)
+	}
+	playlists, _ := h.SelectPlaylistWithModsAndTags(types.Genshin)
+	if len(playlists) != 1 || len(playlists[0].ModsWithTags) != 1 {
+		t.Errorf("expected the existing mod to stay in the playlist got %+v", playlists)
+	}
+
+	if err := h.FixDoctorIssue(types.DoctorIssue{Kind: types.DOCTOR_FOREIGN_KEY, Table: "mod"}); err == nil {
+		t.Error("expected rows of tables that are not checked to be refused")
+	}
+}
+
+func TestSelectClosestCharacter(t *testing.T) {
+	h := newTestDbHelper(t)
+
+	for _, c := range []types.Character{
+		{Id: 1, Game: types.Genshin, Name: "Raiden Shogun"},
+		{Id: 2, Game: types.Genshin, Name: "Raiden Makoto"},
+		{Id: 3, Game: types.Genshin, Name: "Kaedehara Kazuha"},
+		{Id: 4, Game: types.Genshin, Name: "Neuvillette"},
+		{Id: 5, Game: types.Genshin, Name: "Hu Tao"},
+	} {
+		if err := h.UpsertCharacter(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := h.SeedCharacterAliases(types.Genshin, map[string][]string{
+		"Raiden Shogun":    {"Raiden", "Ei", "雷电将军"},
+		"Kaedehara Kazuha": {"Kazuha"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]int{
+		"Raiden":                1,
+		"ei":                    1,
+		"RaidenShogun":          1,
+		"raidenshogun_bikini":   1,
+		"雷电将军":                  1,
+		"Makoto":                2,
+		"Kazuah":                3,
+		"Kaedehara Kazuha Swim": 3,
+		"Neuvilette":            4,
+		"HuTao":                 5,
+	}
+	for name, id := range cases {
+		if c, err := h.SelectClosestCharacter(name, types.Genshin); err != nil || c.Id != id {
+			t.Errorf("%s: expected character %d got %+v %v", name, id, c, err)
+		}
+	}
+	for _, name := range []string{"Nahida", "Tao", "Shogun Makoto"} {
+		if c, err := h.SelectClosestCharacter(name, types.Genshin); err == nil {
+			t.Errorf("%s: expected no match got %s", name, c.Name)
+		}
+	}
+
+	if err := h.DeleteCharacterAlias(types.Genshin, "Ei"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddCharacterAlias(types.Genshin, 4, "Neuv"); err != nil {
+		t.Fatal(err)
+	}
+	h.SeedCharacterAliases(types.Genshin, map[string][]string{"Raiden Shogun": {"Ei"}})
+	if _, err := h.SelectClosestCharacter("Ei", types.Genshin); err == nil {
+		t.Error("a deleted bundled alias was seeded again")
+	}
+	if c, _ := h.SelectClosestCharacter("neuv", types.Genshin); c.Id != 4 {
+		t.Errorf("expected the user alias to match got %+v", c)
+	}
+	if c, _ := h.SelectCharacterForGbCategory(types.Genshin, "Weapons"); c.Category != types.CATEGORY_WEAPON {
+		t.Errorf("expected the weapon category got %+v", c)
+	}
+}
@@ -0,0 +1,310 @@
+package dbh
+
+import (
+	"fmt"
+	"hmm/db"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"slices"
+	"strings"
+)
+
+type DependencyDao interface {
+	InsertModDependency(dep types.ModDependency) error
+	DeleteModDependency(dep types.ModDependency) error
+	SelectModDependencies(modId int) ([]types.ModDependency, error)
+	ReplaceModDependencies(modId int, source types.DependencySource, deps []types.ModDependency) error
+	ReplaceIniDependencies(deps map[int][]types.ModDependency) error
+	SelectModNamespaces(game types.Game) (map[int]ModNamespaces, error)
+	SaveModNamespaces(namespaces []ModNamespaces) error
+	SelectModsNeeding(modId int) ([]types.Mod, error)
+}
+
+var _ DependencyDao = (*DbHelper)(nil)
+
+func dependencyFromDb(d db.ModDependency) types.ModDependency {
+	return types.ModDependency{
+		ModId:     int(d.ModID),
+		Kind:      types.DependencyKind(d.Kind),
+		DependsOn: int(d.DepID),
+		Source:    types.DependencySource(d.Source),
+	}
+}
+
+func (h *DbHelper) InsertModDependency(dep types.ModDependency) error {
+	if dep.Kind == types.DEPENDENCY_MOD && dep.ModId == dep.DependsOn {
+		return fmt.Errorf("mod %d can not depend on itself", dep.ModId)
+	}
+
+	err := h.queries.InsertModDependency(h.ctx, db.InsertModDependencyParams{
+		ModId:  int64(dep.ModId),
+		Kind:   int64(dep.Kind),
+		DepId:  int64(dep.DependsOn),
+		Source: int64(dep.Source),
+	})
+	if err != nil {
+		return err
+	}
+
+	h.writeModSidecars(dep.ModId)
+	return nil
+}
+
+func (h *DbHelper) DeleteModDependency(dep types.ModDependency) error {
+	err := h.queries.DeleteModDependency(h.ctx, db.DeleteModDependencyParams{
+		ModId: int64(dep.ModId),
+		Kind:  int64(dep.Kind),
+		DepId: int64(dep.DependsOn),
+	})
+	if err != nil {
+		return err
+	}
+
+	h.writeModSidecars(dep.ModId)
+	return nil
+}
+
+func (h *DbHelper) SelectModDependencies(modId int) ([]types.ModDependency, error) {
+	deps, err := h.queries.SelectModDependencies(h.ctx, int64(modId))
+	if err != nil {
+		return make([]types.ModDependency, 0), err
+	}
+
+	result := make([]types.ModDependency, 0, len(deps))
+	for _, d := range deps {
+		result = append(result, dependencyFromDb(d))
+	}
+	return result, nil
+}
+
+// ReplaceModDependencies replaces the dependencies of the mod that came from source,
+// used when the dependencies detected from the ini files changed
+func (h *DbHelper) ReplaceModDependencies(modId int, source types.DependencySource, deps []types.ModDependency) error {
+	err := h.withTransaction(func(q *db.Queries) error {
+		return h.replaceDependencies(q, modId, source, deps)
+	})
+	if err != nil {
+		return err
+	}
+
+	h.writeModSidecars(modId)
+	return nil
+}
+
+// ReplaceIniDependencies replaces the detected dependencies of every mod in deps in one
+// transaction. sidecars leave them out so they are not written
+func (h *DbHelper) ReplaceIniDependencies(deps map[int][]types.ModDependency) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		for modId, modDeps := range deps {
+			if err := h.replaceDependencies(q, modId, types.DEPENDENCY_SOURCE_INI, modDeps); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// namespaces the ini files of a mod declare and reference, cached until the
+// fingerprint of the mod changes so sync does not read every ini again
+type ModNamespaces struct {
+	ModId       int
+	Fingerprint string
+	Declared    []string
+	Referenced  []string
+}
+
+func splitNamespaces(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+// SelectModNamespaces returns the cached namespaces of the mods of the game keyed by mod id
+func (h *DbHelper) SelectModNamespaces(game types.Game) (map[int]ModNamespaces, error) {
+	rows, err := h.queries.SelectModNamespacesByGame(h.ctx, game.Int64())
+	if err != nil {
+		return map[int]ModNamespaces{}, err
+	}
+
+	namespaces := make(map[int]ModNamespaces, len(rows))
+	for _, row := range rows {
+		namespaces[int(row.ModID)] = ModNamespaces{
+			ModId:       int(row.ModID),
+			Fingerprint: row.Fingerprint,
+			Declared:    splitNamespaces(row.Declared),
+			Referenced:  splitNamespaces(row.Referenced),
+		}
+	}
+	return namespaces, nil
+}
+
+func (h *DbHelper) SaveModNamespaces(namespaces []ModNamespaces) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	return h.withTransaction(func(q *db.Queries) error {
+		for _, ns := range namespaces {
+			err := q.UpsertModNamespace(h.ctx, db.UpsertModNamespaceParams{
+				ModId:       int64(ns.ModId),
+				Fingerprint: ns.Fingerprint,
+				Declared:    strings.Join(ns.Declared, "\n"),
+				Referenced:  strings.Join(ns.Referenced, "\n"),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (h *DbHelper) replaceDependencies(q *db.Queries, modId int, source types.DependencySource, deps []types.ModDependency) error {
+	err := q.DeleteModDependenciesBySource(h.ctx, db.DeleteModDependenciesBySourceParams{
+		ModId:  int64(modId),
+		Source: int64(source),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range deps {
+		if dep.Kind == types.DEPENDENCY_MOD && dep.DependsOn == modId {
+			continue
+		}
+		err := q.InsertModDependency(h.ctx, db.InsertModDependencyParams{
+			ModId:  int64(modId),
+			Kind:   int64(dep.Kind),
+			DepId:  int64(dep.DependsOn),
+			Source: int64(source),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type dependencyGraph struct {
+	mods       map[int]types.Mod
+	categories map[int][]types.Mod
+	deps       map[int][]types.ModDependency
+}
+
+func (h *DbHelper) selectDependencyGraph(game types.Game) (dependencyGraph, error) {
+	graph := dependencyGraph{
+		mods:       map[int]types.Mod{},
+		categories: map[int][]types.Mod{},
+		deps:       map[int][]types.ModDependency{},
+	}
+
+	deps, err := h.queries.SelectModDependenciesByGame(h.ctx, game.Int64())
+	if err != nil || len(deps) == 0 {
+		return graph, err
+	}
+	for _, d := range deps {
+		dep := dependencyFromDb(d)
+		graph.deps[dep.ModId] = append(graph.deps[dep.ModId], dep)
+	}
+
+	mods, err := h.SelectModsByGame(game)
+	if err != nil {
+		return graph, err
+	}
+	for _, m := range mods {
+		graph.mods[m.Id] = m
+		if m.Category {
+			graph.categories[m.CharacterId] = append(graph.categories[m.CharacterId], m)
+		}
+	}
+	return graph, nil
+}
+
+// adds the mods the enabled mods depend on, dependencies of dependencies are included
+func (g dependencyGraph) resolve(enabled []types.Mod) []types.Mod {
+	result := slices.Clone(enabled)
+	included := map[int]bool{}
+	queue := []int{}
+	for _, m := range enabled {
+		included[m.Id] = true
+		queue = append(queue, m.Id)
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range g.deps[id] {
+			needed := g.categories[dep.DependsOn]
+			if dep.Kind == types.DEPENDENCY_MOD {
+				needed = []types.Mod{}
+				if m, ok := g.mods[dep.DependsOn]; ok {
+					needed = append(needed, m)
+				}
+			}
+
+			for _, m := range needed {
+				if included[m.Id] {
+					continue
+				}
+				included[m.Id] = true
+				result = append(result, m)
+				queue = append(queue, m.Id)
+			}
+		}
+	}
+
+	return result
+}
+
+// SelectModsNeeding returns the enabled mods that still export the mod as a dependency
+func (h *DbHelper) SelectModsNeeding(modId int) ([]types.Mod, error) {
+	mod, err := h.SelectModById(modId)
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+
+	enabled, err := h.selectEnabledMods(mod.Game)
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+	graph, err := h.selectDependencyGraph(mod.Game)
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+
+	needing := []types.Mod{}
+	for _, m := range enabled {
+		if m.Id == modId {
+			continue
+		}
+		exported := graph.resolve([]types.Mod{m})
+		if slices.ContainsFunc(exported, func(e types.Mod) bool { return e.Id == modId }) {
+			needing = append(needing, m)
+		}
+	}
+	return needing, nil
+}
+
+// warns when a disabled mod will still be exported because enabled mods need it
+func (h *DbHelper) warnIfNeeded(modId int) {
+	if h.warn == nil {
+		return
+	}
+
+	needing, err := h.SelectModsNeeding(modId)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+	if len(needing) == 0 {
+		return
+	}
+
+	mod, _ := h.SelectModById(modId)
+	names := make([]string, len(needing))
+	for i, m := range needing {
+		names[i] = m.Filename
+	}
+	h.warn(fmt.Errorf("%s is still needed by %s and will be exported with them", mod.Filename, strings.Join(names, ", ")))
+}
@@ -0,0 +1,252 @@
+package dbh
+
+import (
+	"errors"
+	"fmt"
+	"hmm/db"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// tables checked with PRAGMA foreign_key_check. the key of mod points at character(id)
+// which is not unique without the game so sqlite can not check it, see missingCharacterIssues
+var doctorForeignKeyTables = []string{
+	"texture",
+	"tag",
+	"inicache",
+	"quarantine",
+	"mod_dependency",
+	"mod_namespace",
+	"mod_usage",
+	"playlist_mod_cross_ref",
+	"character_alias",
+}
+
+type DoctorDao interface {
+	Doctor() ([]types.DoctorIssue, error)
+	FixDoctorIssue(issue types.DoctorIssue) error
+}
+
+var _ DoctorDao = (*DbHelper)(nil)
+
+// Doctor checks the rows for references to rows or files that do not exist anymore,
+// nothing is changed until an issue is passed to FixDoctorIssue
+func (h *DbHelper) Doctor() ([]types.DoctorIssue, error) {
+	issues := []types.DoctorIssue{}
+
+	for _, check := range []func() ([]types.DoctorIssue, error){
+		h.foreignKeyIssues,
+		h.missingTextureDirIssues,
+		h.missingCharacterIssues,
+		h.staleIniCacheIssues,
+		h.playlistMissingModIssues,
+	} {
+		found, err := check()
+		if err != nil {
+			return issues, err
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
+
+// FixDoctorIssue removes the broken row, mods without a character get a custom one
+// with the name and id they point at so they keep their dir and data
+func (h *DbHelper) FixDoctorIssue(issue types.DoctorIssue) error {
+	switch issue.Kind {
+	case types.DOCTOR_FOREIGN_KEY:
+		if !slices.Contains(doctorForeignKeyTables, issue.Table) {
+			return fmt.Errorf("can not fix rows of table %s", issue.Table)
+		}
+		_, err := h.db.ExecContext(h.ctx, "DELETE FROM "+issue.Table+" WHERE rowid = ?", issue.RowId)
+		return err
+	case types.DOCTOR_MISSING_TEXTURE_DIR:
+		if err := h.DeleteTexturesByIds([]int{issue.TextureId}); err != nil {
+			return err
+		}
+		return h.DeletePendingDelete(PENDING_DELETE_TEXTURE, issue.TextureId)
+	case types.DOCTOR_MISSING_CHARACTER:
+		mod, err := h.SelectModById(issue.ModId)
+		if err != nil {
+			return err
+		}
+		character := types.Character{
+			Id:     mod.CharacterId,
+			Game:   mod.Game,
+			Name:   mod.Character,
+			Custom: true,
+		}
+		if mod.Category {
+			character.Category = types.CATEGORY_OTHER
+		}
+		return h.UpsertCharacter(character)
+	case types.DOCTOR_STALE_INI_CACHE:
+		return h.queries.DeleteIniCacheByModId(h.ctx, int64(issue.ModId))
+	case types.DOCTOR_PLAYLIST_MISSING_MOD:
+		return h.queries.DeletePlaylistModCrossRef(h.ctx, db.DeletePlaylistModCrossRefParams{
+			PlaylistId: int64(issue.PlaylistId),
+			ModId:      int64(issue.ModId),
+		})
+	}
+	return fmt.Errorf("unknown issue kind %d", issue.Kind)
+}
+
+func (h *DbHelper) foreignKeyIssues() ([]types.DoctorIssue, error) {
+	issues := []types.DoctorIssue{}
+
+	for _, table := range doctorForeignKeyTables {
+		rows, err := h.db.QueryContext(h.ctx, fmt.Sprintf("PRAGMA foreign_key_check(%s)", table))
+		if err != nil {
+			return issues, err
+		}
+
+		for rows.Next() {
+			var child, parent string
+			var rowId, fkId int
+			if err := rows.Scan(&child, &rowId, &parent, &fkId); err != nil {
+				rows.Close()
+				return issues, err
+			}
+			// reported with the playlist name by playlistMissingModIssues
+			if child == "playlist_mod_cross_ref" && parent == "mod" {
+				continue
+			}
+			issues = append(issues, types.DoctorIssue{
+				Kind:    types.DOCTOR_FOREIGN_KEY,
+				Table:   child,
+				RowId:   rowId,
+				Message: fmt.Sprintf("row %d of %s points at a missing row of %s", rowId, child, parent),
+				Fix:     fmt.Sprintf("delete the row from %s", child),
+			})
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return issues, err
+		}
+	}
+	return issues, nil
+}
+
+// textures of mods whose dir is gone are left to sync
+func (h *DbHelper) missingTextureDirIssues() ([]types.DoctorIssue, error) {
+	issues := []types.DoctorIssue{}
+
+	for _, game := range types.Games {
+		mods, err := h.SelectModsByGame(game)
+		if err != nil {
+			return issues, err
+		}
+		modsById := make(map[int]types.Mod, len(mods))
+		for _, m := range mods {
+			modsById[m.Id] = m
+		}
+
+		textures, err := h.SelectTexturesByGame(game)
+		if err != nil {
+			return issues, err
+		}
+
+		for _, texture := range textures {
+			mod, ok := modsById[texture.ModId]
+			if !ok {
+				continue
+			}
+			if exists, _ := util.FileExists(util.GetModDir(mod)); !exists {
+				continue
+			}
+			if exists, _ := util.FileExists(filepath.Join(util.GetModDir(mod), "textures", texture.Filename)); exists {
+				continue
+			}
+			issues = append(issues, types.DoctorIssue{
+				Kind:      types.DOCTOR_MISSING_TEXTURE_DIR,
+				Game:      game,
+				ModId:     mod.Id,
+				TextureId: texture.Id,
+				Message:   fmt.Sprintf("the dir of texture %s of %s is missing", texture.Filename, mod.Filename),
+				Fix:       "delete the texture row",
+			})
+		}
+	}
+	return issues, nil
+}
+
+func (h *DbHelper) missingCharacterIssues() ([]types.DoctorIssue, error) {
+	mods, err := h.queries.SelectModsWithoutCharacter(h.ctx)
+	if err != nil {
+		return []types.DoctorIssue{}, err
+	}
+
+	issues := make([]types.DoctorIssue, 0, len(mods))
+	for _, m := range mods {
+		mod := modFromDb(m)
+		issues = append(issues, types.DoctorIssue{
+			Kind:    types.DOCTOR_MISSING_CHARACTER,
+			Game:    mod.Game,
+			ModId:   mod.Id,
+			Message: fmt.Sprintf("%s points at %s which does not exist", mod.Filename, mod.Character),
+			Fix:     fmt.Sprintf("add %s as a custom character", mod.Character),
+		})
+	}
+	return issues, nil
+}
+
+// the cached path is relative to the dir the generator copies, mods kept as an
+// archive can not be checked without extracting them
+func (h *DbHelper) staleIniCacheIssues() ([]types.DoctorIssue, error) {
+	rows, err := h.queries.SelectIniCacheWithMods(h.ctx)
+	if err != nil {
+		return []types.DoctorIssue{}, err
+	}
+
+	issues := []types.DoctorIssue{}
+	for _, row := range rows {
+		if row.IniFname == "" {
+			continue
+		}
+		mod := modFromDb(row.Mod)
+
+		archive, err := util.GetModArchive(mod)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(archive); err != nil || !info.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(archive, row.IniFname)); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		issues = append(issues, types.DoctorIssue{
+			Kind:    types.DOCTOR_STALE_INI_CACHE,
+			Game:    mod.Game,
+			ModId:   mod.Id,
+			Message: fmt.Sprintf("the cached ini %s is not in %s anymore", row.IniFname, mod.Filename),
+			Fix:     "clear the cached inis of the mod so they are searched again on the next reload",
+		})
+	}
+	return issues, nil
+}
+
+func (h *DbHelper) playlistMissingModIssues() ([]types.DoctorIssue, error) {
+	rows, err := h.queries.SelectPlaylistModCrossRefsWithoutMod(h.ctx)
+	if err != nil {
+		return []types.DoctorIssue{}, err
+	}
+
+	issues := make([]types.DoctorIssue, 0, len(rows))
+	for _, row := range rows {
+		issues = append(issues, types.DoctorIssue{
+			Kind:       types.DOCTOR_PLAYLIST_MISSING_MOD,
+			Game:       types.Game(row.Game),
+			PlaylistId: int(row.ID),
+			ModId:      int(row.ModID),
+			Message:    fmt.Sprintf("playlist %s contains mod %d which was deleted", row.PlaylistName, row.ModID),
+			Fix:        fmt.Sprintf("remove the mod from %s", row.PlaylistName),
+		})
+	}
+	return issues, nil
+}
@@ -0,0 +1,82 @@
+package dbh
+
+import (
+	"database/sql"
+	"errors"
+	"hmm/db"
+	"hmm/pkg/pref"
+	"hmm/pkg/types"
+	"slices"
+)
+
+// SetExclusiveGamesPref sets the names of the games where characters only
+// have one mod enabled unless the character overrides it
+func (h *DbHelper) SetExclusiveGamesPref(games pref.Preference[[]string]) {
+	h.exclusiveGames = games
+}
+
+// categories are only exclusive when it was turned on for the category
+func (h *DbHelper) isExclusive(c types.Character) bool {
+	switch c.Exclusive {
+	case types.EXCLUSIVE_ON:
+		return true
+	case types.EXCLUSIVE_OFF:
+		return false
+	}
+	if c.IsCategory() || h.exclusiveGames == nil {
+		return false
+	}
+	return slices.Contains(h.exclusiveGames.Get(), c.Game.Name())
+}
+
+func (h *DbHelper) UpdateCharacterExclusive(id int, game types.Game, mode types.ExclusiveMode) error {
+	return h.queries.UpdateCharacterExclusive(h.ctx, db.UpdateCharacterExclusiveParams{
+		Exclusive: int64(mode),
+		ID:        int64(id),
+		Game:      game.Int64(),
+	})
+}
+
+// disables the other enabled mods of the character when it is exclusive,
+// mods the enabled mod depends on are kept
+func (h *DbHelper) disableOtherMods(q *db.Queries, mod types.Mod, graph dependencyGraph) ([]types.Mod, error) {
+	disabled := []types.Mod{}
+
+	dbCharacter, err := q.SelectCharacterById(h.ctx, db.SelectCharacterByIdParams{
+		ID:   int64(mod.CharacterId),
+		Game: mod.Game.Int64(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return disabled, nil
+	}
+	if err != nil || !h.isExclusive(characterFromDb(dbCharacter)) {
+		return disabled, err
+	}
+
+	rows, err := q.SelectModsByCharacterId(h.ctx, db.SelectModsByCharacterIdParams{
+		CharId: int64(mod.CharacterId),
+		Game:   mod.Game.Int64(),
+	})
+	if err != nil {
+		return disabled, err
+	}
+
+	needed := graph.resolve([]types.Mod{mod})
+	for _, row := range rows {
+		other := modFromDb(row)
+		if !other.Enabled || other.Id == mod.Id || slices.ContainsFunc(needed, func(m types.Mod) bool { return m.Id == other.Id }) {
+			continue
+		}
+
+		err := q.UpdateModEnabledById(h.ctx, db.UpdateModEnabledByIdParams{
+			Selected: false,
+			ID:       int64(other.Id),
+		})
+		if err != nil {
+			return disabled, err
+		}
+		other.Enabled = false
+		disabled = append(disabled, other)
+	}
+	return disabled, nil
+}
@@ -4,33 +4,59 @@ import (
 	"hmm/db"
 )
 
+// File is relative to the dir the mod is generated into, an empty File is saved
+// for mods without an ini so they are not searched again
 type IniCacheEntry struct {
-	ModId int
-	File  string
+	ModId       int
+	File        string
+	Fingerprint string
 }
 
 type IniCache interface {
-	SelectIniEntryByModId(id int) (IniCacheEntry, error)
-	InsertIniEntry(modId int, file string) error
+	SelectIniEntriesByModId(id int) ([]IniCacheEntry, error)
+	ReplaceIniEntries(modId int, fingerprint string, files []string) error
+	SelectModFingerprint(id int) (string, error)
 }
 
 var _ IniCache = (*DbHelper)(nil)
 
-func (h *DbHelper) SelectIniEntryByModId(id int) (IniCacheEntry, error) {
-
-	dbEntry, err := h.queries.SelectIniCacheByModId(h.ctx, int64(id))
+func (h *DbHelper) SelectIniEntriesByModId(id int) ([]IniCacheEntry, error) {
+	rows, err := h.queries.SelectIniCacheByModId(h.ctx, int64(id))
 	if err != nil {
-		return IniCacheEntry{}, err
+		return []IniCacheEntry{}, err
+	}
+
+	entries := make([]IniCacheEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, IniCacheEntry{
+			ModId:       int(row.ModID),
+			File:        row.Fname,
+			Fingerprint: row.Fingerprint,
+		})
 	}
-	return IniCacheEntry{
-		ModId: int(dbEntry.ModID),
-		File:  dbEntry.Fname,
-	}, nil
+	return entries, nil
 }
 
-func (h *DbHelper) InsertIniEntry(modId int, file string) error {
-	return h.queries.InsertOrUpdateEntry(h.ctx, db.InsertOrUpdateEntryParams{
-		ModId: int64(modId),
-		FName: file,
+// ReplaceIniEntries deletes the cached files of the mod and saves files with the fingerprint
+// they were found for, no files saves an empty entry
+func (h *DbHelper) ReplaceIniEntries(modId int, fingerprint string, files []string) error {
+	if len(files) == 0 {
+		files = []string{""}
+	}
+	return h.withTransaction(func(q *db.Queries) error {
+		if err := q.DeleteIniCacheByModId(h.ctx, int64(modId)); err != nil {
+			return err
+		}
+		for _, file := range files {
+			err := q.InsertIniCacheEntry(h.ctx, db.InsertIniCacheEntryParams{
+				ModId:       int64(modId),
+				FName:       file,
+				Fingerprint: fingerprint,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
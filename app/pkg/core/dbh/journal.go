@@ -0,0 +1,188 @@
+package dbh
+
+import (
+	"errors"
+	"hmm/db"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"slices"
+	"sync"
+)
+
+// operations kept for undo, the oldest are dropped first
+const journalSize = 100
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+type JournalDao interface {
+	Undo() (string, error)
+	Redo() (string, error)
+	SelectJournal() types.Journal
+}
+
+var _ JournalDao = (*DbHelper)(nil)
+
+// an applied operation with the functions that revert and reapply it,
+// both only use helpers that are not recorded
+type journalEntry struct {
+	name string
+	undo func() error
+	redo func() error
+}
+
+// the journal only lives in memory, the entries hold closures over the rows they change so
+// the history is per session and is lost when the app restarts or the database is replaced
+type journal struct {
+	mutex sync.Mutex
+	undo  []journalEntry
+	redo  []journalEntry
+}
+
+func (h *DbHelper) record(name string, undo, redo func() error) {
+	h.journal.mutex.Lock()
+	defer h.journal.mutex.Unlock()
+
+	h.journal.undo = append(h.journal.undo, journalEntry{name, undo, redo})
+	if len(h.journal.undo) > journalSize {
+		h.journal.undo = slices.Delete(h.journal.undo, 0, len(h.journal.undo)-journalSize)
+	}
+	h.journal.redo = nil
+}
+
+// the ids in the journal belong to the old database after it was replaced
+func (h *DbHelper) clearJournal() {
+	h.journal.mutex.Lock()
+	defer h.journal.mutex.Unlock()
+
+	h.journal.undo = nil
+	h.journal.redo = nil
+}
+
+// Undo reverts the last operation and returns its name. an operation that fails to
+// revert, e.g. because its mods were removed, is dropped so it does not block the ones below
+func (h *DbHelper) Undo() (string, error) {
+	h.journal.mutex.Lock()
+	defer h.journal.mutex.Unlock()
+
+	if len(h.journal.undo) == 0 {
+		return "", ErrNothingToUndo
+	}
+	entry := h.journal.undo[len(h.journal.undo)-1]
+	h.journal.undo = h.journal.undo[:len(h.journal.undo)-1]
+	if err := entry.undo(); err != nil {
+		return entry.name, err
+	}
+
+	h.journal.redo = append(h.journal.redo, entry)
+	return entry.name, nil
+}
+
+// Redo applies the last undone operation again and returns its name, a failing
+// operation is dropped like in Undo
+func (h *DbHelper) Redo() (string, error) {
+	h.journal.mutex.Lock()
+	defer h.journal.mutex.Unlock()
+
+	if len(h.journal.redo) == 0 {
+		return "", ErrNothingToRedo
+	}
+	entry := h.journal.redo[len(h.journal.redo)-1]
+	h.journal.redo = h.journal.redo[:len(h.journal.redo)-1]
+	if err := entry.redo(); err != nil {
+		return entry.name, err
+	}
+
+	h.journal.undo = append(h.journal.undo, entry)
+	return entry.name, nil
+}
+
+// SelectJournal returns the names of the operations that can be undone and redone, newest first
+func (h *DbHelper) SelectJournal() types.Journal {
+	h.journal.mutex.Lock()
+	defer h.journal.mutex.Unlock()
+
+	j := types.Journal{
+		Undo: make([]string, 0, len(h.journal.undo)),
+		Redo: make([]string, 0, len(h.journal.redo)),
+	}
+	for i := len(h.journal.undo) - 1; i >= 0; i-- {
+		j.Undo = append(j.Undo, h.journal.undo[i].name)
+	}
+	for i := len(h.journal.redo) - 1; i >= 0; i-- {
+		j.Redo = append(j.Redo, h.journal.redo[i].name)
+	}
+	return j
+}
+
+func (h *DbHelper) selectEnabledIds(game types.Game) ([]int64, error) {
+	mods, err := h.queries.SelectEnabledModsForGame(h.ctx, game.Int64())
+	if err != nil {
+		return []int64{}, err
+	}
+	ids := make([]int64, len(mods))
+	for i, m := range mods {
+		ids[i] = m.ID
+	}
+	return ids, nil
+}
+
+// runs fn and records the mods it enabled and disabled, undo only changes those
+// mods so later changes to other mods are kept. only their sidecars are written
+func (h *DbHelper) journalEnabled(name string, game types.Game, fn func() error) error {
+	before, err := h.selectEnabledIds(game)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	after, err := h.selectEnabledIds(game)
+	if err != nil {
+		log.LogError(err.Error())
+		return nil
+	}
+
+	enabled := slices.DeleteFunc(slices.Clone(after), func(id int64) bool { return slices.Contains(before, id) })
+	disabled := slices.DeleteFunc(slices.Clone(before), func(id int64) bool { return slices.Contains(after, id) })
+	h.markEnabled(enabled)
+	h.recordEnabled(name, enabled, disabled)
+	h.writeModsSidecars(slices.Concat(enabled, disabled))
+	return nil
+}
+
+func (h *DbHelper) recordEnabled(name string, enabled, disabled []int64) {
+	if len(enabled) == 0 && len(disabled) == 0 {
+		return
+	}
+	h.record(
+		name,
+		func() error { return h.setModsEnabled(disabled, enabled) },
+		func() error { return h.setModsEnabled(enabled, disabled) },
+	)
+}
+
+func (h *DbHelper) setModsEnabled(enable, disable []int64) error {
+	err := h.withTransaction(func(q *db.Queries) error {
+		for _, params := range []db.UpdateModsEnabledByIdsParams{
+			{Selected: true, Ids: enable},
+			{Selected: false, Ids: disable},
+		} {
+			if len(params.Ids) == 0 {
+				continue
+			}
+			if err := q.UpdateModsEnabledByIds(h.ctx, params); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.markEnabled(enable)
+	h.writeModsSidecars(slices.Concat(enable, disable))
+	return nil
+}
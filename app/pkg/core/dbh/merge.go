@@ -0,0 +1,263 @@
+package dbh
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hmm/db"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type MergeDao interface {
+	MergeMods(keepId, removeId int) error
+}
+
+var _ MergeDao = (*DbHelper)(nil)
+
+// a dir moved from the removed mod, moved back when the merge fails
+type mergeMove struct {
+	from, to string
+}
+
+func revertMergeMoves(moves []mergeMove) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		if err := os.Rename(moves[i].to, moves[i].from); err != nil {
+			log.LogError(err.Error())
+		}
+	}
+}
+
+// a name not used by the mod, the removed copy gets a suffix when both have a texture or keymap with the same name
+func unusedName(dir, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		if exists, _ := util.FileExists(filepath.Join(dir, name)); !exists {
+			return name
+		}
+		name = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+}
+
+// MergeMods moves the tags, textures, keymaps, playlists and dependencies of the removed mod
+// to the kept mod, the removed mods row and files are deleted
+func (h *DbHelper) MergeMods(keepId, removeId int) error {
+	if keepId == removeId {
+		return errors.New("can not merge a mod with itself")
+	}
+
+	keep, err := h.SelectModById(keepId)
+	if err != nil {
+		return err
+	}
+	remove, err := h.SelectModById(removeId)
+	if err != nil {
+		return err
+	}
+	textures, err := h.SelectTexturesByModId(removeId)
+	if err != nil {
+		return err
+	}
+
+	keepDir := util.GetModDir(keep)
+	removeDir := util.GetModDir(remove)
+	defer h.BeginWrite(keepDir, removeDir)()
+
+	moves := []mergeMove{}
+	move := func(from, to string) error {
+		if err := os.MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+		moves = append(moves, mergeMove{from, to})
+		return nil
+	}
+
+	textureNames := map[int]string{}
+	for _, t := range textures {
+		name := unusedName(filepath.Join(keepDir, "textures"), t.Filename)
+		from := filepath.Join(removeDir, "textures", t.Filename)
+		if exists, _ := util.FileExists(from); exists {
+			if err := move(from, filepath.Join(keepDir, "textures", name)); err != nil {
+				revertMergeMoves(moves)
+				return err
+			}
+		}
+		textureNames[t.Id] = name
+	}
+
+	keymaps, _ := os.ReadDir(util.GetKeyMapsDir(remove))
+	for _, keymap := range keymaps {
+		name := unusedName(util.GetKeyMapsDir(keep), keymap.Name())
+		if err := move(filepath.Join(util.GetKeyMapsDir(remove), keymap.Name()), filepath.Join(util.GetKeyMapsDir(keep), name)); err != nil {
+			revertMergeMoves(moves)
+			return err
+		}
+	}
+
+	err = h.withTransaction(func(q *db.Queries) error {
+		if err := q.UpdateModMetadata(h.ctx, mergedMetadata(keep, remove)); err != nil {
+			return err
+		}
+		if err := h.mergePersonal(q, keep, remove); err != nil {
+			return err
+		}
+
+		for id, name := range textureNames {
+			err := q.UpdateTextureModId(h.ctx, db.UpdateTextureModIdParams{
+				ModId: int64(keepId),
+				Fname: name,
+				ID:    int64(id),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := q.MergeModTags(h.ctx, db.MergeModTagsParams{KeepId: int64(keepId), RemoveId: int64(removeId)}); err != nil {
+			return err
+		}
+		if err := q.DeleteTagsByModId(h.ctx, int64(removeId)); err != nil {
+			return err
+		}
+
+		if err := q.MergePlaylistModCrossRefs(h.ctx, db.MergePlaylistModCrossRefsParams{KeepId: int64(keepId), RemoveId: int64(removeId)}); err != nil {
+			return err
+		}
+		if err := q.DeletePlaylistModCrossRefsByModId(h.ctx, int64(removeId)); err != nil {
+			return err
+		}
+
+		if err := q.MergeModDependencies(h.ctx, db.MergeModDependenciesParams{KeepId: int64(keepId), RemoveId: int64(removeId)}); err != nil {
+			return err
+		}
+		if err := q.MergeModDependents(h.ctx, db.MergeModDependentsParams{KeepId: int64(keepId), RemoveId: int64(removeId)}); err != nil {
+			return err
+		}
+		if err := q.DeleteModDependenciesByModId(h.ctx, int64(removeId)); err != nil {
+			return err
+		}
+
+		err := q.DeleteArchivePasswordsByScope(h.ctx, db.DeleteArchivePasswordsByScopeParams{
+			Scope:   int64(PASSWORD_SCOPE_MOD),
+			ScopeId: int64(removeId),
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteQuarantinedFilesByModId(h.ctx, int64(removeId)); err != nil {
+			return err
+		}
+		if err := h.mergeUsage(q, int64(keepId), int64(removeId)); err != nil {
+			return err
+		}
+		err = q.DeletePendingDelete(h.ctx, db.DeletePendingDeleteParams{
+			Kind:  int64(PENDING_DELETE_MOD),
+			RowId: int64(removeId),
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteModById(h.ctx, int64(removeId))
+	})
+	if err != nil {
+		revertMergeMoves(moves)
+		return err
+	}
+
+	if err := os.RemoveAll(removeDir); err != nil {
+		log.LogError(err.Error())
+	}
+
+	h.writeModSidecars(keepId)
+	for id := range textureNames {
+		h.writeTextureSidecars(id)
+	}
+	return nil
+}
+
+// the mod stays a favorite if either was, the higher rating is kept and the notes are joined
+func (h *DbHelper) mergePersonal(q *db.Queries, keep, remove types.Mod) error {
+	notes := keep.Notes
+	if remove.Notes != "" && remove.Notes != keep.Notes {
+		notes = strings.TrimSpace(keep.Notes + "\n" + remove.Notes)
+	}
+
+	id := int64(keep.Id)
+	if err := q.UpdateModFavorite(h.ctx, db.UpdateModFavoriteParams{Favorite: keep.Favorite || remove.Favorite, ID: id}); err != nil {
+		return err
+	}
+	if err := q.UpdateModRating(h.ctx, db.UpdateModRatingParams{Rating: int64(max(keep.Rating, remove.Rating)), ID: id}); err != nil {
+		return err
+	}
+	return q.UpdateModNotes(h.ctx, db.UpdateModNotesParams{Notes: notes, ID: id})
+}
+
+// the counts of both mods are added, the time since the last reload is dropped
+func (h *DbHelper) mergeUsage(q *db.Queries, keepId, removeId int64) error {
+	removed, err := q.SelectModUsageById(h.ctx, removeId)
+	if err != nil || len(removed) == 0 {
+		return err
+	}
+	kept, err := q.SelectModUsageById(h.ctx, keepId)
+	if err != nil {
+		return err
+	}
+
+	merged := removed[0]
+	if len(kept) != 0 {
+		merged.GenerateCount += kept[0].GenerateCount
+		merged.EnabledSeconds += kept[0].EnabledSeconds
+		merged.LastExportedAt = max(merged.LastExportedAt, kept[0].LastExportedAt)
+	}
+	err = q.RestoreModUsage(h.ctx, db.RestoreModUsageParams{
+		ModId:          keepId,
+		GenerateCount:  merged.GenerateCount,
+		EnabledSeconds: merged.EnabledSeconds,
+		LastExportedAt: merged.LastExportedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return q.DeleteModUsageByModId(h.ctx, removeId)
+}
+
+// the kept mods metadata with the missing values filled from the removed mod
+func mergedMetadata(keep, remove types.Mod) db.UpdateModMetadataParams {
+	if keep.GbId == 0 {
+		keep.GbId = remove.GbId
+	}
+	if keep.ModLink == "" {
+		keep.ModLink = remove.ModLink
+	}
+	if keep.GbFileName == "" {
+		keep.GbFileName = remove.GbFileName
+	}
+	if keep.GbDownloadLink == "" {
+		keep.GbDownloadLink = remove.GbDownloadLink
+	}
+	for _, image := range remove.PreviewImages {
+		if !slices.Contains(keep.PreviewImages, image) {
+			keep.PreviewImages = append(keep.PreviewImages, image)
+		}
+	}
+
+	return db.UpdateModMetadataParams{
+		Selected:       keep.Enabled || remove.Enabled,
+		PreviewImages:  strings.Join(keep.PreviewImages, "<seperator>"),
+		GbId:           sql.NullInt64{Valid: keep.GbId != 0, Int64: int64(keep.GbId)},
+		ModLink:        sql.NullString{Valid: keep.ModLink != "", String: keep.ModLink},
+		GbFilename:     sql.NullString{Valid: keep.GbFileName != "", String: keep.GbFileName},
+		GbDownloadLink: sql.NullString{Valid: keep.GbDownloadLink != "", String: keep.GbDownloadLink},
+		ID:             int64(keep.Id),
+	}
+}
@@ -3,24 +3,38 @@ package dbh
 import (
 	"database/sql"
 	"hmm/db"
+	"hmm/pkg/log"
 	"hmm/pkg/types"
+	"hmm/pkg/util"
 	"slices"
 	"strings"
+	"time"
 )
 
 type ModDao interface {
 	InsertMod(mod types.Mod) (int64, error)
 	SelectModsByCharacterName(name string, game types.Game) ([]types.Mod, error)
 	SelectModById(id int) (types.Mod, error)
+	SelectModsByGame(game types.Game) ([]types.Mod, error)
 	SelectEnabledModsByGame(game types.Game) ([]types.Mod, error)
 	SelectModByFileCharacterGame(file, character string, game types.Game) (types.Mod, error)
 	SelectModsByGbId(id int) ([]types.Mod, error)
 	DeleteUnusedMods(files []string, game types.Game) error
+	DeleteUnusedModsByCharacter(files []string, character string, game types.Game) error
+	DeleteModsByIds(ids []int) error
+	UpdateModFilename(id int, fname string) error
+	UpdateModLocation(id int, fname string, character types.Character) error
+	UpdateModFingerprint(id int, fingerprint string) error
+	SelectModFingerprints(game types.Game) (map[int]string, error)
+	SelectModFingerprint(id int) (string, error)
 	UpdateModGbId(modId, gbId int) error
 	UpdateModImages(id int, images []string) error
 	UpdateDisableAllModsByGame(game types.Game) error
-	UpdateModEnabledById(enabled bool, id int) error
+	UpdateModEnabledById(enabled bool, id int) ([]types.Mod, error)
 	UpdateModsEnabledFromSlice(ids []int64, game types.Game) error
+	SelectModsWithGbIdByGame(game types.Game) ([]types.Mod, error)
+	UpdateModGbMetadata(id int, metadata types.Mod) error
+	UpdateModSize(id int, size int64) error
 }
 
 var _ ModDao = (*DbHelper)(nil)
@@ -41,12 +55,48 @@ func modFromDb(m db.Mod) types.Mod {
 		ModLink:        m.ModLink.String,
 		GbFileName:     m.GbFileName.String,
 		GbDownloadLink: m.GbDownloadLink.String,
+		Quarantined:    m.Flags&MOD_FLAG_QUARANTINED != 0,
+		Category:       m.Flags&MOD_FLAG_CATEGORY != 0,
 		Id:             int(m.ID),
+		GbName:         m.GbName,
+		Author:         m.Author,
+		Version:        m.Version,
+		Description:    m.Description,
+		ContentRatings: splitContentRatings(m.ContentRatings),
+		AddedAt:        m.AddedAt,
+		LastEnabledAt:  m.LastEnabledAt,
+		GbUpdatedAt:    m.GbUpdatedAt,
+		Size:           m.Size,
+		Favorite:       m.Favorite,
+		Rating:         int(m.Rating),
+		Notes:          m.Notes,
 	}
 }
 
+func splitContentRatings(ratings string) []string {
+	return slices.DeleteFunc(strings.Split(ratings, ","), func(r string) bool { return r == "" })
+}
+
+// a sidecar already in the mod dir is left for sync to apply
 func (h *DbHelper) InsertMod(m types.Mod) (int64, error) {
-	return h.queries.InsertMod(h.ctx, db.InsertModParams{
+	id, err := h.queries.InsertMod(h.ctx, insertModParams(m))
+	if err != nil {
+		return id, err
+	}
+
+	if !sidecarExists(util.GetModDir(m)) {
+		h.writeModSidecars(int(id))
+	}
+	return id, nil
+}
+
+func insertModParams(m types.Mod) db.InsertModParams {
+	flags := int64(0)
+	if m.Category {
+		flags |= MOD_FLAG_CATEGORY
+	}
+
+	return db.InsertModParams{
 		ModFilename:    m.Filename,
 		Game:           int64(m.Game),
 		CharName:       m.Character,
@@ -57,7 +107,9 @@ func (h *DbHelper) InsertMod(m types.Mod) (int64, error) {
 		ModLink:        sql.NullString{Valid: m.ModLink != "", String: m.ModLink},
 		GbFilename:     sql.NullString{Valid: m.GbFileName != "", String: m.GbFileName},
 		GbDownloadLink: sql.NullString{Valid: m.GbDownloadLink != "", String: m.GbDownloadLink},
-	})
+		Flags:          flags,
+		AddedAt:        time.Now().Unix(),
+	}
 }
 
 func (h *DbHelper) SelectModsByCharacterName(name string, game types.Game) ([]types.Mod, error) {
@@ -83,7 +135,34 @@ func (h *DbHelper) SelectModById(id int) (types.Mod, error) {
 	return modFromDb(dbMod), nil
 }
 
+func (h *DbHelper) SelectModsByGame(game types.Game) ([]types.Mod, error) {
+	m, err := h.queries.SelectModsByGame(h.ctx, game.Int64())
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+	mods := make([]types.Mod, 0, len(m))
+	for _, mod := range m {
+		mods = append(mods, modFromDb(mod))
+	}
+	return mods, nil
+}
+
+// SelectEnabledModsByGame returns the enabled mods and every mod they depend on
 func (h *DbHelper) SelectEnabledModsByGame(game types.Game) ([]types.Mod, error) {
+	enabled, err := h.selectEnabledMods(game)
+	if err != nil {
+		return enabled, err
+	}
+
+	graph, err := h.selectDependencyGraph(game)
+	if err != nil {
+		log.LogError(err.Error())
+		return enabled, nil
+	}
+	return graph.resolve(enabled), nil
+}
+
+func (h *DbHelper) selectEnabledMods(game types.Game) ([]types.Mod, error) {
 	m, err := h.queries.SelectEnabledModsForGame(h.ctx, int64(game))
 	if err != nil {
 		return make([]types.Mod, 0), err
@@ -137,34 +216,227 @@ func (h *DbHelper) DeleteUnusedMods(files []string, game types.Game) error {
 	})
 }
 
+// only deletes mods of the character, used when a single character dir changed
+func (h *DbHelper) DeleteUnusedModsByCharacter(files []string, character string, game types.Game) error {
+	return h.queries.DeleteUnusedModsByCharacter(h.ctx, db.DeleteUnusedModsByCharacterParams{
+		Files:         files,
+		CharacterName: character,
+		Game:          game.Int64(),
+	})
+}
+
+// only deletes the rows, files are left untouched
+func (h *DbHelper) DeleteModsByIds(ids []int) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		return h.deleteModRows(q, ids)
+	})
+}
+
+func (h *DbHelper) deleteModRows(q *db.Queries, ids []int) error {
+	for _, id := range ids {
+		err := q.DeleteArchivePasswordsByScope(h.ctx, db.DeleteArchivePasswordsByScopeParams{
+			Scope:   int64(PASSWORD_SCOPE_MOD),
+			ScopeId: int64(id),
+		})
+		if err != nil {
+			return err
+		}
+		if err = q.DeleteQuarantinedFilesByModId(h.ctx, int64(id)); err != nil {
+			return err
+		}
+		if err = q.DeleteModDependenciesByModId(h.ctx, int64(id)); err != nil {
+			return err
+		}
+		if err = q.DeleteModUsageByModId(h.ctx, int64(id)); err != nil {
+			return err
+		}
+		if err = q.DeleteModById(h.ctx, int64(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DbHelper) UpdateModFilename(id int, fname string) error {
+	return h.queries.UpdateModFilename(h.ctx, db.UpdateModFilenameParams{
+		Fname: fname,
+		ID:    int64(id),
+	})
+}
+
+// only updates the row, used when the dir was already moved to another character
+func (h *DbHelper) UpdateModLocation(id int, fname string, character types.Character) error {
+	return h.queries.UpdateModLocation(h.ctx, db.UpdateModLocationParams{
+		Fname:        fname,
+		CharName:     character.Name,
+		CharId:       int64(character.Id),
+		CategoryFlag: categoryFlag(character),
+		ID:           int64(id),
+	})
+}
+
+func (h *DbHelper) UpdateModFingerprint(id int, fingerprint string) error {
+	return h.queries.UpdateModFingerprint(h.ctx, db.UpdateModFingerprintParams{
+		Fingerprint: fingerprint,
+		ID:          int64(id),
+	})
+}
+
+func (h *DbHelper) SelectModsWithGbIdByGame(game types.Game) ([]types.Mod, error) {
+	m, err := h.queries.SelectModsWithGbIdByGame(h.ctx, game.Int64())
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+	mods := make([]types.Mod, 0, len(m))
+	for _, mod := range m {
+		mods = append(mods, modFromDb(mod))
+	}
+	return mods, nil
+}
+
+// UpdateModGbMetadata saves the name, author, version, description, content ratings and
+// GameBanana update time of metadata
+func (h *DbHelper) UpdateModGbMetadata(id int, metadata types.Mod) error {
+	return h.queries.UpdateModGbMetadata(h.ctx, db.UpdateModGbMetadataParams{
+		GbName:         metadata.GbName,
+		Author:         metadata.Author,
+		Version:        metadata.Version,
+		Description:    metadata.Description,
+		ContentRatings: strings.Join(metadata.ContentRatings, ","),
+		GbUpdatedAt:    metadata.GbUpdatedAt,
+		ID:             int64(id),
+	})
+}
+
+func (h *DbHelper) UpdateModSize(id int, size int64) error {
+	return h.queries.UpdateModSize(h.ctx, db.UpdateModSizeParams{
+		Size: size,
+		ID:   int64(id),
+	})
+}
+
+// sets the last enabled time of mods that were just enabled
+func (h *DbHelper) markEnabled(ids []int64) {
+	if len(ids) == 0 {
+		return
+	}
+	err := h.queries.UpdateModsLastEnabledAt(h.ctx, db.UpdateModsLastEnabledAtParams{
+		LastEnabledAt: time.Now().Unix(),
+		Ids:           ids,
+	})
+	if err != nil {
+		log.LogError(err.Error())
+	}
+}
+
+// mod id to fingerprint, mods without one are left out
+func (h *DbHelper) SelectModFingerprints(game types.Game) (map[int]string, error) {
+	rows, err := h.queries.SelectModFingerprintsByGame(h.ctx, game.Int64())
+	if err != nil {
+		return map[int]string{}, err
+	}
+	fingerprints := make(map[int]string, len(rows))
+	for _, row := range rows {
+		fingerprints[int(row.ID)] = row.Fingerprint
+	}
+	return fingerprints, nil
+}
+
+// empty when the mod was not fingerprinted yet
+func (h *DbHelper) SelectModFingerprint(id int) (string, error) {
+	return h.queries.SelectModFingerprintById(h.ctx, int64(id))
+}
+
 func (h *DbHelper) UpdateModGbId(modId, gbId int) error {
-	return h.queries.UpdateModGbId(h.ctx, db.UpdateModGbIdParams{
-		GbId: sql.NullInt64{Valid: gbId > 0, Int64: int64(modId)},
-		ID:   int64(gbId),
+	err := h.queries.UpdateModGbId(h.ctx, db.UpdateModGbIdParams{
+		GbId: sql.NullInt64{Valid: gbId > 0, Int64: int64(gbId)},
+		ID:   int64(modId),
 	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(modId)
+	return nil
 }
 
 func (h *DbHelper) UpdateModImages(modId int, images []string) error {
-	return h.queries.UpdateModImages(h.ctx, db.UpdateModImagesParams{
+	err := h.queries.UpdateModImages(h.ctx, db.UpdateModImagesParams{
 		PreviewImages: strings.Join(images, "<seperator>"),
 		ID:            int64(modId),
 	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(modId)
+	return nil
 }
 
 func (h *DbHelper) UpdateDisableAllModsByGame(game types.Game) error {
-	return h.queries.UpdateDisableAllModsByGame(h.ctx, game.Int64())
+	return h.journalEnabled("Disable all mods", game, func() error {
+		return h.queries.UpdateDisableAllModsByGame(h.ctx, game.Int64())
+	})
 }
 
-func (h *DbHelper) UpdateModEnabledById(enabled bool, id int) error {
-	return h.queries.UpdateModEnabledById(h.ctx, db.UpdateModEnabledByIdParams{
-		Selected: enabled,
-		ID:       int64(id),
+// UpdateModEnabledById returns the mods that were disabled because the
+// character of the enabled mod is exclusive
+func (h *DbHelper) UpdateModEnabledById(enabled bool, id int) ([]types.Mod, error) {
+	mod, err := h.SelectModById(id)
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+
+	graph := dependencyGraph{}
+	if enabled {
+		if graph, err = h.selectDependencyGraph(mod.Game); err != nil {
+			return make([]types.Mod, 0), err
+		}
+	}
+
+	disabled := []types.Mod{}
+	err = h.withTransaction(func(q *db.Queries) error {
+		err := q.UpdateModEnabledById(h.ctx, db.UpdateModEnabledByIdParams{
+			Selected: enabled,
+			ID:       int64(id),
+		})
+		if err != nil || !enabled {
+			return err
+		}
+
+		disabled, err = h.disableOtherMods(q, mod, graph)
+		return err
 	})
+	if err != nil {
+		return make([]types.Mod, 0), err
+	}
+
+	h.writeModSidecars(id)
+	disabledIds := []int64{}
+	for _, m := range disabled {
+		h.writeModSidecars(m.Id)
+		disabledIds = append(disabledIds, int64(m.Id))
+	}
+
+	switch {
+	case enabled && !mod.Enabled:
+		h.markEnabled([]int64{int64(id)})
+		h.recordEnabled("Enable "+mod.Filename, []int64{int64(id)}, disabledIds)
+	case !enabled && mod.Enabled:
+		h.recordEnabled("Disable "+mod.Filename, []int64{}, []int64{int64(id)})
+	default:
+		h.recordEnabled("Enable "+mod.Filename, []int64{}, disabledIds)
+	}
+
+	if !enabled {
+		h.warnIfNeeded(id)
+	}
+	return disabled, nil
 }
 
 func (h *DbHelper) UpdateModsEnabledFromSlice(ids []int64, game types.Game) error {
-	return h.queries.UpdateModsEnabledFromSlice(h.ctx, db.UpdateModsEnabledFromSliceParams{
-		Enabled: ids,
-		Game:    game.Int64(),
+	return h.journalEnabled("Enable mods", game, func() error {
+		return h.queries.UpdateModsEnabledFromSlice(h.ctx, db.UpdateModsEnabledFromSliceParams{
+			Enabled: ids,
+			Game:    game.Int64(),
+		})
 	})
 }
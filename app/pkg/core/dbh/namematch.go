@@ -0,0 +1,159 @@
+package dbh
+
+import (
+	"hmm/pkg/types"
+	"strings"
+	"unicode"
+)
+
+const (
+	// lowest score a name is matched with
+	nameMatchThreshold = 0.8
+	// words shorter than this only match when they are equal
+	minFuzzyWordLength = 4
+	// lowest similarity of two words that counts as the same word
+	minWordSimilarity = 0.75
+)
+
+// matchCharacterName returns the character whose name or alias is the closest to name.
+// nothing is matched when two characters are equally close
+func matchCharacterName(name string, characters []types.Character, aliases []types.CharacterAlias) (types.Character, bool) {
+	scores := make(map[int]float64, len(characters))
+	for _, c := range characters {
+		scores[c.Id] = nameScore(name, c.Name)
+	}
+	for _, a := range aliases {
+		if score, ok := scores[a.CharacterId]; ok && !a.Disabled {
+			scores[a.CharacterId] = max(score, nameScore(name, a.Alias))
+		}
+	}
+
+	best, found, ambiguous := types.Character{}, false, false
+	bestScore := 0.0
+	for _, c := range characters {
+		score := scores[c.Id]
+		if score < nameMatchThreshold {
+			continue
+		}
+		switch {
+		case !found || score > bestScore+0.001:
+			best, bestScore, found, ambiguous = c, score, true, false
+		case score > bestScore-0.001 && c.Id != best.Id:
+			ambiguous = true
+		}
+	}
+	return best, found && !ambiguous
+}
+
+// nameScore is 1 for names with the same words and a little lower for typos or when
+// every word is in the other name, so exact names and aliases are preferred
+func nameScore(query, candidate string) float64 {
+	q, c := nameWords(query), nameWords(candidate)
+	if len(q) == 0 || len(c) == 0 {
+		return 0
+	}
+
+	joinedQuery, joinedCandidate := strings.Join(q, ""), strings.Join(c, "")
+	if joinedQuery == joinedCandidate {
+		return 1
+	}
+
+	// the same words with typos
+	score := min(wordCoverage(q, c), wordCoverage(c, q))
+
+	// short names like Ei or Hu only match the whole name
+	if len([]rune(joinedQuery)) >= minFuzzyWordLength {
+		score = max(score, 0.9*wordCoverage(q, c))
+	}
+	if len([]rune(joinedCandidate)) >= minFuzzyWordLength {
+		// dir names with extra words like RaidenShogun_Bikini_v2
+		score = max(score, 0.85*wordCoverage(c, q))
+		// names written without separators in lowercase like raidenshogunbikini
+		if strings.Contains(joinedQuery, joinedCandidate) {
+			score = max(score, 0.85)
+		}
+	}
+	return score
+}
+
+// the average similarity of each word of from to the closest word of to
+func wordCoverage(from, to []string) float64 {
+	total := 0.0
+	for _, f := range from {
+		best := 0.0
+		for _, t := range to {
+			best = max(best, wordSimilarity(f, t))
+		}
+		total += best
+	}
+	return total / float64(len(from))
+}
+
+func wordSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if min(len(ra), len(rb)) < minFuzzyWordLength {
+		return 0
+	}
+	similarity := 1 - float64(editDistance(ra, rb))/float64(max(len(ra), len(rb)))
+	if similarity < minWordSimilarity {
+		return 0
+	}
+	return similarity
+}
+
+// nameWords splits a name into lowercase words on anything that is not a letter or number
+// and on camel case, RaidenShogun_v2 is raiden shogun v2
+func nameWords(name string) []string {
+	words := []string{}
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsNumber(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// edits to turn a into b, swapping two neighbouring letters is a single edit
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,225 @@
+package dbh
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hmm/db"
+	"hmm/pkg/types"
+	"strings"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("unknown sort")
+)
+
+type PagingDao interface {
+	SelectModPage(filter types.ModPageFilter, cursor string, limit int) (types.ModPage, error)
+}
+
+var _ PagingDao = (*DbHelper)(nil)
+
+// position after the last mod of a page, the filter is kept in the cursor so
+// the next page only needs the cursor
+type modCursor struct {
+	Filter types.ModPageFilter `json:"filter"`
+	Key    any                 `json:"key"`
+	Id     int                 `json:"id"`
+}
+
+func encodeModCursor(c modCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeModCursor(cursor string) (modCursor, error) {
+	c := modCursor{}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Key == nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// the sort key and if it is descending, ties are ordered by id so the cursor is stable
+func modPageOrder(sort types.ModSort) (string, bool, error) {
+	switch sort {
+	case types.SORT_MOD_NAME:
+		return "m.fname COLLATE NOCASE", false, nil
+	case types.SORT_MOD_RATING:
+		return "m.rating", true, nil
+	case types.SORT_MOD_FAVORITE:
+		return "m.favorite", true, nil
+	case types.SORT_MOD_ADDED:
+		return "m.added_at", true, nil
+	}
+	return "", false, fmt.Errorf("%w %s", ErrInvalidSort, sort)
+}
+
+// SelectModPage returns up to limit mods matching the filter with their tags, textures and
+// characters. the filter is ignored when a cursor from the previous page is passed
+func (h *DbHelper) SelectModPage(filter types.ModPageFilter, cursor string, limit int) (types.ModPage, error) {
+	page := types.ModPage{Mods: []types.ModWithTags{}, Characters: []types.Character{}}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	var after *modCursor
+	if cursor != "" {
+		c, err := decodeModCursor(cursor)
+		if err != nil {
+			return page, err
+		}
+		filter = c.Filter
+		after = &c
+	}
+
+	key, desc, err := modPageOrder(filter.Sort)
+	if err != nil {
+		return page, err
+	}
+
+	conditions := []string{"m.game = ?"}
+	args := []any{filter.Game.Int64()}
+	if filter.Element != "" {
+		conditions = append(conditions, "LOWER(c.element) = LOWER(?)")
+		args = append(args, filter.Element)
+	}
+	if filter.CharacterId != 0 {
+		conditions = append(conditions, "m.char_id = ?")
+		args = append(args, filter.CharacterId)
+	}
+	if filter.Enabled != nil {
+		conditions = append(conditions, "m.selected = ?")
+		args = append(args, *filter.Enabled)
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "EXISTS(SELECT 1 FROM tag t WHERE t.mod_id = m.id AND t.tag_name = ?)")
+		args = append(args, filter.Tag)
+	}
+	if filter.HasTextures != nil {
+		exists := "EXISTS(SELECT 1 FROM texture x WHERE x.mod_id = m.id)"
+		if !*filter.HasTextures {
+			exists = "NOT " + exists
+		}
+		conditions = append(conditions, exists)
+	}
+
+	dir, op := "ASC", ">"
+	if desc {
+		dir, op = "DESC", "<"
+	}
+	if after != nil {
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND m.id > ?))", key, op))
+		args = append(args, after.Key, after.Key, after.Id)
+	}
+	// one extra row tells if there is a next page
+	args = append(args, limit+1)
+
+	rows, err := h.db.QueryContext(h.ctx, fmt.Sprintf(`
+		SELECT m.id, %[1]s
+		FROM mod m
+		LEFT JOIN character c ON c.id = m.char_id AND c.game = m.game
+		WHERE %[2]s
+		ORDER BY %[1]s %[3]s, m.id
+		LIMIT ?`, key, strings.Join(conditions, " AND "), dir),
+		args...,
+	)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	keys := []any{}
+	for rows.Next() {
+		var id int64
+		var k any
+		if err := rows.Scan(&id, &k); err != nil {
+			return page, err
+		}
+		ids = append(ids, id)
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(ids) > limit {
+		ids, keys = ids[:limit], keys[:limit]
+		page.NextCursor = encodeModCursor(modCursor{Filter: filter, Key: keys[limit-1], Id: int(ids[limit-1])})
+	}
+	if len(ids) == 0 {
+		return page, nil
+	}
+
+	return page, h.fillModPage(&page, filter.Game, ids)
+}
+
+// loads the mods with their tags and textures with one query per table, keyed by id
+func (h *DbHelper) selectModsWithTags(ids []int64) (map[int64]*types.ModWithTags, error) {
+	mods, err := h.queries.SelectModsByIds(h.ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := h.queries.SelectTagsByModIds(h.ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	textures, err := h.queries.SelectTexturesByModIds(h.ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int64]*types.ModWithTags, len(mods))
+	for _, m := range mods {
+		byId[m.ID] = &types.ModWithTags{Mod: modFromDb(m), Tags: []types.Tag{}, Textures: []types.Texture{}}
+	}
+	for _, t := range tags {
+		if m, ok := byId[t.ModID]; ok {
+			m.Tags = append(m.Tags, types.Tag{ModId: int(t.ModID), Name: t.TagName})
+		}
+	}
+	for _, t := range textures {
+		if m, ok := byId[t.ModID]; ok {
+			m.Textures = append(m.Textures, textureFromDb(t))
+		}
+	}
+	return byId, nil
+}
+
+// loads the rows of the page with one query per table
+func (h *DbHelper) fillModPage(page *types.ModPage, game types.Game, ids []int64) error {
+	byId, err := h.selectModsWithTags(ids)
+	if err != nil {
+		return err
+	}
+
+	characterIds := []int64{}
+	for _, id := range ids {
+		if m, ok := byId[id]; ok {
+			page.Mods = append(page.Mods, *m)
+			characterIds = append(characterIds, int64(m.Mod.CharacterId))
+		}
+	}
+
+	characters, err := h.queries.SelectCharactersByIds(h.ctx, db.SelectCharactersByIdsParams{Game: game.Int64(), Ids: characterIds})
+	if err != nil {
+		return err
+	}
+	for _, c := range characters {
+		page.Characters = append(page.Characters, characterFromDb(c))
+	}
+	return nil
+}
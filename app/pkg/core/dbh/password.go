@@ -0,0 +1,67 @@
+package dbh
+
+import (
+	"hmm/db"
+	"hmm/pkg/types"
+	"slices"
+)
+
+type PasswordScope int
+
+const (
+	PASSWORD_SCOPE_MOD       PasswordScope = 0
+	PASSWORD_SCOPE_GB_MOD    PasswordScope = 1
+	PASSWORD_SCOPE_SUBMITTER PasswordScope = 2
+)
+
+type PasswordDao interface {
+	InsertArchivePassword(scope PasswordScope, id int, password string) error
+	SelectArchivePasswords(scope PasswordScope, id int) ([]string, error)
+	DeleteArchivePassword(scope PasswordScope, id int, password string) error
+	SelectArchivePasswordsForMod(mod types.Mod) []string
+}
+
+var _ PasswordDao = (*DbHelper)(nil)
+
+func (h *DbHelper) InsertArchivePassword(scope PasswordScope, id int, password string) error {
+	return h.queries.InsertArchivePassword(h.ctx, db.InsertArchivePasswordParams{
+		Scope:    int64(scope),
+		ScopeId:  int64(id),
+		Password: password,
+	})
+}
+
+func (h *DbHelper) SelectArchivePasswords(scope PasswordScope, id int) ([]string, error) {
+	passwords, err := h.queries.SelectArchivePasswords(h.ctx, db.SelectArchivePasswordsParams{
+		Scope:   int64(scope),
+		ScopeId: int64(id),
+	})
+	if err != nil {
+		return make([]string, 0), err
+	}
+	return passwords, nil
+}
+
+func (h *DbHelper) DeleteArchivePassword(scope PasswordScope, id int, password string) error {
+	return h.queries.DeleteArchivePassword(h.ctx, db.DeleteArchivePasswordParams{
+		Scope:    int64(scope),
+		ScopeId:  int64(id),
+		Password: password,
+	})
+}
+
+// passwords saved for the local mod first then the gamebanana mod it came from
+func (h *DbHelper) SelectArchivePasswordsForMod(mod types.Mod) []string {
+	passwords, _ := h.SelectArchivePasswords(PASSWORD_SCOPE_MOD, mod.Id)
+	if mod.GbId != 0 {
+		gb, _ := h.SelectArchivePasswords(PASSWORD_SCOPE_GB_MOD, mod.GbId)
+		passwords = append(passwords, gb...)
+	}
+	result := make([]string, 0, len(passwords))
+	for _, p := range passwords {
+		if !slices.Contains(result, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package dbh
+
+import (
+	"cmp"
+	"errors"
+	"hmm/db"
+	"hmm/pkg/types"
+	"slices"
+)
+
+var ErrInvalidRating = errors.New("rating must be between 0 and 5")
+
+// favorites, ratings and notes set by the user, kept in hmm.json so they survive a rebuild
+type PersonalDao interface {
+	UpdateModFavorite(id int, favorite bool) error
+	UpdateModRating(id int, rating int) error
+	UpdateModNotes(id int, notes string) error
+}
+
+var _ PersonalDao = (*DbHelper)(nil)
+
+func (h *DbHelper) UpdateModFavorite(id int, favorite bool) error {
+	err := h.queries.UpdateModFavorite(h.ctx, db.UpdateModFavoriteParams{
+		Favorite: favorite,
+		ID:       int64(id),
+	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(id)
+	return nil
+}
+
+// UpdateModRating sets a rating from 1 to 5, 0 clears it
+func (h *DbHelper) UpdateModRating(id int, rating int) error {
+	if rating < 0 || rating > 5 {
+		return ErrInvalidRating
+	}
+	err := h.queries.UpdateModRating(h.ctx, db.UpdateModRatingParams{
+		Rating: int64(rating),
+		ID:     int64(id),
+	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(id)
+	return nil
+}
+
+func (h *DbHelper) UpdateModNotes(id int, notes string) error {
+	err := h.queries.UpdateModNotes(h.ctx, db.UpdateModNotesParams{
+		Notes: notes,
+		ID:    int64(id),
+	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(id)
+	return nil
+}
+
+// SortCharacterMods sorts the mods of each character in place, mods that compare equal
+// keep the name order of the query
+func SortCharacterMods(characters []types.CharacterWithModsAndTags, sort types.ModSort) {
+	var compare func(a, b types.ModWithTags) int
+	switch sort {
+	case types.SORT_MOD_RATING:
+		compare = func(a, b types.ModWithTags) int { return cmp.Compare(b.Mod.Rating, a.Mod.Rating) }
+	case types.SORT_MOD_ADDED:
+		compare = func(a, b types.ModWithTags) int { return cmp.Compare(b.Mod.AddedAt, a.Mod.AddedAt) }
+	case types.SORT_MOD_FAVORITE:
+		compare = func(a, b types.ModWithTags) int {
+			if a.Mod.Favorite == b.Mod.Favorite {
+				return 0
+			}
+			if a.Mod.Favorite {
+				return -1
+			}
+			return 1
+		}
+	default:
+		return
+	}
+
+	for _, c := range characters {
+		slices.SortStableFunc(c.ModWithTags, compare)
+	}
+}
+
+// SelectSortedCharacterMods selects like SelectCharacterWithModsTagsAndTextures and sorts the
+// mods of each character, the library uses it with LibrarySortPref
+func (h *DbHelper) SelectSortedCharacterMods(game types.Game, modFileName string, characterName string, tagName string, sort types.ModSort) ([]types.CharacterWithModsAndTags, error) {
+	characters, err := h.SelectCharacterWithModsTagsAndTextures(game, modFileName, characterName, tagName)
+	if err != nil {
+		return characters, err
+	}
+	SortCharacterMods(characters, sort)
+	return characters, nil
+}
@@ -13,15 +13,82 @@ type PlaylistDao interface {
 	DeletePlaylistById(id int64) error
 	EnablePlaylist(id int64, game types.Game) error
 	SelectPlaylists() ([]types.Playlist, error)
+	InsertPlaylistWithMods(game types.Game, name string, modIds []int) error
 }
 
 var _ PlaylistDao = (*DbHelper)(nil)
 
 func (h *DbHelper) DeletePlaylistById(id int64) error {
-	return h.queries.DeletePlaylistById(h.ctx, id)
+	playlist, err := h.queries.SelectPlaylistById(h.ctx, id)
+	if err != nil {
+		return err
+	}
+	modIds, err := h.queries.SelectPlaylistModIds(h.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := h.deletePlaylist(id); err != nil {
+		return err
+	}
+	h.record(
+		"Delete playlist "+playlist.PlaylistName,
+		func() error { return h.restorePlaylist(playlist, modIds) },
+		func() error { return h.deletePlaylist(id) },
+	)
+	return nil
+}
+
+func (h *DbHelper) deletePlaylist(id int64) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		if err := q.DeletePlaylistModCrossRefsByPlaylistId(h.ctx, id); err != nil {
+			return err
+		}
+		return q.DeletePlaylistById(h.ctx, id)
+	})
+}
+
+// inserts the playlist with its original id so redo and undo of later operations still find it
+func (h *DbHelper) restorePlaylist(playlist db.Playlist, modIds []int64) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		err := q.RestorePlaylist(h.ctx, db.RestorePlaylistParams{
+			ID:   playlist.ID,
+			Name: playlist.PlaylistName,
+			Game: playlist.Game,
+		})
+		if err != nil {
+			return err
+		}
+		for _, modId := range modIds {
+			err := q.RestorePlaylistModCrossRef(h.ctx, db.RestorePlaylistModCrossRefParams{
+				ModId:      modId,
+				PlaylistId: playlist.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (h *DbHelper) UpdatePlaylistName(id int64, name string) error {
+	playlist, err := h.queries.SelectPlaylistById(h.ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := h.updatePlaylistName(id, name); err != nil {
+		return err
+	}
+	h.record(
+		"Rename playlist "+playlist.PlaylistName,
+		func() error { return h.updatePlaylistName(id, playlist.PlaylistName) },
+		func() error { return h.updatePlaylistName(id, name) },
+	)
+	return nil
+}
+
+func (h *DbHelper) updatePlaylistName(id int64, name string) error {
 	return h.queries.UpdatePlaylistName(h.ctx, db.UpdatePlaylistNameParams{
 		ID:   id,
 		Name: name,
@@ -29,9 +96,15 @@ func (h *DbHelper) UpdatePlaylistName(id int64, name string) error {
 }
 
 func (h *DbHelper) EnablePlaylist(id int64, game types.Game) error {
-	return h.queries.EnableModsForPlaylist(h.ctx, db.EnableModsForPlaylistParams{
-		PlaylistId: id,
-		Game:       game.Int64(),
+	playlist, err := h.queries.SelectPlaylistById(h.ctx, id)
+	if err != nil {
+		return err
+	}
+	return h.journalEnabled("Enable playlist "+playlist.PlaylistName, game, func() error {
+		return h.queries.EnableModsForPlaylist(h.ctx, db.EnableModsForPlaylistParams{
+			PlaylistId: id,
+			Game:       game.Int64(),
+		})
 	})
 }
 
@@ -54,8 +127,10 @@ func (h *DbHelper) SelectPlaylists() ([]types.Playlist, error) {
 }
 
 func (h *DbHelper) CreatePlaylist(game types.Game, name string) error {
-	return h.withTransaction(func(q *db.Queries) error {
-		pid, err := q.InsertPlaylist(h.ctx, db.InsertPlaylistParams{PlaylistName: name, Game: int64(game)})
+	var pid int64
+	err := h.withTransaction(func(q *db.Queries) error {
+		var err error
+		pid, err = q.InsertPlaylist(h.ctx, db.InsertPlaylistParams{PlaylistName: name, Game: int64(game)})
 		if err != nil {
 			return err
 		}
@@ -73,6 +148,22 @@ func (h *DbHelper) CreatePlaylist(game types.Game, name string) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	playlist := db.Playlist{ID: pid, PlaylistName: name, Game: game.Int64()}
+	modIds, err := h.queries.SelectPlaylistModIds(h.ctx, pid)
+	if err != nil {
+		log.LogError(err.Error())
+		return nil
+	}
+	h.record(
+		"Create playlist "+name,
+		func() error { return h.deletePlaylist(pid) },
+		func() error { return h.restorePlaylist(playlist, modIds) },
+	)
+	return nil
 }
 
 func (h *DbHelper) SelectPlaylistWithModsAndTags(game types.Game) ([]types.PlaylistWithModsAndTags, error) {
@@ -107,7 +198,21 @@ func (h *DbHelper) SelectPlaylistWithModsAndTags(game types.Game) ([]types.Playl
 				ModLink:        item.ModLink.String,
 				GbFileName:     item.GbFileName.String,
 				GbDownloadLink: item.GbDownloadLink.String,
+				Quarantined:    item.Flags&MOD_FLAG_QUARANTINED != 0,
+				Category:       item.Flags&MOD_FLAG_CATEGORY != 0,
 				Id:             int(item.ID_2),
+				GbName:         item.GbName,
+				Author:         item.Author,
+				Version:        item.Version,
+				Description:    item.Description,
+				ContentRatings: splitContentRatings(item.ContentRatings),
+				AddedAt:        item.AddedAt,
+				LastEnabledAt:  item.LastEnabledAt,
+				GbUpdatedAt:    item.GbUpdatedAt,
+				Size:           item.Size,
+				Favorite:       item.Favorite,
+				Rating:         int(item.Rating),
+				Notes:          item.Notes,
 			},
 			Tags: make([]types.Tag, 0),
 		})
@@ -146,3 +251,24 @@ func (h *DbHelper) SelectPlaylistWithModsAndTags(game types.Game) ([]types.Playl
 
 	return lst, nil
 }
+
+// creates the playlist from the given mods instead of the enabled ones
+func (h *DbHelper) InsertPlaylistWithMods(game types.Game, name string, modIds []int) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		pid, err := q.InsertPlaylist(h.ctx, db.InsertPlaylistParams{PlaylistName: name, Game: int64(game)})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range modIds {
+			err := q.InsertPlayListModCrossRef(h.ctx, db.InsertPlayListModCrossRefParams{
+				PlaylistId: pid,
+				ModId:      int64(id),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
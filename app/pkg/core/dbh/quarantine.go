@@ -0,0 +1,61 @@
+package dbh
+
+import (
+	"hmm/db"
+	"hmm/pkg/types"
+)
+
+type QuarantineDao interface {
+	InsertQuarantinedFiles(modId int, paths []string) error
+	SelectQuarantinedFiles(modId int) ([]types.QuarantinedFile, error)
+	UpdateQuarantineAllowed(modId int, path string, allowed bool) error
+}
+
+var _ QuarantineDao = (*DbHelper)(nil)
+
+func (h *DbHelper) InsertQuarantinedFiles(modId int, paths []string) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		for _, path := range paths {
+			err := q.InsertQuarantinedFile(h.ctx, db.InsertQuarantinedFileParams{
+				ModId: int64(modId),
+				Path:  path,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return q.UpdateModQuarantineFlag(h.ctx, int64(modId))
+	})
+}
+
+func (h *DbHelper) SelectQuarantinedFiles(modId int) ([]types.QuarantinedFile, error) {
+	files, err := h.queries.SelectQuarantinedFiles(h.ctx, int64(modId))
+	if err != nil {
+		return make([]types.QuarantinedFile, 0), err
+	}
+
+	result := make([]types.QuarantinedFile, 0, len(files))
+	for _, f := range files {
+		result = append(result, types.QuarantinedFile{
+			ModId:   int(f.ModID),
+			Path:    f.Path,
+			Allowed: f.Allowed,
+		})
+	}
+	return result, nil
+}
+
+// allowed files are copied by the generator, the mod stays flagged until every file is allowed
+func (h *DbHelper) UpdateQuarantineAllowed(modId int, path string, allowed bool) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		err := q.UpdateQuarantineAllowed(h.ctx, db.UpdateQuarantineAllowedParams{
+			Allowed: allowed,
+			ModId:   int64(modId),
+			Path:    path,
+		})
+		if err != nil {
+			return err
+		}
+		return q.UpdateModQuarantineFlag(h.ctx, int64(modId))
+	})
+}
@@ -0,0 +1,126 @@
+package dbh
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hmm/pkg/log"
+	"hmm/pkg/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RecreateDatabase moves the current db file aside and switches the helper
+// to a new empty db at the same path, returns where the old file was moved to.
+// queries wait for the switch, the old db is kept when the new one can not be opened
+func (h *DbHelper) RecreateDatabase(migrations fs.FS, ddl string) (string, error) {
+	dbFile := util.GetDbFile()
+	moved := fmt.Sprintf("%s.broken-%s", dbFile, time.Now().Format("2006-01-02_15-04-05"))
+
+	var opened *sql.DB
+	err := h.conn.swap(func(old *sql.DB) (*sql.DB, error) {
+		old.Close()
+
+		if err := os.Rename(dbFile, moved); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return reopenDb(old, dbFile), err
+		}
+
+		_, dbSql, err := OpenDb(h.ctx, dbFile, migrations, ddl)
+		if err != nil {
+			os.Remove(dbFile)
+			if err := os.Rename(moved, dbFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.LogError(err.Error())
+			}
+			return reopenDb(old, dbFile), err
+		}
+		opened = dbSql
+		return dbSql, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	h.connected(opened)
+	return moved, nil
+}
+
+// opens the db file that was closed for a swap again, old is kept if that fails
+// so queries return errors instead of using a nil db
+func reopenDb(old *sql.DB, dbFile string) *sql.DB {
+	dbSql, err := sql.Open("sqlite3", dbFile)
+	if err != nil {
+		log.LogError(err.Error())
+		return old
+	}
+	return dbSql
+}
+
+// OpenBackup opens a copy of the backup so running the migrations on
+// older backups does not modify them, close removes the copy
+func OpenBackup(path string, migrations fs.FS, ddl string) (helper *DbHelper, close func(), err error) {
+	tmp, err := os.MkdirTemp("", "hmm-backup")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	copyPath := filepath.Join(tmp, filepath.Base(path))
+	if err = util.CopyFile(path, copyPath, true); err != nil {
+		os.RemoveAll(tmp)
+		return nil, nil, err
+	}
+
+	queries, dbSql, err := OpenDb(context.Background(), copyPath, migrations, ddl)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, nil, err
+	}
+
+	// an empty or corrupt file still opens, make sure it can be read
+	if _, err = queries.SelectPlaylists(context.Background()); err != nil {
+		dbSql.Close()
+		os.RemoveAll(tmp)
+		return nil, nil, err
+	}
+
+	return NewDbHelper(dbSql), func() {
+		dbSql.Close()
+		os.RemoveAll(tmp)
+	}, nil
+}
+
+// BackupFiles returns the backups in GetBackupDir() newest first
+func BackupFiles() []string {
+	entries, err := os.ReadDir(GetBackupDir())
+	if err != nil {
+		return []string{}
+	}
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	backups := []backup{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".db") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(GetBackupDir(), e.Name()), info.ModTime()})
+	}
+
+	slices.SortFunc(backups, func(a, b backup) int { return b.modTime.Compare(a.modTime) })
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths
+}
@@ -0,0 +1,391 @@
+package dbh
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hmm/db"
+	"hmm/pkg/log"
+	"hmm/pkg/pref"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const recycleEntryFile = "entry.json"
+
+type RecycleDao interface {
+	SelectRecycleBin() ([]types.RecycleEntry, error)
+	RestoreRecycleEntry(id string) error
+	EmptyRecycleBin() error
+	PurgeRecycleBin() error
+}
+
+var _ RecycleDao = (*DbHelper)(nil)
+
+// rows saved with a recycled mod or texture, a texture entry has no mod
+type recycledRows struct {
+	Entry        types.RecycleEntry `json:"entry"`
+	Mod          *db.Mod            `json:"mod,omitempty"`
+	Textures     []db.Texture       `json:"textures"`
+	Tags         []db.Tag           `json:"tags"`
+	Playlists    []int64            `json:"playlists"`
+	Dependencies []db.ModDependency `json:"dependencies"`
+	Quarantine   []db.Quarantine    `json:"quarantine"`
+	Usage        []db.ModUsage      `json:"usage"`
+}
+
+// SetRecycleRetentionPref sets the days entries are kept, 0 keeps them until the bin is emptied
+func (h *DbHelper) SetRecycleRetentionPref(days pref.Preference[int]) {
+	h.recycleRetention = days
+}
+
+func recycleEntryDir(id string) string {
+	return filepath.Join(util.GetRecycleDir(), id)
+}
+
+func recycledFilesDir(id string) string {
+	return filepath.Join(recycleEntryDir(id), "files")
+}
+
+// moves dir into a new entry with the rows, the entry dir is returned so it can be
+// moved back when the transaction fails
+func recycle(rows recycledRows, dir string) (string, error) {
+	rows.Entry.Id = strconv.FormatInt(time.Now().UnixNano(), 10)
+	rows.Entry.DeletedAt = time.Now()
+
+	entryDir := recycleEntryDir(rows.Entry.Id)
+	if err := os.MkdirAll(recycledFilesDir(rows.Entry.Id), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	bytes, err := json.Marshal(rows)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(entryDir, recycleEntryFile), bytes, 0666)
+	}
+	if err != nil {
+		os.RemoveAll(entryDir)
+		return "", err
+	}
+
+	if exists, _ := util.FileExists(dir); exists {
+		if err := os.Rename(dir, filepath.Join(recycledFilesDir(rows.Entry.Id), filepath.Base(dir))); err != nil {
+			os.RemoveAll(entryDir)
+			return "", err
+		}
+	}
+	return entryDir, nil
+}
+
+// undoes recycle when the rows could not be deleted
+func unrecycle(entryDir, dir string) {
+	if entryDir == "" {
+		return
+	}
+	recycled := filepath.Join(entryDir, "files", filepath.Base(dir))
+	if exists, _ := util.FileExists(recycled); exists {
+		if err := os.Rename(recycled, dir); err != nil {
+			log.LogError(err.Error())
+			return
+		}
+	}
+	os.RemoveAll(entryDir)
+}
+
+func readRecycledRows(id string) (recycledRows, error) {
+	rows := recycledRows{}
+	bytes, err := os.ReadFile(filepath.Join(recycleEntryDir(id), recycleEntryFile))
+	if err != nil {
+		return rows, err
+	}
+	err = json.Unmarshal(bytes, &rows)
+	return rows, err
+}
+
+func (h *DbHelper) recycleMod(q *db.Queries, dbMod db.Mod) (recycledRows, error) {
+	mod := modFromDb(dbMod)
+	rows := recycledRows{
+		Entry: types.RecycleEntry{
+			Kind:      types.RECYCLE_MOD,
+			Game:      mod.Game,
+			Character: mod.Character,
+			Mod:       mod.Filename,
+		},
+		Mod: &dbMod,
+	}
+
+	var err error
+	id := int64(mod.Id)
+	if rows.Textures, err = q.SelectTexturesByModId(h.ctx, id); err != nil {
+		return rows, err
+	}
+	if rows.Tags, err = q.SelectTagsByModId(h.ctx, id); err != nil {
+		return rows, err
+	}
+	if rows.Playlists, err = q.SelectPlaylistIdsByModId(h.ctx, id); err != nil {
+		return rows, err
+	}
+	if rows.Dependencies, err = q.SelectModDependenciesInvolving(h.ctx, id); err != nil {
+		return rows, err
+	}
+	if rows.Quarantine, err = q.SelectQuarantinedFiles(h.ctx, id); err != nil {
+		return rows, err
+	}
+	if rows.Usage, err = q.SelectModUsageById(h.ctx, id); err != nil {
+		return rows, err
+	}
+	return rows, nil
+}
+
+// SelectRecycleBin lists the entries newest first, entries that can not be read are skipped
+func (h *DbHelper) SelectRecycleBin() ([]types.RecycleEntry, error) {
+	entries := []types.RecycleEntry{}
+	dirs, err := os.ReadDir(util.GetRecycleDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return entries, err
+	}
+
+	for _, dir := range dirs {
+		rows, err := readRecycledRows(dir.Name())
+		if err != nil {
+			log.LogError(err.Error())
+			continue
+		}
+		entries = append(entries, rows.Entry)
+	}
+	slices.SortFunc(entries, func(a, b types.RecycleEntry) int { return b.DeletedAt.Compare(a.DeletedAt) })
+	return entries, nil
+}
+
+// RestoreRecycleEntry puts the files back and inserts the rows, the original ids are
+// used unless they were taken by rows added after the delete
+func (h *DbHelper) RestoreRecycleEntry(id string) error {
+	rows, err := readRecycledRows(id)
+	if err != nil {
+		return err
+	}
+	if rows.Entry.Kind == types.RECYCLE_TEXTURE {
+		return h.restoreTexture(id, rows)
+	}
+	if rows.Mod == nil {
+		return fmt.Errorf("recycled mod %s has no row", id)
+	}
+
+	dir := util.GetModDir(modFromDb(*rows.Mod))
+	if exists, _ := util.FileExists(dir); exists {
+		return fmt.Errorf("%s already has a mod named %s", rows.Entry.Character, rows.Entry.Mod)
+	}
+	defer h.BeginWrite(dir)()
+
+	var modId int64
+	textureIds := []int{}
+	err = h.withTransaction(func(q *db.Queries) error {
+		var err error
+		m := rows.Mod
+		modId, err = q.RestoreMod(h.ctx, db.RestoreModParams{
+			ID:             h.unusedModId(q, m.ID),
+			Fname:          m.Fname,
+			Game:           m.Game,
+			CharName:       m.CharName,
+			CharId:         m.CharID,
+			Selected:       m.Selected,
+			PreviewImages:  m.PreviewImages,
+			GbId:           m.GbID,
+			ModLink:        m.ModLink,
+			GbFilename:     m.GbFileName,
+			GbDownloadLink: m.GbDownloadLink,
+			Flags:          m.Flags,
+			Fingerprint:    m.Fingerprint,
+			Author:         m.Author,
+			Version:        m.Version,
+			Description:    m.Description,
+			ContentRatings: m.ContentRatings,
+			AddedAt:        m.AddedAt,
+			LastEnabledAt:  m.LastEnabledAt,
+			Size:           m.Size,
+			GbUpdatedAt:    m.GbUpdatedAt,
+			Favorite:       m.Favorite,
+			Rating:         m.Rating,
+			Notes:          m.Notes,
+			GbName:         m.GbName,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, t := range rows.Textures {
+			t.ModID = modId
+			textureId, err := h.insertRecycledTexture(q, t)
+			if err != nil {
+				return err
+			}
+			textureIds = append(textureIds, int(textureId))
+		}
+
+		for _, tag := range rows.Tags {
+			if err := q.InsertTag(h.ctx, db.InsertTagParams{TagName: tag.TagName, ModId: modId}); err != nil {
+				return err
+			}
+		}
+		for _, playlist := range rows.Playlists {
+			err := q.RestorePlaylistModCrossRef(h.ctx, db.RestorePlaylistModCrossRefParams{ModId: modId, PlaylistId: playlist})
+			if err != nil {
+				return err
+			}
+		}
+		for _, dep := range rows.Dependencies {
+			if dep.ModID == m.ID {
+				dep.ModID = modId
+			}
+			if dep.Kind == int64(types.DEPENDENCY_MOD) && dep.DepID == m.ID {
+				dep.DepID = modId
+			}
+			err := q.InsertModDependency(h.ctx, db.InsertModDependencyParams{
+				ModId:  dep.ModID,
+				Kind:   dep.Kind,
+				DepId:  dep.DepID,
+				Source: dep.Source,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, file := range rows.Quarantine {
+			if err := q.InsertQuarantinedFile(h.ctx, db.InsertQuarantinedFileParams{ModId: modId, Path: file.Path}); err != nil {
+				return err
+			}
+			err := q.UpdateQuarantineAllowed(h.ctx, db.UpdateQuarantineAllowedParams{Allowed: file.Allowed, ModId: modId, Path: file.Path})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, usage := range rows.Usage {
+			err := q.RestoreModUsage(h.ctx, db.RestoreModUsageParams{
+				ModId:          modId,
+				GenerateCount:  usage.GenerateCount,
+				EnabledSeconds: usage.EnabledSeconds,
+				LastExportedAt: usage.LastExportedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return restoreRecycledFiles(id, dir)
+	})
+	if err != nil {
+		return err
+	}
+
+	os.RemoveAll(recycleEntryDir(id))
+	h.writeModSidecars(int(modId))
+	h.writeTextureSidecars(textureIds...)
+	return nil
+}
+
+func (h *DbHelper) restoreTexture(id string, rows recycledRows) error {
+	if len(rows.Textures) != 1 {
+		return fmt.Errorf("recycled texture %s has no row", id)
+	}
+	texture := rows.Textures[0]
+
+	mod, err := h.SelectModById(int(texture.ModID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("the mod %s of the texture was deleted, restore it first", rows.Entry.Mod)
+	}
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(util.GetModDir(mod), "textures", texture.Fname)
+	if exists, _ := util.FileExists(dir); exists {
+		return fmt.Errorf("%s already has a texture named %s", mod.Filename, texture.Fname)
+	}
+	defer h.BeginWrite(dir)()
+
+	var textureId int64
+	err = h.withTransaction(func(q *db.Queries) error {
+		var err error
+		if textureId, err = h.insertRecycledTexture(q, texture); err != nil {
+			return err
+		}
+		return restoreRecycledFiles(id, dir)
+	})
+	if err != nil {
+		return err
+	}
+
+	os.RemoveAll(recycleEntryDir(id))
+	h.writeTextureSidecars(int(textureId))
+	return nil
+}
+
+func (h *DbHelper) insertRecycledTexture(q *db.Queries, t db.Texture) (int64, error) {
+	textureId := sql.NullInt64{Valid: true, Int64: t.ID}
+	if _, err := q.SelectTextureById(h.ctx, t.ID); !errors.Is(err, sql.ErrNoRows) {
+		textureId.Valid = false
+	}
+
+	return q.RestoreTexture(h.ctx, db.RestoreTextureParams{
+		ID:             textureId,
+		ModId:          t.ModID,
+		Fname:          t.Fname,
+		Selected:       t.Selected,
+		PreviewImages:  t.PreviewImages,
+		GbId:           t.GbID,
+		ModLink:        t.ModLink,
+		GbFilename:     t.GbFileName,
+		GbDownloadLink: t.GbDownloadLink,
+	})
+}
+
+// the id is left null so a new one is picked when it was taken
+func (h *DbHelper) unusedModId(q *db.Queries, id int64) sql.NullInt64 {
+	_, err := q.SelectModById(h.ctx, id)
+	return sql.NullInt64{Valid: errors.Is(err, sql.ErrNoRows), Int64: id}
+}
+
+func restoreRecycledFiles(id, dir string) error {
+	recycled := filepath.Join(recycledFilesDir(id), filepath.Base(dir))
+	if exists, _ := util.FileExists(recycled); !exists {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Rename(recycled, dir)
+}
+
+func (h *DbHelper) EmptyRecycleBin() error {
+	return os.RemoveAll(util.GetRecycleDir())
+}
+
+// PurgeRecycleBin deletes the entries older than the retention setting
+func (h *DbHelper) PurgeRecycleBin() error {
+	if h.recycleRetention == nil || h.recycleRetention.Get() <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-time.Duration(h.recycleRetention.Get()) * time.Hour * 24)
+
+	entries, err := h.SelectRecycleBin()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.DeletedAt.Before(cutoff) {
+			if err := os.RemoveAll(recycleEntryDir(entry.Id)); err != nil {
+				log.LogError(err.Error())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package dbh
+
+import (
+	"database/sql"
+	"fmt"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"strings"
+)
+
+// results returned by Search
+const searchLimit = 100
+
+// the text indexed for each column of mod_search, m is the mod row.
+// columns with a higher weight rank matches higher
+var searchFields = []struct {
+	column string
+	expr   string
+	weight float64
+}{
+	{"filename", "m.fname", 10},
+	{"character", "m.char_name", 5},
+	{"tags", "COALESCE((SELECT group_concat(t.tag_name, ' ') FROM tag t WHERE t.mod_id = m.id), '')", 5},
+	{"notes", "m.notes", 3},
+	{"gamebanana", "m.gb_name || ' ' || COALESCE(m.gb_file_name, '') || ' ' || m.author", 3},
+	{"description", "m.description", 1},
+	{"textures", "COALESCE((SELECT group_concat(x.fname, ' ') FROM texture x WHERE x.mod_id = m.id), '')", 2},
+}
+
+type SearchDao interface {
+	Search(game types.Game, query string) ([]types.SearchResult, error)
+}
+
+var _ SearchDao = (*DbHelper)(nil)
+
+func searchColumns() string {
+	columns := make([]string, len(searchFields))
+	for i, f := range searchFields {
+		columns[i] = f.column
+	}
+	return strings.Join(columns, ", ")
+}
+
+func searchExprs(sep string) string {
+	exprs := make([]string, len(searchFields))
+	for i, f := range searchFields {
+		exprs[i] = f.expr
+	}
+	return strings.Join(exprs, sep)
+}
+
+// replaces the index row of the mod with the id expression
+func reindexSearch(id string) string {
+	return fmt.Sprintf(
+		"DELETE FROM mod_search WHERE rowid = %[1]s; INSERT INTO mod_search(rowid, %[2]s) SELECT m.id, %[3]s FROM mod m WHERE m.id = %[1]s;",
+		id, searchColumns(), searchExprs(", "),
+	)
+}
+
+// the triggers keep the index in sync with writes from any query
+func searchTriggers() map[string]string {
+	return map[string]string{
+		"mod_search_mod_insert": "AFTER INSERT ON mod BEGIN " + reindexSearch("NEW.id") + " END",
+		"mod_search_mod_update": "AFTER UPDATE OF fname, char_name, notes, gb_name, gb_file_name, author, description ON mod BEGIN " +
+			"DELETE FROM mod_search WHERE rowid = OLD.id; " + reindexSearch("NEW.id") + " END",
+		"mod_search_mod_delete":     "AFTER DELETE ON mod BEGIN DELETE FROM mod_search WHERE rowid = OLD.id; END",
+		"mod_search_tag_insert":     "AFTER INSERT ON tag BEGIN " + reindexSearch("NEW.mod_id") + " END",
+		"mod_search_tag_update":     "AFTER UPDATE ON tag BEGIN " + reindexSearch("OLD.mod_id") + " " + reindexSearch("NEW.mod_id") + " END",
+		"mod_search_tag_delete":     "AFTER DELETE ON tag BEGIN " + reindexSearch("OLD.mod_id") + " END",
+		"mod_search_texture_insert": "AFTER INSERT ON texture BEGIN " + reindexSearch("NEW.mod_id") + " END",
+		"mod_search_texture_update": "AFTER UPDATE OF fname, mod_id ON texture BEGIN " + reindexSearch("OLD.mod_id") + " " + reindexSearch("NEW.mod_id") + " END",
+		"mod_search_texture_delete": "AFTER DELETE ON texture BEGIN " + reindexSearch("OLD.mod_id") + " END",
+	}
+}
+
+// creates the full text index when sqlite was built with the sqlite_fts5 tag and rebuilds
+// its rows so writes made by a build without it are picked up. without fts5 the triggers
+// are dropped and Search falls back to LIKE
+func (h *DbHelper) initSearch(dbsql *sql.DB) error {
+	h.fts.Store(false)
+
+	var enabled bool
+	if err := dbsql.QueryRowContext(h.ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return err
+	}
+
+	tx, err := dbsql.BeginTx(h.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for name := range searchTriggers() {
+		if _, err := tx.ExecContext(h.ctx, "DROP TRIGGER IF EXISTS "+name); err != nil {
+			return err
+		}
+	}
+	if !enabled {
+		log.LogDebug("sqlite was built without fts5, search uses LIKE")
+		return tx.Commit()
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS mod_search USING fts5(%s, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3')", searchColumns()),
+		"DELETE FROM mod_search",
+		fmt.Sprintf("INSERT INTO mod_search(rowid, %s) SELECT m.id, %s FROM mod m", searchColumns(), searchExprs(", ")),
+	}
+	for name, trigger := range searchTriggers() {
+		statements = append(statements, fmt.Sprintf("CREATE TRIGGER %s %s", name, trigger))
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(h.ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	h.fts.Store(true)
+	return nil
+}
+
+// Search matches the query against the filenames, characters, tags, notes, GameBanana
+// metadata and texture names of the mods, best match first. with fts5 the query supports
+// prefixes like nahi*, AND, OR, NOT, "phrases" and column filters like tags:outfit,
+// a query that is not valid fts5 syntax is searched as plain words
+func (h *DbHelper) Search(game types.Game, query string) ([]types.SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return []types.SearchResult{}, nil
+	}
+
+	var ids []int
+	var ranks []float64
+	var err error
+	if h.fts.Load() {
+		ids, ranks, err = h.matchSearch(game, query)
+		if err != nil {
+			ids, ranks, err = h.matchSearch(game, plainSearchQuery(query))
+		}
+	} else {
+		ids, ranks, err = h.likeSearch(game, query)
+	}
+	if err != nil {
+		return []types.SearchResult{}, err
+	}
+
+	modIds := make([]int64, len(ids))
+	for i, id := range ids {
+		modIds[i] = int64(id)
+	}
+	byId, err := h.selectModsWithTags(modIds)
+	if err != nil {
+		return []types.SearchResult{}, err
+	}
+
+	results := make([]types.SearchResult, 0, len(ids))
+	for i, id := range modIds {
+		if m, ok := byId[id]; ok {
+			results = append(results, types.SearchResult{ModWithTags: *m, Rank: ranks[i]})
+		}
+	}
+	return results, nil
+}
+
+func (h *DbHelper) matchSearch(game types.Game, query string) ([]int, []float64, error) {
+	weights := make([]string, len(searchFields))
+	for i, f := range searchFields {
+		weights[i] = fmt.Sprint(f.weight)
+	}
+
+	rows, err := h.db.QueryContext(h.ctx, fmt.Sprintf(`
+		SELECT m.id, bm25(mod_search, %s) AS rank
+		FROM mod_search
+		JOIN mod m ON m.id = mod_search.rowid
+		WHERE mod_search MATCH ? AND m.game = ?
+		ORDER BY rank
+		LIMIT ?`, strings.Join(weights, ", ")),
+		query, game.Int64(), searchLimit,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	ranks := []float64{}
+	for rows.Next() {
+		var id int
+		var rank float64
+		if err := rows.Scan(&id, &rank); err != nil {
+			return nil, nil, err
+		}
+		// bm25 is lower for better matches
+		ids = append(ids, id)
+		ranks = append(ranks, -rank)
+	}
+	return ids, ranks, rows.Err()
+}
+
+// every word has to be in the mod, words after NOT must not be. OR is not supported
+func (h *DbHelper) likeSearch(game types.Game, query string) ([]int, []float64, error) {
+	conditions := []string{"m.game = ?"}
+	args := []any{game.Int64()}
+
+	not := false
+	for _, word := range searchWords(query) {
+		switch word {
+		case "AND", "OR":
+			continue
+		case "NOT":
+			not = true
+			continue
+		}
+		condition := fmt.Sprintf("(%s) LIKE ?", searchExprs(" || ' ' || "))
+		if not {
+			condition = "NOT " + condition
+			not = false
+		}
+		conditions = append(conditions, condition)
+		args = append(args, "%"+word+"%")
+	}
+	args = append(args, searchLimit)
+
+	rows, err := h.db.QueryContext(h.ctx,
+		"SELECT m.id FROM mod m WHERE "+strings.Join(conditions, " AND ")+" ORDER BY m.fname LIMIT ?",
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	ranks := []float64{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		ranks = append(ranks, 0)
+	}
+	return ids, ranks, rows.Err()
+}
+
+// the words of the query without fts5 syntax, column filters keep only the value
+func searchWords(query string) []string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return r == ' ' || r == '"' || r == '*' || r == '(' || r == ')' || r == '^' || r == '+'
+	})
+	for i, w := range words {
+		if _, value, ok := strings.Cut(w, ":"); ok && value != "" {
+			words[i] = value
+		}
+	}
+	return words
+}
+
+// quotes each word as a prefix so any input is valid fts5
+func plainSearchQuery(query string) string {
+	words := searchWords(query)
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"*`
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,396 @@
+package dbh
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hmm/db"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const SIDECAR_VERSION = 1
+
+// metadata kept in util.SidecarFile inside the mod dir so the
+// library can be copied or rebuilt without hmm.db
+type ModSidecar struct {
+	Version        int      `json:"version"`
+	Enabled        bool     `json:"enabled"`
+	PreviewImages  []string `json:"previewImages"`
+	GbId           int      `json:"gbId"`
+	ModLink        string   `json:"modLink"`
+	GbFileName     string   `json:"gbFileName"`
+	GbDownloadLink string   `json:"gbDownloadLink"`
+	Tags           []string `json:"tags"`
+	// dependencies are stored by name, ids are only valid in one db
+	Dependencies []SidecarDependency `json:"dependencies,omitempty"`
+	Favorite     bool                `json:"favorite,omitempty"`
+	Rating       int                 `json:"rating,omitempty"`
+	Notes        string              `json:"notes,omitempty"`
+	// row id the sidecar was written for, used by sync to follow renamed dirs
+	Id int `json:"id"`
+}
+
+// Character is the category name for category dependencies
+type SidecarDependency struct {
+	Kind      types.DependencyKind `json:"kind"`
+	Character string               `json:"character"`
+	Category  bool                 `json:"category"`
+	Filename  string               `json:"filename,omitempty"`
+}
+
+type TextureSidecar struct {
+	Version        int      `json:"version"`
+	Enabled        bool     `json:"enabled"`
+	PreviewImages  []string `json:"previewImages"`
+	GbId           int      `json:"gbId"`
+	ModLink        string   `json:"modLink"`
+	GbFileName     string   `json:"gbFileName"`
+	GbDownloadLink string   `json:"gbDownloadLink"`
+	// row id the sidecar was written for, used by sync to follow renamed dirs
+	Id int `json:"id"`
+}
+
+type SidecarDao interface {
+	WriteModSidecar(modId int) error
+	WriteTextureSidecar(textureId int) error
+	ApplyModSidecar(modId int, sidecar ModSidecar) error
+	ApplyTextureSidecar(textureId int, sidecar TextureSidecar) error
+	ApplyModSidecarDependencies(modId int, deps []SidecarDependency) error
+}
+
+var _ SidecarDao = (*DbHelper)(nil)
+
+func (s ModSidecar) HasMetadata() bool {
+	return s.GbId != 0 || s.ModLink != "" || len(s.PreviewImages) > 0 || len(s.Tags) > 0 || len(s.Dependencies) > 0 ||
+		s.Favorite || s.Rating != 0 || s.Notes != ""
+}
+
+func (s TextureSidecar) HasMetadata() bool {
+	return s.GbId != 0 || s.ModLink != "" || len(s.PreviewImages) > 0
+}
+
+func ReadModSidecar(dir string) (ModSidecar, error) {
+	return readSidecar[ModSidecar](dir)
+}
+
+func ReadTextureSidecar(dir string) (TextureSidecar, error) {
+	return readSidecar[TextureSidecar](dir)
+}
+
+func readSidecar[T any](dir string) (T, error) {
+	var sidecar T
+	b, err := os.ReadFile(filepath.Join(dir, util.SidecarFile))
+	if err != nil {
+		return sidecar, err
+	}
+	err = json.Unmarshal(b, &sidecar)
+	return sidecar, err
+}
+
+// only writes when the dir exists and the content changed
+func writeSidecar(dir string, sidecar any) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, util.SidecarFile)
+	if prev, err := os.ReadFile(path); err == nil && bytes.Equal(prev, b) {
+		return nil
+	}
+
+	return os.WriteFile(path, b, 0666)
+}
+
+func sidecarExists(dir string) bool {
+	exists, _ := util.FileExists(filepath.Join(dir, util.SidecarFile))
+	return exists
+}
+
+func (h *DbHelper) WriteModSidecar(modId int) error {
+	mod, err := h.SelectModById(modId)
+	if err != nil {
+		return err
+	}
+	tags, err := h.SelectTagsByModId(int64(modId))
+	if err != nil {
+		return err
+	}
+
+	tagNames := make([]string, len(tags))
+	for i, t := range tags {
+		tagNames[i] = t.Name
+	}
+
+	deps, err := h.sidecarDependencies(mod)
+	if err != nil {
+		return err
+	}
+
+	return writeSidecar(util.GetModDir(mod), newModSidecar(mod, tagNames, deps))
+}
+
+func newModSidecar(mod types.Mod, tags []string, deps []SidecarDependency) ModSidecar {
+	return ModSidecar{
+		Version:        SIDECAR_VERSION,
+		Id:             mod.Id,
+		Enabled:        mod.Enabled,
+		PreviewImages:  mod.PreviewImages,
+		GbId:           mod.GbId,
+		ModLink:        mod.ModLink,
+		GbFileName:     mod.GbFileName,
+		GbDownloadLink: mod.GbDownloadLink,
+		Tags:           tags,
+		Dependencies:   deps,
+		Favorite:       mod.Favorite,
+		Rating:         mod.Rating,
+		Notes:          mod.Notes,
+	}
+}
+
+// dependencies detected from the ini files are left out, they are detected again on sync.
+// dependencies of the previous sidecar that do not match a row are kept so they are
+// restored once the mod they point to is added
+func (h *DbHelper) sidecarDependencies(mod types.Mod) ([]SidecarDependency, error) {
+	rows, err := h.SelectModDependencies(mod.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []SidecarDependency{}
+	for _, row := range rows {
+		if row.Source == types.DEPENDENCY_SOURCE_INI {
+			continue
+		}
+
+		switch row.Kind {
+		case types.DEPENDENCY_MOD:
+			dep, err := h.SelectModById(row.DependsOn)
+			if err != nil {
+				continue
+			}
+			deps = append(deps, SidecarDependency{Kind: row.Kind, Character: dep.Character, Category: dep.Category, Filename: dep.Filename})
+		case types.DEPENDENCY_CATEGORY:
+			dep, err := h.SelectCharacterById(row.DependsOn, mod.Game)
+			if err != nil {
+				continue
+			}
+			deps = append(deps, SidecarDependency{Kind: row.Kind, Character: dep.Name, Category: true})
+		}
+	}
+
+	if prev, err := ReadModSidecar(util.GetModDir(mod)); err == nil {
+		for _, dep := range prev.Dependencies {
+			if _, ok := h.resolveSidecarDependency(mod.Game, dep); !ok && !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	return deps, nil
+}
+
+// returns the id of the mod or category the dependency points to
+func (h *DbHelper) resolveSidecarDependency(game types.Game, dep SidecarDependency) (int, bool) {
+	switch dep.Kind {
+	case types.DEPENDENCY_MOD:
+		m, err := h.SelectModByFileCharacterGame(dep.Filename, dep.Character, game)
+		if err != nil || m.Category != dep.Category {
+			return 0, false
+		}
+		return m.Id, true
+	case types.DEPENDENCY_CATEGORY:
+		categories, _ := h.SelectCategoriesByGame(game)
+		i := slices.IndexFunc(categories, func(c types.Character) bool { return c.Name == dep.Character })
+		if i == -1 {
+			return 0, false
+		}
+		return categories[i].Id, true
+	}
+	return 0, false
+}
+
+// ApplyModSidecarDependencies adds the dependencies of the sidecar that match a row,
+// called by sync after all mods of the game have rows
+func (h *DbHelper) ApplyModSidecarDependencies(modId int, deps []SidecarDependency) error {
+	mod, err := h.SelectModById(modId)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range deps {
+		id, ok := h.resolveSidecarDependency(mod.Game, dep)
+		if !ok || (dep.Kind == types.DEPENDENCY_MOD && id == modId) {
+			continue
+		}
+		err := h.queries.InsertModDependency(h.ctx, db.InsertModDependencyParams{
+			ModId:  int64(modId),
+			Kind:   int64(dep.Kind),
+			DepId:  int64(id),
+			Source: int64(types.DEPENDENCY_SOURCE_SIDECAR),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DbHelper) WriteTextureSidecar(textureId int) error {
+	texture, err := h.SelectTextureById(textureId)
+	if err != nil {
+		return err
+	}
+	mod, err := h.SelectModById(texture.ModId)
+	if err != nil {
+		return err
+	}
+
+	return writeSidecar(filepath.Join(util.GetModDir(mod), "textures", texture.Filename), newTextureSidecar(texture))
+}
+
+func newTextureSidecar(texture types.Texture) TextureSidecar {
+	return TextureSidecar{
+		Version:        SIDECAR_VERSION,
+		Id:             texture.Id,
+		Enabled:        texture.Enabled,
+		PreviewImages:  texture.PreviewImages,
+		GbId:           texture.GbId,
+		ModLink:        texture.ModLink,
+		GbFileName:     texture.GbFileName,
+		GbDownloadLink: texture.GbDownloadLink,
+	}
+}
+
+// sidecar writes never fail the db update, the next sync rewrites missing files
+func (h *DbHelper) writeModSidecars(ids ...int) {
+	for _, id := range ids {
+		if err := h.WriteModSidecar(id); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.LogErrorf("failed to write sidecar for mod %d: %s", id, err.Error())
+		}
+	}
+}
+
+func (h *DbHelper) writeTextureSidecars(ids ...int) {
+	for _, id := range ids {
+		if err := h.WriteTextureSidecar(id); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.LogErrorf("failed to write sidecar for texture %d: %s", id, err.Error())
+		}
+	}
+}
+
+// writes the sidecars of mods changed by a bulk update, the rows and tags are
+// loaded with one query each
+func (h *DbHelper) writeModsSidecars(ids []int64) {
+	if len(ids) == 0 {
+		return
+	}
+	mods, err := h.queries.SelectModsByIds(h.ctx, ids)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+	tags, err := h.queries.SelectTagsByModIds(h.ctx, ids)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+
+	tagNames := make(map[int64][]string, len(mods))
+	for _, t := range tags {
+		tagNames[t.ModID] = append(tagNames[t.ModID], t.TagName)
+	}
+	for _, m := range mods {
+		mod := modFromDb(m)
+		deps, err := h.sidecarDependencies(mod)
+		if err == nil {
+			err = writeSidecar(util.GetModDir(mod), newModSidecar(mod, append([]string{}, tagNames[m.ID]...), deps))
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.LogErrorf("failed to write sidecar for mod %d: %s", mod.Id, err.Error())
+		}
+	}
+}
+
+// ApplyModSidecar overwrites the metadata of the row with the sidecar and adds its tags
+func (h *DbHelper) ApplyModSidecar(modId int, sidecar ModSidecar) error {
+	err := h.withTransaction(func(q *db.Queries) error {
+		return h.applyModSidecar(q, modId, sidecar)
+	})
+	if err != nil {
+		return err
+	}
+
+	h.writeModSidecars(modId)
+	return nil
+}
+
+func (h *DbHelper) applyModSidecar(q *db.Queries, modId int, sidecar ModSidecar) error {
+	err := q.UpdateModMetadata(h.ctx, db.UpdateModMetadataParams{
+		Selected:       sidecar.Enabled,
+		PreviewImages:  strings.Join(sidecar.PreviewImages, "<seperator>"),
+		GbId:           sql.NullInt64{Valid: sidecar.GbId != 0, Int64: int64(sidecar.GbId)},
+		ModLink:        sql.NullString{Valid: sidecar.ModLink != "", String: sidecar.ModLink},
+		GbFilename:     sql.NullString{Valid: sidecar.GbFileName != "", String: sidecar.GbFileName},
+		GbDownloadLink: sql.NullString{Valid: sidecar.GbDownloadLink != "", String: sidecar.GbDownloadLink},
+		ID:             int64(modId),
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.UpdateModFavorite(h.ctx, db.UpdateModFavoriteParams{Favorite: sidecar.Favorite, ID: int64(modId)}); err != nil {
+		return err
+	}
+	if err := q.UpdateModRating(h.ctx, db.UpdateModRatingParams{Rating: int64(sidecar.Rating), ID: int64(modId)}); err != nil {
+		return err
+	}
+	if err := q.UpdateModNotes(h.ctx, db.UpdateModNotesParams{Notes: sidecar.Notes, ID: int64(modId)}); err != nil {
+		return err
+	}
+
+	for _, tag := range sidecar.Tags {
+		err := q.InsertTag(h.ctx, db.InsertTagParams{
+			TagName: tag,
+			ModId:   int64(modId),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DbHelper) ApplyTextureSidecar(textureId int, sidecar TextureSidecar) error {
+	if err := h.applyTextureSidecar(h.queries, textureId, sidecar); err != nil {
+		return err
+	}
+
+	h.writeTextureSidecars(textureId)
+	return nil
+}
+
+func (h *DbHelper) applyTextureSidecar(q *db.Queries, textureId int, sidecar TextureSidecar) error {
+	return q.UpdateTextureMetadata(h.ctx, db.UpdateTextureMetadataParams{
+		Selected:       sidecar.Enabled,
+		PreviewImages:  strings.Join(sidecar.PreviewImages, "<seperator>"),
+		GbId:           sql.NullInt64{Valid: sidecar.GbId != 0, Int64: int64(sidecar.GbId)},
+		ModLink:        sql.NullString{Valid: sidecar.ModLink != "", String: sidecar.ModLink},
+		GbFilename:     sql.NullString{Valid: sidecar.GbFileName != "", String: sidecar.GbFileName},
+		GbDownloadLink: sql.NullString{Valid: sidecar.GbDownloadLink != "", String: sidecar.GbDownloadLink},
+		ID:             int64(textureId),
+	})
+}
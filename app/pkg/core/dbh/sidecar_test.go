@@ -0,0 +1,137 @@
+package dbh
+
+import (
+	"database/sql"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newTestDbHelper(t *testing.T) *DbHelper {
+	ddl, err := os.ReadFile("../../../db/sql/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbSql, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "hmm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbSql.Close() })
+
+	if _, err = dbSql.Exec(string(ddl)); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewDbHelper(dbSql)
+}
+
+func TestModSidecarRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	mod := types.Mod{
+		Filename:      "mod",
+		Game:          types.Genshin,
+		Character:     "Nahida",
+		CharacterId:   1,
+		GbId:          42,
+		ModLink:       "https://gamebanana.com/mods/42",
+		PreviewImages: []string{"a.png"},
+	}
+	if err := os.MkdirAll(util.GetModDir(mod), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestDbHelper(t)
+	id, err := h.InsertMod(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = h.InsertTag("outfit", int(id)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.UpdateModEnabledById(true, int(id)); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecar, err := ReadModSidecar(util.GetModDir(mod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sidecar.GbId != 42 || !sidecar.Enabled || !slices.Equal(sidecar.Tags, []string{"outfit"}) {
+		t.Fatalf("sidecar does not match row %+v", sidecar)
+	}
+
+	// a fresh db picks the metadata back up from the sidecar
+	rebuilt := newTestDbHelper(t)
+	id, err = rebuilt.InsertMod(types.Mod{
+		Filename:    mod.Filename,
+		Game:        mod.Game,
+		Character:   mod.Character,
+		CharacterId: mod.CharacterId,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, _ := ReadModSidecar(util.GetModDir(mod)); s.GbId != 42 {
+		t.Fatal("insert overwrote the existing sidecar")
+	}
+
+	if err = rebuilt.ApplyModSidecar(int(id), sidecar); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := rebuilt.SelectModById(int(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GbId != mod.GbId || restored.ModLink != mod.ModLink || !restored.Enabled {
+		t.Errorf("row was not restored %+v", restored)
+	}
+	tags, _ := rebuilt.SelectTagsByModId(id)
+	if len(tags) != 1 || tags[0].Name != "outfit" {
+		t.Errorf("tags were not restored %v", tags)
+	}
+}
+
+func TestBulkEnableOnlyWritesChangedSidecars(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	h := newTestDbHelper(t)
+	ids := []int64{}
+	for _, name := range []string{"changed", "unchanged"} {
+		mod := types.Mod{Filename: name, Game: types.Genshin, Character: "Nahida", CharacterId: 1}
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		id, err := h.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.InsertTag("outfit", int(id))
+		ids = append(ids, id)
+	}
+	unchanged := filepath.Join(root, "Genshin", "Nahida", "unchanged", util.SidecarFile)
+	os.Remove(unchanged)
+
+	if err := h.UpdateModsEnabledFromSlice([]int64{ids[0]}, types.Genshin); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecar, err := ReadModSidecar(filepath.Join(root, "Genshin", "Nahida", "changed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sidecar.Enabled || !slices.Equal(sidecar.Tags, []string{"outfit"}) {
+		t.Errorf("sidecar of the enabled mod was not written %+v", sidecar)
+	}
+	if _, err := os.Stat(unchanged); err == nil {
+		t.Error("sidecar of a mod that did not change was written")
+	}
+}
@@ -0,0 +1,328 @@
+package dbh
+
+import (
+	"hmm/db"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"path/filepath"
+	"time"
+)
+
+type PendingDeleteKind int
+
+const (
+	PENDING_DELETE_MOD     PendingDeleteKind = 0
+	PENDING_DELETE_TEXTURE PendingDeleteKind = 1
+)
+
+// a row that sync could not find on disk but holds user data
+type PendingDelete struct {
+	Kind  PendingDeleteKind
+	Id    int
+	Since time.Time
+}
+
+type SyncDao interface {
+	InsertPendingDelete(kind PendingDeleteKind, id int, game types.Game, since time.Time) error
+	SelectPendingDeletes(game types.Game) ([]PendingDelete, error)
+	DeletePendingDelete(kind PendingDeleteKind, id int) error
+	SelectModIdsWithUserData(game types.Game) ([]int, error)
+	SelectTextureIdsWithUserData(game types.Game) ([]int, error)
+	ApplySyncBatch(batch *SyncBatch) error
+}
+
+var _ SyncDao = (*DbHelper)(nil)
+
+// keeps the since time of an existing entry
+func (h *DbHelper) InsertPendingDelete(kind PendingDeleteKind, id int, game types.Game, since time.Time) error {
+	return h.queries.InsertPendingDelete(h.ctx, db.InsertPendingDeleteParams{
+		Kind:  int64(kind),
+		RowId: int64(id),
+		Game:  game.Int64(),
+		Since: since.Unix(),
+	})
+}
+
+func (h *DbHelper) SelectPendingDeletes(game types.Game) ([]PendingDelete, error) {
+	rows, err := h.queries.SelectPendingDeletesByGame(h.ctx, game.Int64())
+	if err != nil {
+		return make([]PendingDelete, 0), err
+	}
+
+	result := make([]PendingDelete, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, PendingDelete{
+			Kind:  PendingDeleteKind(row.Kind),
+			Id:    int(row.RowID),
+			Since: time.Unix(row.Since, 0),
+		})
+	}
+	return result, nil
+}
+
+func (h *DbHelper) DeletePendingDelete(kind PendingDeleteKind, id int) error {
+	return h.queries.DeletePendingDelete(h.ctx, db.DeletePendingDeleteParams{
+		Kind:  int64(kind),
+		RowId: int64(id),
+	})
+}
+
+// mods that have gamebanana data, tags, playlists, ini cache or texture data
+func (h *DbHelper) SelectModIdsWithUserData(game types.Game) ([]int, error) {
+	ids, err := h.queries.SelectModIdsWithUserData(h.ctx, game.Int64())
+	return toIntSlice(ids), err
+}
+
+func (h *DbHelper) SelectTextureIdsWithUserData(game types.Game) ([]int, error) {
+	ids, err := h.queries.SelectTextureIdsWithUserData(h.ctx, game.Int64())
+	return toIntSlice(ids), err
+}
+
+func toIntSlice(ids []int64) []int {
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int(id))
+	}
+	return result
+}
+
+// SyncBatch holds every row change found by a sync so they are written in one transaction
+type SyncBatch struct {
+	Game types.Game
+	// new mod dirs, the ids of the mods and their textures are set when applied
+	Mods []SyncMod
+	// new texture dirs of existing mods
+	Textures []SyncTexture
+	// rows of mods whose dir was renamed or moved to another character, with the new location
+	Moved           []types.Mod
+	RenamedTextures []types.Texture
+	Fingerprints    map[int]string
+	Sizes           map[int]int64
+	// sidecars with metadata applied to existing rows
+	ModSidecars     map[int]ModSidecar
+	TextureSidecars map[int]TextureSidecar
+	InsertPending   []PendingDelete
+	DeletePending   []PendingDelete
+	DeleteMods      []int
+	DeleteTextures  []int
+}
+
+type SyncMod struct {
+	Mod         types.Mod
+	Fingerprint string
+	// read from the dir, without one a new sidecar is written
+	Sidecar  *ModSidecar
+	Textures []SyncTexture
+}
+
+type SyncTexture struct {
+	Texture types.Texture
+	Sidecar *TextureSidecar
+}
+
+func NewSyncBatch(game types.Game) *SyncBatch {
+	return &SyncBatch{
+		Game:            game,
+		Fingerprints:    map[int]string{},
+		Sizes:           map[int]int64{},
+		ModSidecars:     map[int]ModSidecar{},
+		TextureSidecars: map[int]TextureSidecar{},
+	}
+}
+
+// ApplySyncBatch writes the rows of the batch in a single transaction, sidecars are
+// written once it is committed
+func (h *DbHelper) ApplySyncBatch(batch *SyncBatch) error {
+	err := h.withTransaction(func(q *db.Queries) error {
+		for i := range batch.Mods {
+			if err := h.insertSyncMod(q, &batch.Mods[i]); err != nil {
+				return err
+			}
+		}
+		for i := range batch.Textures {
+			if err := h.insertSyncTexture(q, &batch.Textures[i]); err != nil {
+				return err
+			}
+		}
+
+		for _, mod := range batch.Moved {
+			flags := int64(0)
+			if mod.Category {
+				flags = MOD_FLAG_CATEGORY
+			}
+			err := q.UpdateModLocation(h.ctx, db.UpdateModLocationParams{
+				Fname:        mod.Filename,
+				CharName:     mod.Character,
+				CharId:       int64(mod.CharacterId),
+				CategoryFlag: flags,
+				ID:           int64(mod.Id),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, texture := range batch.RenamedTextures {
+			err := q.UpdateTextureNameById(h.ctx, db.UpdateTextureNameByIdParams{
+				Fname: texture.Filename,
+				ID:    int64(texture.Id),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for id, fingerprint := range batch.Fingerprints {
+			if err := q.UpdateModFingerprint(h.ctx, db.UpdateModFingerprintParams{Fingerprint: fingerprint, ID: int64(id)}); err != nil {
+				return err
+			}
+		}
+		for id, size := range batch.Sizes {
+			if err := q.UpdateModSize(h.ctx, db.UpdateModSizeParams{Size: size, ID: int64(id)}); err != nil {
+				return err
+			}
+		}
+		for id, sidecar := range batch.ModSidecars {
+			if err := h.applyModSidecar(q, id, sidecar); err != nil {
+				return err
+			}
+		}
+		for id, sidecar := range batch.TextureSidecars {
+			if err := h.applyTextureSidecar(q, id, sidecar); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range batch.InsertPending {
+			err := q.InsertPendingDelete(h.ctx, db.InsertPendingDeleteParams{
+				Kind:  int64(p.Kind),
+				RowId: int64(p.Id),
+				Game:  batch.Game.Int64(),
+				Since: p.Since.Unix(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, p := range batch.DeletePending {
+			if err := q.DeletePendingDelete(h.ctx, db.DeletePendingDeleteParams{Kind: int64(p.Kind), RowId: int64(p.Id)}); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range batch.DeleteTextures {
+			if err := q.DeleteTextureById(h.ctx, int64(id)); err != nil {
+				return err
+			}
+		}
+		return h.deleteModRows(q, batch.DeleteMods)
+	})
+	if err != nil {
+		return err
+	}
+
+	h.writeSyncSidecars(batch)
+	return nil
+}
+
+func (h *DbHelper) insertSyncMod(q *db.Queries, m *SyncMod) error {
+	id, err := q.InsertMod(h.ctx, insertModParams(m.Mod))
+	if err != nil {
+		return err
+	}
+	m.Mod.Id = int(id)
+
+	if m.Fingerprint != "" {
+		if err := q.UpdateModFingerprint(h.ctx, db.UpdateModFingerprintParams{Fingerprint: m.Fingerprint, ID: id}); err != nil {
+			return err
+		}
+	}
+	if m.Mod.Size != 0 {
+		if err := q.UpdateModSize(h.ctx, db.UpdateModSizeParams{Size: m.Mod.Size, ID: id}); err != nil {
+			return err
+		}
+	}
+	if m.Sidecar != nil {
+		if err := h.applyModSidecar(q, m.Mod.Id, *m.Sidecar); err != nil {
+			return err
+		}
+	}
+
+	for i := range m.Textures {
+		m.Textures[i].Texture.ModId = m.Mod.Id
+		if err := h.insertSyncTexture(q, &m.Textures[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DbHelper) insertSyncTexture(q *db.Queries, t *SyncTexture) error {
+	id, err := q.InsertTexture(h.ctx, insertTextureParams(t.Texture))
+	if err != nil {
+		return err
+	}
+	t.Texture.Id = int(id)
+
+	if t.Sidecar != nil {
+		return h.applyTextureSidecar(q, t.Texture.Id, *t.Sidecar)
+	}
+	return nil
+}
+
+// new rows only hold what the batch inserted so their sidecars are written without
+// reading them back, dirs with a sidecar that could not be read are left untouched
+func (h *DbHelper) writeSyncSidecars(batch *SyncBatch) {
+	write := func(dir string, sidecar any) {
+		if err := writeSidecar(dir, sidecar); err != nil {
+			log.LogErrorf("failed to write sidecar for %s: %s", dir, err.Error())
+		}
+	}
+	writeTexture := func(mod types.Mod, t SyncTexture) {
+		dir := filepath.Join(util.GetModDir(mod), "textures", t.Texture.Filename)
+		if t.Sidecar != nil {
+			sidecar := *t.Sidecar
+			sidecar.Version = SIDECAR_VERSION
+			write(dir, sidecar)
+		} else if !sidecarExists(dir) {
+			t.Texture.PreviewImages = []string{}
+			write(dir, newTextureSidecar(t.Texture))
+		}
+	}
+
+	for _, m := range batch.Mods {
+		dir := util.GetModDir(m.Mod)
+		if m.Sidecar != nil {
+			sidecar := *m.Sidecar
+			sidecar.Version = SIDECAR_VERSION
+			sidecar.Id = m.Mod.Id
+			write(dir, sidecar)
+		} else if !sidecarExists(dir) {
+			m.Mod.PreviewImages = []string{}
+			write(dir, newModSidecar(m.Mod, []string{}, nil))
+		}
+		for _, t := range m.Textures {
+			writeTexture(m.Mod, t)
+		}
+	}
+
+	mods := map[int]types.Mod{}
+	for _, t := range batch.Textures {
+		mod, ok := mods[t.Texture.ModId]
+		if !ok {
+			var err error
+			if mod, err = h.SelectModById(t.Texture.ModId); err != nil {
+				continue
+			}
+			mods[mod.Id] = mod
+		}
+		writeTexture(mod, t)
+	}
+
+	for id := range batch.ModSidecars {
+		h.writeModSidecars(id)
+	}
+	for id := range batch.TextureSidecars {
+		h.writeTextureSidecars(id)
+	}
+}
@@ -3,6 +3,7 @@ package dbh
 import (
 	"hmm/db"
 	"hmm/pkg/types"
+	"slices"
 )
 
 type TagDao interface {
@@ -16,17 +17,59 @@ type TagDao interface {
 var _ TagDao = (*DbHelper)(nil)
 
 func (h *DbHelper) InsertTag(name string, modId int) error {
-	return h.queries.InsertTag(h.ctx, db.InsertTagParams{
+	tags, err := h.SelectTagsByModId(int64(modId))
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(tags, func(t types.Tag) bool { return t.Name == name }) {
+		return nil
+	}
+
+	if err := h.insertTag(name, modId); err != nil {
+		return err
+	}
+	h.record(
+		"Add tag "+name,
+		func() error { return h.deleteTag(name, modId) },
+		func() error { return h.insertTag(name, modId) },
+	)
+	return nil
+}
+
+func (h *DbHelper) insertTag(name string, modId int) error {
+	err := h.queries.InsertTag(h.ctx, db.InsertTagParams{
 		TagName: name,
 		ModId:   int64(modId),
 	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(modId)
+	return nil
 }
 
 func (h *DbHelper) DeleteTag(name string, modId int) error {
-	return h.queries.DeleteTag(h.ctx, db.DeleteTagParams{
+	if err := h.deleteTag(name, modId); err != nil {
+		return err
+	}
+	h.record(
+		"Remove tag "+name,
+		func() error { return h.insertTag(name, modId) },
+		func() error { return h.deleteTag(name, modId) },
+	)
+	return nil
+}
+
+func (h *DbHelper) deleteTag(name string, modId int) error {
+	err := h.queries.DeleteTag(h.ctx, db.DeleteTagParams{
 		Name:  name,
 		ModId: int64(modId),
 	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(modId)
+	return nil
 }
 
 func (h *DbHelper) SelectTagsByModId(modId int64) ([]types.Tag, error) {
@@ -47,33 +90,96 @@ func (h *DbHelper) SelectTagsByModId(modId int64) ([]types.Tag, error) {
 }
 
 func (h *DbHelper) UpdateTagName(old, new string, modId int) error {
-	return h.queries.UpdateTagName(h.ctx, db.UpdateTagNameParams{
+	if err := h.updateTagName(old, new, modId); err != nil {
+		return err
+	}
+	h.record(
+		"Rename tag "+old,
+		func() error { return h.updateTagName(new, old, modId) },
+		func() error { return h.updateTagName(old, new, modId) },
+	)
+	return nil
+}
+
+func (h *DbHelper) updateTagName(old, new string, modId int) error {
+	err := h.queries.UpdateTagName(h.ctx, db.UpdateTagNameParams{
 		UpdatedName: new,
 		ID:          int64(modId),
 		OldName:     old,
 	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(modId)
+	return nil
 }
 
 func (h *DbHelper) InsertTagForAllModsByCharacterIds(ids []int64, tagname string, game types.Game) error {
-	return h.withTransaction(func(q *db.Queries) error {
+	// only the mods that did not have the tag are untagged by undo
+	added := []int{}
+	err := h.withTransaction(func(q *db.Queries) error {
 		for _, cid := range ids {
 			mods, err := q.SelectModsByCharacterId(h.ctx, db.SelectModsByCharacterIdParams{
-				Name: cid,
-				Game: game.Int64(),
+				CharId: cid,
+				Game:   game.Int64(),
 			})
 			if err != nil {
 				return err
 			}
 			for _, m := range mods {
-				err := q.InsertTag(h.ctx, db.InsertTagParams{
+				tags, err := q.SelectTagsByModId(h.ctx, m.ID)
+				if err != nil {
+					return err
+				}
+				if slices.ContainsFunc(tags, func(t db.Tag) bool { return t.TagName == tagname }) {
+					continue
+				}
+
+				err = q.InsertTag(h.ctx, db.InsertTagParams{
 					TagName: tagname,
 					ModId:   m.ID,
 				})
 				if err != nil {
 					return err
 				}
+				added = append(added, int(m.ID))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(added...)
+
+	if len(added) > 0 {
+		h.record(
+			"Add tag "+tagname+" to all mods",
+			func() error { return h.setTagForMods(tagname, added, false) },
+			func() error { return h.setTagForMods(tagname, added, true) },
+		)
+	}
+	return nil
+}
+
+func (h *DbHelper) setTagForMods(name string, modIds []int, tagged bool) error {
+	err := h.withTransaction(func(q *db.Queries) error {
+		for _, id := range modIds {
+			var err error
+			if tagged {
+				err = q.InsertTag(h.ctx, db.InsertTagParams{TagName: name, ModId: int64(id)})
+			} else {
+				err = q.DeleteTag(h.ctx, db.DeleteTagParams{Name: name, ModId: int64(id)})
+			}
+			if err != nil {
+				return err
 			}
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	h.writeModSidecars(modIds...)
+	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"hmm/db"
 	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"path/filepath"
 	"slices"
 	"strings"
 )
@@ -14,8 +16,10 @@ type TextureDao interface {
 	SelectEnabledTexturesByModId(id int) ([]types.Texture, error)
 	SelectTextureById(id int) (types.Texture, error)
 	UpdateTextureEnabledById(id int, enabled bool) error
+	SelectTexturesByGame(game types.Game) ([]types.Texture, error)
 	DeleteUnusedTextureFromMap(modIdtoTexFiles map[int][]string) error
-	updateTextureName(id int, name string) error
+	DeleteTexturesByIds(ids []int) error
+	UpdateTextureName(id int, name string) error
 }
 
 var _ TextureDao = (*DbHelper)(nil)
@@ -38,8 +42,23 @@ func textureFromDb(t db.Texture) types.Texture {
 	}
 }
 
+// a sidecar already in the texture dir is left for sync to apply
 func (h *DbHelper) InsertTexture(t types.Texture) (int64, error) {
-	return h.queries.InsertTexture(h.ctx, db.InsertTextureParams{
+	id, err := h.queries.InsertTexture(h.ctx, insertTextureParams(t))
+	if err != nil {
+		return id, err
+	}
+
+	if mod, err := h.SelectModById(t.ModId); err == nil {
+		if !sidecarExists(filepath.Join(util.GetModDir(mod), "textures", t.Filename)) {
+			h.writeTextureSidecars(int(id))
+		}
+	}
+	return id, nil
+}
+
+func insertTextureParams(t types.Texture) db.InsertTextureParams {
+	return db.InsertTextureParams{
 		ModFilename:    t.Filename,
 		ModId:          int64(t.ModId),
 		Selected:       t.Enabled,
@@ -48,7 +67,7 @@ func (h *DbHelper) InsertTexture(t types.Texture) (int64, error) {
 		ModLink:        sql.NullString{Valid: t.ModLink != "", String: t.ModLink},
 		GbFilename:     sql.NullString{Valid: t.GbFileName != "", String: t.GbFileName},
 		GbDownloadLink: sql.NullString{Valid: t.GbDownloadLink != "", String: t.GbDownloadLink},
-	})
+	}
 }
 
 func (h *DbHelper) DeleteUnusedTextureFromMap(modIdtoTexFiles map[int][]string) error {
@@ -103,7 +122,34 @@ func (h *DbHelper) SelectEnabledTexturesByModId(id int) ([]types.Texture, error)
 	return result, nil
 }
 
-func (h *DbHelper) updateTextureName(id int, name string) error {
+func (h *DbHelper) SelectTexturesByGame(game types.Game) ([]types.Texture, error) {
+	textures, err := h.queries.SelectTexturesByGame(h.ctx, game.Int64())
+	if err != nil {
+		return make([]types.Texture, 0), err
+	}
+
+	result := make([]types.Texture, 0, len(textures))
+
+	for _, t := range textures {
+		result = append(result, textureFromDb(t))
+	}
+
+	return result, nil
+}
+
+// only deletes the rows, files are left untouched
+func (h *DbHelper) DeleteTexturesByIds(ids []int) error {
+	return h.withTransaction(func(q *db.Queries) error {
+		for _, id := range ids {
+			if err := q.DeleteTextureById(h.ctx, int64(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (h *DbHelper) UpdateTextureName(id int, name string) error {
 	return h.queries.UpdateTextureNameById(h.ctx, db.UpdateTextureNameByIdParams{
 		Fname: name,
 		ID:    int64(id),
@@ -111,8 +157,35 @@ func (h *DbHelper) updateTextureName(id int, name string) error {
 }
 
 func (h *DbHelper) UpdateTextureEnabledById(id int, enabled bool) error {
-	return h.queries.UpdateTextureEnabledById(h.ctx, db.UpdateTextureEnabledByIdParams{
+	texture, err := h.SelectTextureById(id)
+	if err != nil {
+		return err
+	}
+	if err := h.updateTextureEnabledById(id, enabled); err != nil {
+		return err
+	}
+	if texture.Enabled != enabled {
+		name := "Disable " + texture.Filename
+		if enabled {
+			name = "Enable " + texture.Filename
+		}
+		h.record(
+			name,
+			func() error { return h.updateTextureEnabledById(id, texture.Enabled) },
+			func() error { return h.updateTextureEnabledById(id, enabled) },
+		)
+	}
+	return nil
+}
+
+func (h *DbHelper) updateTextureEnabledById(id int, enabled bool) error {
+	err := h.queries.UpdateTextureEnabledById(h.ctx, db.UpdateTextureEnabledByIdParams{
 		Selected: enabled,
 		ID:       int64(id),
 	})
+	if err != nil {
+		return err
+	}
+	h.writeTextureSidecars(id)
+	return nil
 }
@@ -0,0 +1,111 @@
+package dbh
+
+import (
+	"cmp"
+	"hmm/db"
+	"hmm/pkg/types"
+	"slices"
+	"time"
+)
+
+// mods not exported for this many days are stale when the filter does not set StaleDays
+const defaultStaleDays = 30
+
+type UsageDao interface {
+	RecordGeneration(game types.Game, generated []int, exported []int) error
+	SelectModUsage(filter types.UsageFilter) ([]types.ModUsage, error)
+}
+
+var _ UsageDao = (*DbHelper)(nil)
+
+// RecordGeneration is called after each reload with the enabled set and the mods that were
+// copied, the time the previous set stayed exported is added before the new set is counted
+func (h *DbHelper) RecordGeneration(game types.Game, generated []int, exported []int) error {
+	now := time.Now().Unix()
+	return h.withTransaction(func(q *db.Queries) error {
+		err := q.CloseModUsageByGame(h.ctx, db.CloseModUsageByGameParams{Now: now, Game: game.Int64()})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range generated {
+			err := q.InsertModGenerated(h.ctx, db.InsertModGeneratedParams{ModId: int64(id), ActiveSince: now})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(exported) == 0 {
+			return nil
+		}
+		ids := make([]int64, len(exported))
+		for i, id := range exported {
+			ids[i] = int64(id)
+		}
+		return q.UpdateModsLastExportedAt(h.ctx, db.UpdateModsLastExportedAtParams{LastExportedAt: now, Ids: ids})
+	})
+}
+
+// SelectModUsage returns the usage of the mods matching the filter, recent is sorted by the
+// last export and the other views by least used first
+func (h *DbHelper) SelectModUsage(filter types.UsageFilter) ([]types.ModUsage, error) {
+	rows, err := h.queries.SelectModUsage(h.ctx, db.SelectModUsageParams{
+		Game:    filter.Game.Int64(),
+		CharId:  int64(filter.CharacterId),
+		TagName: filter.Tag,
+	})
+	if err != nil {
+		return []types.ModUsage{}, err
+	}
+
+	now := time.Now().Unix()
+	staleDays := filter.StaleDays
+	if staleDays <= 0 {
+		staleDays = defaultStaleDays
+	}
+	staleBefore := now - int64(staleDays)*int64((time.Hour*24).Seconds())
+
+	usage := make([]types.ModUsage, 0, len(rows))
+	for _, row := range rows {
+		u := types.ModUsage{
+			Mod:            modFromDb(row.Mod),
+			GenerateCount:  int(row.GenerateCount),
+			EnabledSeconds: row.EnabledSeconds,
+			LastExportedAt: row.LastExportedAt,
+		}
+		// the current set is still exported
+		if row.ActiveSince != 0 {
+			u.EnabledSeconds += max(now-row.ActiveSince, 0)
+		}
+
+		switch filter.View {
+		case types.USAGE_RECENT:
+			if u.LastExportedAt == 0 {
+				continue
+			}
+		case types.USAGE_NEVER:
+			if u.GenerateCount != 0 {
+				continue
+			}
+		case types.USAGE_STALE:
+			// mods added recently are not stale before they had a chance to be used
+			last := u.LastExportedAt
+			if last == 0 {
+				last = u.Mod.AddedAt
+			}
+			if row.ActiveSince != 0 || last >= staleBefore {
+				continue
+			}
+		}
+		usage = append(usage, u)
+	}
+
+	if filter.View == types.USAGE_RECENT {
+		slices.SortStableFunc(usage, func(a, b types.ModUsage) int { return cmp.Compare(b.LastExportedAt, a.LastExportedAt) })
+	} else {
+		slices.SortStableFunc(usage, func(a, b types.ModUsage) int {
+			return cmp.Or(cmp.Compare(a.GenerateCount, b.GenerateCount), cmp.Compare(a.LastExportedAt, b.LastExportedAt))
+		})
+	}
+	return usage, nil
+}
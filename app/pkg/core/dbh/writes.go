@@ -0,0 +1,60 @@
+package dbh
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// dirs the app is moving or extracting files into, the library watcher
+// waits for them so it does not sync a dir before its row is saved
+type appWrites struct {
+	mutex sync.Mutex
+	paths map[string]int
+}
+
+// BeginWrite marks the dirs as written by the app until the returned func is called
+func (h *DbHelper) BeginWrite(paths ...string) (done func()) {
+	h.writes.mutex.Lock()
+	defer h.writes.mutex.Unlock()
+
+	if h.writes.paths == nil {
+		h.writes.paths = map[string]int{}
+	}
+	for _, path := range paths {
+		h.writes.paths[filepath.Clean(path)]++
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.writes.mutex.Lock()
+			defer h.writes.mutex.Unlock()
+
+			for _, path := range paths {
+				path = filepath.Clean(path)
+				if h.writes.paths[path]--; h.writes.paths[path] <= 0 {
+					delete(h.writes.paths, path)
+				}
+			}
+		})
+	}
+}
+
+// IsWriting reports whether the path is a dir the app is writing, is inside one or contains one
+func (h *DbHelper) IsWriting(path string) bool {
+	h.writes.mutex.Lock()
+	defer h.writes.mutex.Unlock()
+
+	path = filepath.Clean(path)
+	for writing := range h.writes.paths {
+		if path == writing || isSubPath(writing, path) || isSubPath(path, writing) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSubPath(dir, path string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"hmm/pkg/core/dbh"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mholt/archives"
+)
+
+// matches namespaced references like run = CommandList\global\ORFix\ORFix,
+// the first group is the namespace without the name of the section or variable
+var iniNamespaceReference = regexp.MustCompile(`(?:commandlist|resource|customshader|\$)\\([^\s,=\[\]]+)\\[^\\\s,=\[\]]+`)
+
+// namespaces declared with namespace = and referenced by other sections in the ini
+// files of the mod dir, lowercased with backslashes as separators. archives in the mod
+// dir, like the ones space saver writes, are read without extracting them
+func iniNamespaces(modDir string) (declared []string, referenced []string) {
+	scan := func(r io.Reader) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			if line == "" || strings.HasPrefix(line, ";") {
+				continue
+			}
+
+			if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) == "namespace" {
+				ns := strings.ReplaceAll(strings.TrimSpace(value), "/", "\\")
+				if ns != "" && !slices.Contains(declared, ns) {
+					declared = append(declared, ns)
+				}
+				continue
+			}
+
+			for _, match := range iniNamespaceReference.FindAllStringSubmatch(line, -1) {
+				if !slices.Contains(referenced, match[1]) {
+					referenced = append(referenced, match[1])
+				}
+			}
+		}
+	}
+
+	walkInis(os.DirFS(modDir), scan)
+
+	entries, _ := os.ReadDir(modDir)
+	for _, e := range entries {
+		if e.IsDir() || !isLibraryArchive(e.Name()) {
+			continue
+		}
+		fsys, err := archives.FileSystem(context.Background(), filepath.Join(modDir, e.Name()), nil)
+		if err != nil {
+			log.LogError(err.Error())
+			continue
+		}
+		walkInis(fsys, scan)
+	}
+
+	referenced = slices.DeleteFunc(referenced, func(ns string) bool { return slices.Contains(declared, ns) })
+	return declared, referenced
+}
+
+// calls read with every enabled ini file, metadata dirs are skipped
+func walkInis(fsys fs.FS, read func(io.Reader)) {
+	fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && slices.Contains(util.MetaDataDirs, d.Name()) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		name := strings.ToLower(d.Name())
+		if !strings.HasSuffix(name, ".ini") || strings.HasPrefix(name, "disabled") {
+			return nil
+		}
+
+		file, err := fsys.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+		read(file)
+		return nil
+	})
+}
+
+// DetectDependencies scans the ini files of every mod of the game for
+// references to namespaces declared by other mods
+func (s *SyncHelper) DetectDependencies(game types.Game) error {
+	return s.running[game].SubmitErr(func() error {
+		mods, err := s.db.SelectModsByGame(game)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(mods))
+		for i, m := range mods {
+			ids[i] = m.Id
+		}
+		return s.detectIniDependencies(game, ids)
+	}).Wait()
+}
+
+// the inis of the mods in modIds are read again, other mods use the namespaces cached for
+// their fingerprint. the detected dependencies of the read mods and of the mods that
+// reference a namespace they declare are replaced, so a library installed after the
+// mods that use it is linked to them
+func (s *SyncHelper) detectIniDependencies(game types.Game, modIds []int) error {
+	mods, err := s.db.SelectModsByGame(game)
+	if err != nil {
+		return err
+	}
+	fingerprints, err := s.db.SelectModFingerprints(game)
+	if err != nil {
+		return err
+	}
+	namespaces, err := s.db.SelectModNamespaces(game)
+	if err != nil {
+		return err
+	}
+
+	scanned := []dbh.ModNamespaces{}
+	for _, m := range mods {
+		cached, ok := namespaces[m.Id]
+		if ok && cached.Fingerprint == fingerprints[m.Id] && !slices.Contains(modIds, m.Id) {
+			continue
+		}
+		declared, referenced := iniNamespaces(util.GetModDir(m))
+		ns := dbh.ModNamespaces{
+			ModId:       m.Id,
+			Fingerprint: fingerprints[m.Id],
+			Declared:    declared,
+			Referenced:  referenced,
+		}
+		namespaces[m.Id] = ns
+		scanned = append(scanned, ns)
+	}
+	if err := s.db.SaveModNamespaces(scanned); err != nil {
+		log.LogError(err.Error())
+	}
+
+	providers := map[string][]int{}
+	for _, m := range mods {
+		for _, ns := range namespaces[m.Id].Declared {
+			providers[ns] = append(providers[ns], m.Id)
+		}
+	}
+
+	resolve := map[int]bool{}
+	for _, ns := range scanned {
+		resolve[ns.ModId] = true
+	}
+	for _, m := range mods {
+		for _, ns := range namespaces[m.Id].Referenced {
+			if slices.ContainsFunc(providers[ns], func(id int) bool { return resolve[id] }) {
+				resolve[m.Id] = true
+			}
+		}
+	}
+
+	deps := make(map[int][]types.ModDependency, len(resolve))
+	for id := range resolve {
+		deps[id] = []types.ModDependency{}
+		for _, ns := range namespaces[id].Referenced {
+			for _, provider := range providers[ns] {
+				if provider != id {
+					deps[id] = append(deps[id], types.ModDependency{ModId: id, Kind: types.DEPENDENCY_MOD, DependsOn: provider})
+				}
+			}
+		}
+	}
+	return s.db.ReplaceIniDependencies(deps)
+}
+
+// runs after every mod dir of the pass has a row so dependencies between
+// mods added in the same pass are found
+func (s *SyncHelper) syncDependencies(pass *syncPass) {
+	changed := []int{}
+	for _, entries := range [][]SyncReportEntry{pass.report.Added, pass.report.Renamed, pass.report.Repaired} {
+		for _, e := range entries {
+			if e.TextureId == 0 && !slices.Contains(changed, e.ModId) {
+				changed = append(changed, e.ModId)
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	mods, err := s.db.SelectModsByGame(pass.game)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+	modsById := make(map[int]types.Mod, len(mods))
+	for _, m := range mods {
+		modsById[m.Id] = m
+	}
+
+	for _, id := range changed {
+		mod, ok := modsById[id]
+		if !ok {
+			continue
+		}
+		sidecar, err := dbh.ReadModSidecar(util.GetModDir(mod))
+		if err != nil || len(sidecar.Dependencies) == 0 {
+			continue
+		}
+		if err := s.db.ApplyModSidecarDependencies(id, sidecar.Dependencies); err != nil {
+			log.LogError(err.Error())
+		}
+	}
+
+	if err := s.detectIniDependencies(pass.game, changed); err != nil {
+		log.LogError(err.Error())
+	}
+}
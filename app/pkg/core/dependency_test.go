@@ -0,0 +1,138 @@
+package core
+
+import (
+	"archive/zip"
+	"hmm/pkg/core/dbh"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIniNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "mod.ini"), []byte(`
+namespace = mods\furina
+; run = CommandList\commented\Out
+[TextureOverrideBody]
+run = CommandList\global\ORFix\ORFix
+run = CommandListLocal
+ResourceRef = ref Resource\mods\furina\Shared
+`), 0666)
+	os.WriteFile(filepath.Join(dir, "DISABLED_old.ini"), []byte(`run = CommandList\disabled\Mod\Run`), 0666)
+
+	declared, referenced := iniNamespaces(dir)
+	if !slices.Equal(declared, []string{"mods\\furina"}) {
+		t.Errorf("unexpected declared namespaces %v", declared)
+	}
+	if !slices.Equal(referenced, []string{"global\\orfix"}) {
+		t.Errorf("unexpected referenced namespaces %v", referenced)
+	}
+}
+
+func TestIniNamespacesInArchive(t *testing.T) {
+	dir := t.TempDir()
+	f, _ := os.Create(filepath.Join(dir, "orfix.zip"))
+	w := zip.NewWriter(f)
+	ini, _ := w.Create("ORFix/ORFix.ini")
+	ini.Write([]byte(`namespace = global\ORFix`))
+	w.Close()
+	f.Close()
+
+	declared, _ := iniNamespaces(dir)
+	if !slices.Equal(declared, []string{"global\\orfix"}) {
+		t.Errorf("expected the namespace in the archive got %v", declared)
+	}
+}
+
+func TestProviderInstalledLaterIsLinked(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	s.db.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Furina"})
+	s.db.UpsertCharacter(types.Character{Id: 2, Game: types.Genshin, Name: "Libraries", Category: types.CATEGORY_OTHER})
+
+	insert := func(mod types.Mod, ini string, content string) int {
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		os.WriteFile(filepath.Join(util.GetModDir(mod), ini), []byte(content), 0666)
+		id, err := s.db.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int(id)
+	}
+
+	skin := insert(types.Mod{Filename: "skin", Game: types.Genshin, Character: "Furina", CharacterId: 1, Enabled: true}, "skin.ini", `run = CommandList\global\ORFix\ORFix`)
+	if err := s.detectIniDependencies(types.Genshin, []int{skin}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the skin ini is cached, removing it shows that only the new mod is read
+	os.Remove(filepath.Join(root, "Genshin", "Furina", "skin", "skin.ini"))
+	orfix := insert(types.Mod{Filename: "orfix", Game: types.Genshin, Character: "Libraries", CharacterId: 2, Category: true}, "ORFix.ini", `namespace = global\ORFix`)
+	if err := s.detectIniDependencies(types.Genshin, []int{orfix}); err != nil {
+		t.Fatal(err)
+	}
+
+	needing, _ := s.db.SelectModsNeeding(orfix)
+	if len(needing) != 1 || needing[0].Id != skin {
+		t.Errorf("expected the skin to need orfix got %+v", needing)
+	}
+}
+
+func TestDependenciesAreExported(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	s.db.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Furina"})
+	s.db.UpsertCharacter(types.Character{Id: 2, Game: types.Genshin, Name: "Libraries", Category: types.CATEGORY_OTHER})
+
+	ids := map[string]int{}
+	for _, mod := range []types.Mod{
+		{Filename: "skin", Game: types.Genshin, Character: "Furina", CharacterId: 1, Enabled: true},
+		{Filename: "orfix", Game: types.Genshin, Character: "Libraries", CharacterId: 2, Category: true},
+	} {
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		id, err := s.db.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[mod.Filename] = int(id)
+	}
+	os.WriteFile(filepath.Join(root, "Genshin", "Furina", "skin", "skin.ini"), []byte(`run = CommandList\global\ORFix\ORFix`), 0666)
+	os.WriteFile(filepath.Join(root, "Genshin", util.CategoriesDir, "Libraries", "orfix", "ORFix.ini"), []byte(`namespace = global\ORFix`), 0666)
+
+	if err := s.detectIniDependencies(types.Genshin, []int{ids["skin"], ids["orfix"]}); err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := s.db.SelectEnabledModsByGame(types.Genshin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 2 {
+		t.Fatalf("expected orfix to be exported with the skin got %+v", exported)
+	}
+
+	needing, _ := s.db.SelectModsNeeding(ids["orfix"])
+	if len(needing) != 1 || needing[0].Id != ids["skin"] {
+		t.Errorf("expected the skin to need orfix got %+v", needing)
+	}
+
+	// only dependencies that are not detected from the ini are kept in the sidecar
+	s.db.InsertModDependency(types.ModDependency{ModId: ids["skin"], Kind: types.DEPENDENCY_CATEGORY, DependsOn: 2})
+	mod, _ := s.db.SelectModById(ids["skin"])
+	sidecar, err := dbh.ReadModSidecar(util.GetModDir(mod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := sidecar.Dependencies; len(deps) != 1 || deps[0].Character != "Libraries" || !deps[0].Category {
+		t.Errorf("unexpected sidecar dependencies %+v", deps)
+	}
+}
@@ -4,17 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hmm/pkg/api"
 	"hmm/pkg/core/dbh"
 	"hmm/pkg/log"
 	"hmm/pkg/pref"
 	"hmm/pkg/types"
 	"hmm/pkg/util"
 	"io"
+	"io/fs"
 	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,22 +26,37 @@ import (
 )
 
 const (
-	EVENT_DOWNLOAD = "download"
-	STATE_QUEUED   = "queued"
-	STATE_FINSIHED = "finished"
-	STATE_ERROR    = "error"
-	STATE_UNZIP    = "unzip"
-	STATE_COMPRESS = "compress"
+	EVENT_DOWNLOAD    = "download"
+	STATE_QUEUED      = "queued"
+	STATE_FINSIHED    = "finished"
+	STATE_ERROR       = "error"
+	STATE_UNZIP       = "unzip"
+	STATE_COMPRESS    = "compress"
+	STATE_PASSWORD    = "password"
+	STATE_UNSUPPORTED = "unsupported"
 )
 
+// items in these states are no longer worked on and can be retried
+func isDoneState(state string) bool {
+	switch state {
+	case STATE_FINSIHED, STATE_ERROR, STATE_PASSWORD, STATE_UNSUPPORTED:
+		return true
+	}
+	return false
+}
+
 type DLMeta struct {
 	character     string
 	characterId   int
 	game          types.Game
 	gbId          int
 	texture       bool
+	category      bool
 	modId         int
 	previewImages []string
+	// set by SubmitPassword when an archive was encrypted
+	password         string
+	rememberPassword bool
 }
 
 type DLItem struct {
@@ -58,12 +76,16 @@ type Downloader struct {
 	Queue      map[string]*DLItem
 	mutex      sync.RWMutex
 	spaceSaver pref.Preference[bool]
+	format     pref.Preference[string]
+	level      pref.Preference[int]
 }
 
 func NewDownloader(
 	db *dbh.DbHelper,
 	count pref.Preference[int],
 	spaceSaver pref.Preference[bool],
+	format pref.Preference[string],
+	level pref.Preference[int],
 	emmiter EventEmmiter,
 ) *Downloader {
 
@@ -73,6 +95,8 @@ func NewDownloader(
 		Queue:      map[string]*DLItem{},
 		mutex:      sync.RWMutex{},
 		spaceSaver: spaceSaver,
+		format:     format,
+		level:      level,
 		emitter:    emmiter,
 	}
 
@@ -94,7 +118,7 @@ func NewDownloader(
 
 			qCpy := d.GetQueue()
 			for _, item := range qCpy {
-				if item.State != STATE_FINSIHED && item.State != STATE_ERROR {
+				if !isDoneState(item.State) {
 					d.Retry(item.Link)
 				}
 			}
@@ -151,6 +175,7 @@ func (d *Downloader) Retry(link string) error {
 
 	item, ok := d.Queue[link]
 	if !ok {
+		d.mutex.Unlock()
 		return errors.New("item not found")
 	}
 
@@ -158,15 +183,36 @@ func (d *Downloader) Retry(link string) error {
 
 	d.mutex.Unlock()
 
-	return d.Download(
-		item.Link,
-		item.Filename,
-		item.meta.character,
-		item.meta.characterId,
-		item.meta.game,
-		item.meta.gbId,
-		item.meta.previewImages,
-	)
+	// resubmit with the same meta so textures and passwords are kept
+	return d.submitItem(item.Link, item.Filename, item.meta)
+}
+
+// SubmitPassword retries an item waiting in STATE_PASSWORD with the given password.
+// if remember is set the password is also saved for the submitter of the gamebanana mod
+func (d *Downloader) SubmitPassword(link string, password string, remember bool) error {
+
+	d.mutex.Lock()
+
+	item, ok := d.Queue[link]
+	if !ok {
+		d.mutex.Unlock()
+		return errors.New("item not found")
+	}
+
+	if item.State != STATE_PASSWORD {
+		d.mutex.Unlock()
+		return errors.New("item is not waiting for a password")
+	}
+
+	meta := item.meta
+	meta.password = password
+	meta.rememberPassword = remember
+
+	delete(d.Queue, item.Link)
+
+	d.mutex.Unlock()
+
+	return d.submitItem(item.Link, item.Filename, meta)
 }
 
 func convertDriveLinkToDownload(viewLink string) (string, error) {
@@ -209,8 +255,7 @@ func (d *Downloader) submitItem(link, filename string, meta DLMeta) error {
 	d.mutex.Lock()
 
 	if item, ok := d.Queue[link]; ok {
-		done := item.State == STATE_FINSIHED || item.State == STATE_ERROR
-		if !done {
+		if !isDoneState(item.State) {
 			d.mutex.Unlock()
 			return errors.New("already downloading " + item.Link)
 		}
@@ -314,6 +359,18 @@ func (d *Downloader) Download(
 		previewImages: previewImages,
 	}
 
+	// mods downloaded into a category are stored in the categories dir
+	c, err := d.db.SelectCharacterById(characterId, game)
+	if err != nil {
+		// downloads from the server and plugins may only know the name
+		c, err = d.db.SelectClosestCharacter(character, game)
+	}
+	if err == nil {
+		meta.character = c.Name
+		meta.characterId = c.Id
+		meta.category = c.IsCategory()
+	}
+
 	return d.submitItem(link, filename, meta)
 }
 
@@ -328,7 +385,15 @@ func cleanup(d *Downloader, link string, err error) {
 		return
 	}
 
-	if err != nil {
+	if IsPasswordError(err) {
+		log.LogError(err.Error())
+		item.State = STATE_PASSWORD
+		d.emitter.Emit("download", STATE_PASSWORD)
+	} else if errors.Is(err, ErrUnsupportedEncryption) {
+		log.LogError(err.Error())
+		item.State = STATE_UNSUPPORTED
+		d.emitter.Emit("download", STATE_UNSUPPORTED)
+	} else if err != nil {
 		log.LogError(err.Error())
 		item.State = STATE_ERROR
 		d.emitter.Emit("download", STATE_ERROR)
@@ -349,7 +414,7 @@ func (d *Downloader) localDownload(link, filename string, meta DLMeta) (err erro
 
 		if !ok ||
 			item == nil ||
-			item.State == STATE_ERROR || item.State == STATE_FINSIHED {
+			isDoneState(item.State) {
 			log.LogDebugf("item not in queue %s", link)
 			return
 		}
@@ -480,19 +545,28 @@ func (d *Downloader) unzipAndInsertToDb(
 		dotIdx = len(filename)
 	}
 	var outputDir string
+	var modDir string
 	if meta.texture {
 		m, err := d.db.SelectModById(meta.modId)
 		if err != nil {
 			log.LogDebugf("couldnt find mod %d", meta.modId)
 			return err
 		}
-		outputDir = filepath.Join(util.GetModDir(m), "textures", filename[:dotIdx])
+		modDir = util.GetModDir(m)
+		outputDir = findUniqueDirName(filepath.Join(modDir, "textures", filename[:dotIdx]))
 	} else {
-		outputDir = filepath.Join(util.GetCharacterDir(meta.character, meta.game), filename[:dotIdx])
+		outputDir = findUniqueDirName(util.GetModDir(types.Mod{
+			Filename:  filename[:dotIdx],
+			Game:      meta.game,
+			Character: meta.character,
+			Category:  meta.category,
+		}))
+		modDir = outputDir
 	}
-	outputDir = findUniqueDirName(outputDir)
 
 	log.LogDebugf("set output dir %s", outputDir)
+	// the watcher waits until the row is inserted, otherwise it inserts a row without the metadata
+	defer d.db.BeginWrite(outputDir)()
 
 	err := os.MkdirAll(outputDir, 0777)
 	if err != nil {
@@ -515,10 +589,13 @@ func (d *Downloader) unzipAndInsertToDb(
 	log.LogDebug(filepath.Ext(filePath))
 
 	ext := filepath.Ext(filePath)
+	password := ""
 	switch {
 	case unarrSupported(ext):
 		log.LogDebugf("extracting %s", filepath.Ext(filePath))
-		if _, err = ArchiveExtract(filePath, outputDir, true, true, onProgress); err != nil {
+		if password, err = d.extractWithPasswords(filePath, outputDir, meta, onProgress); err != nil {
+			// removed so a retry with a password reuses the same dir name
+			os.RemoveAll(outputDir)
 			return err
 		}
 	case ext == "":
@@ -541,21 +618,28 @@ func (d *Downloader) unzipAndInsertToDb(
 		return errors.New("unsupported compression format")
 	}
 
+	// executables are moved out before the mod is compressed so they never reach the generator
+	quarantined, err := quarantineExecutables(modDir, outputDir)
+	if err != nil {
+		return err
+	}
+
 	if d.spaceSaver.Get() {
-		out, err := os.Open(outputDir)
+		dirs, err := os.ReadDir(outputDir)
 		if err != nil {
 			return err
 		}
-		defer out.Close()
-
-		dirs, err := out.Readdir(1)
-		if err != nil {
-			return err
+		dirs = slices.DeleteFunc(dirs, func(d os.DirEntry) bool {
+			return slices.Contains(util.MetaDataDirs, d.Name())
+		})
+		if len(dirs) == 0 {
+			return fs.ErrNotExist
 		}
 		path := filepath.Join(outputDir, dirs[0].Name())
 
-		dest := filepath.Join(filepath.Dir(path), filepath.Base(path)+".zip")
-		err = ZipFolder(path, dest, func(total, complete int) {
+		opts := compressionOptionsFrom(d.format, d.level)
+		dest := filepath.Join(filepath.Dir(path), filepath.Base(path)+opts.Format.Extension())
+		err = CompressFolder(path, dest, opts, func(total, complete int) {
 			updateProgress(STATE_COMPRESS, DataProgress{Total: int64(total), Progress: int64(complete)})
 		})
 
@@ -565,6 +649,7 @@ func (d *Downloader) unzipAndInsertToDb(
 		os.RemoveAll(path)
 	}
 
+	var id int64
 	if meta.texture {
 		log.LogDebug("Inserting texture")
 		_, err = d.db.InsertTexture(types.Texture{
@@ -580,11 +665,12 @@ func (d *Downloader) unzipAndInsertToDb(
 		})
 	} else {
 		log.LogDebug("Inserting mod")
-		_, err = d.db.InsertMod(types.Mod{
+		id, err = d.db.InsertMod(types.Mod{
 			Filename:       filepath.Base(outputDir),
 			Game:           meta.game,
 			Character:      meta.character,
 			CharacterId:    meta.characterId,
+			Category:       meta.category,
 			PreviewImages:  meta.previewImages,
 			Enabled:        false,
 			GbId:           meta.gbId,
@@ -594,9 +680,96 @@ func (d *Downloader) unzipAndInsertToDb(
 		})
 	}
 
+	if err == nil && password != "" {
+		d.rememberPassword(int(id), meta, password)
+	}
+
+	if err == nil && len(quarantined) > 0 {
+		modId := int(id)
+		if meta.texture {
+			modId = meta.modId
+		}
+		log.LogPrintf("quarantined %d files for mod %d", len(quarantined), modId)
+		err = d.db.InsertQuarantinedFiles(modId, quarantined)
+	}
+
 	return err
 }
 
+// tries the password submitted by the user first, then passwords that worked for
+// the same gamebanana mod or submitter. returns the password that was used
+func (d *Downloader) extractWithPasswords(
+	filePath, outputDir string,
+	meta DLMeta,
+	onProgress func(progress int64, total int64),
+) (string, error) {
+
+	_, err := ArchiveExtractWithPassword(filePath, outputDir, meta.password, true, true, onProgress)
+	if !IsPasswordError(err) || meta.password != "" {
+		return meta.password, err
+	}
+
+	for _, stored := range d.storedPasswords(meta) {
+		for _, password := range stored() {
+			_, err = ArchiveExtractWithPassword(filePath, outputDir, password, true, true, onProgress)
+			if !IsPasswordError(err) {
+				return password, err
+			}
+		}
+	}
+
+	return "", err
+}
+
+// the saved passwords grouped by scope, the submitter is only looked up on gamebanana
+// when the passwords of the mod did not work
+func (d *Downloader) storedPasswords(meta DLMeta) []func() []string {
+	if meta.gbId == 0 {
+		return []func() []string{}
+	}
+
+	return []func() []string{
+		func() []string {
+			passwords, _ := d.db.SelectArchivePasswords(dbh.PASSWORD_SCOPE_GB_MOD, meta.gbId)
+			return passwords
+		},
+		func() []string {
+			submitterId, ok := gbSubmitterId(meta.gbId)
+			if !ok {
+				return []string{}
+			}
+			passwords, _ := d.db.SelectArchivePasswords(dbh.PASSWORD_SCOPE_SUBMITTER, submitterId)
+			return passwords
+		},
+	}
+}
+
+func (d *Downloader) rememberPassword(id int, meta DLMeta, password string) {
+	saved := []error{}
+	if !meta.texture {
+		saved = append(saved, d.db.InsertArchivePassword(dbh.PASSWORD_SCOPE_MOD, id, password))
+	}
+	if meta.gbId != 0 {
+		saved = append(saved, d.db.InsertArchivePassword(dbh.PASSWORD_SCOPE_GB_MOD, meta.gbId, password))
+	}
+	if meta.rememberPassword && meta.gbId != 0 {
+		if submitterId, ok := gbSubmitterId(meta.gbId); ok {
+			saved = append(saved, d.db.InsertArchivePassword(dbh.PASSWORD_SCOPE_SUBMITTER, submitterId, password))
+		}
+	}
+	if err := errors.Join(saved...); err != nil {
+		log.LogError(err.Error())
+	}
+}
+
+func gbSubmitterId(gbId int) (int, bool) {
+	page, err := (&api.GbApi{}).ModPage(gbId)
+	if err != nil || page.ASubmitter.IDRow == 0 {
+		return 0, false
+	}
+	return int(page.ASubmitter.IDRow), true
+}
+
 func unarrSupported(ext string) bool {
 	_, exists := unarrExtensions[ext]
 	return exists
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"hmm/db"
 	"hmm/pkg/core/dbh"
 	"hmm/pkg/log"
 	"hmm/pkg/pref"
@@ -36,7 +35,7 @@ func _getDb() *dbh.DbHelper {
 		panic(err)
 	}
 
-	return dbh.NewDbHelper(db.New(dbSql), dbSql)
+	return dbh.NewDbHelper(dbSql)
 }
 
 func TestZip(t *testing.T) {
@@ -63,6 +62,8 @@ func TestGoogleDrive(t *testing.T) {
 		_getDb(),
 		prefs.GetInt("test_workers", 1),
 		prefs.GetBoolean("test_space_saver", false),
+		prefs.GetString("test_compression_format", string(ZipDeflate)),
+		prefs.GetInt("test_compression_level", 0),
 		emitter,
 	)
 
@@ -116,6 +117,8 @@ func TestLocalSource(t *testing.T) {
 		_getDb(),
 		prefs.GetInt("test_workers", 1),
 		prefs.GetBoolean("test_space_saver", true),
+		prefs.GetString("test_compression_format", string(ZipDeflate)),
+		prefs.GetInt("test_compression_level", 0),
 		emitter,
 	)
 
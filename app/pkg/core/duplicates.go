@@ -0,0 +1,134 @@
+package core
+
+import (
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"slices"
+	"strings"
+)
+
+// FindDuplicates groups mods of the game that came from the same GameBanana file
+// or have the same files on disk
+func (s *SyncHelper) FindDuplicates(game types.Game) ([]types.DuplicateGroup, error) {
+	groups := []types.DuplicateGroup{}
+	err := s.running[game].SubmitErr(func() error {
+		var err error
+		groups, err = s.findDuplicates(game)
+		return err
+	}).Wait()
+	return groups, err
+}
+
+// MergeMods keeps one copy of a duplicate, see dbh.MergeMods
+func (s *SyncHelper) MergeMods(keepId, removeId int) error {
+	keep, err := s.db.SelectModById(keepId)
+	if err != nil {
+		return err
+	}
+	return s.running[keep.Game].SubmitErr(func() error {
+		return s.db.MergeMods(keepId, removeId)
+	}).Wait()
+}
+
+func (s *SyncHelper) findDuplicates(game types.Game) ([]types.DuplicateGroup, error) {
+	mods, err := s.db.SelectModsByGame(game)
+	if err != nil {
+		return []types.DuplicateGroup{}, err
+	}
+	fingerprints, err := s.db.SelectModFingerprints(game)
+	if err != nil {
+		return []types.DuplicateGroup{}, err
+	}
+
+	parent := make([]int, len(mods))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := map[int][]types.DuplicateReason{}
+	union := func(a, b int, reason types.DuplicateReason) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[rb] = ra
+			reasons[ra] = append(reasons[ra], reasons[rb]...)
+			delete(reasons, rb)
+		}
+		if !slices.Contains(reasons[ra], reason) {
+			reasons[ra] = append(reasons[ra], reason)
+		}
+	}
+
+	byFile := map[string][]int{}
+	byFingerprint := map[string][]int{}
+	for i, m := range mods {
+		if m.GbFileName != "" {
+			file := strings.ToLower(m.GbFileName)
+			byFile[file] = append(byFile[file], i)
+		}
+
+		fingerprint, ok := fingerprints[m.Id]
+		if !ok {
+			// mods added before fingerprints were stored
+			fingerprint, _ = modFingerprint(util.GetModDir(m))
+			if fingerprint != "" {
+				s.db.UpdateModFingerprint(m.Id, fingerprint)
+			}
+		}
+		if fingerprint != "" {
+			byFingerprint[fingerprint] = append(byFingerprint[fingerprint], i)
+		}
+	}
+
+	for _, idxs := range byFile {
+		// different pages can upload files with the same name, mods without a gbId
+		// are only matched when the name belongs to one page
+		byGbId := map[int]int{}
+		unknown := []int{}
+		for _, i := range idxs {
+			if mods[i].GbId == 0 {
+				unknown = append(unknown, i)
+			} else if first, ok := byGbId[mods[i].GbId]; ok {
+				union(first, i, types.DUPLICATE_GB_FILE)
+			} else {
+				byGbId[mods[i].GbId] = i
+			}
+		}
+
+		if len(byGbId) > 1 {
+			continue
+		}
+		for _, first := range byGbId {
+			unknown = append(unknown, first)
+		}
+		for _, i := range unknown[min(1, len(unknown)):] {
+			union(unknown[0], i, types.DUPLICATE_GB_FILE)
+		}
+	}
+	for _, idxs := range byFingerprint {
+		for _, b := range idxs[1:] {
+			union(idxs[0], b, types.DUPLICATE_FINGERPRINT)
+		}
+	}
+
+	grouped := map[int][]types.Mod{}
+	for i, m := range mods {
+		if _, ok := reasons[find(i)]; ok {
+			grouped[find(i)] = append(grouped[find(i)], m)
+		}
+	}
+
+	groups := make([]types.DuplicateGroup, 0, len(grouped))
+	for root, group := range grouped {
+		slices.SortFunc(group, func(a, b types.Mod) int { return a.Id - b.Id })
+		slices.Sort(reasons[root])
+		groups = append(groups, types.DuplicateGroup{Mods: group, Reasons: reasons[root]})
+	}
+	slices.SortFunc(groups, func(a, b types.DuplicateGroup) int { return a.Mods[0].Id - b.Mods[0].Id })
+	return groups, nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindAndMergeDuplicates(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	s.db.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	ids := map[string]int{}
+	for _, mod := range []types.Mod{
+		{Filename: "downloaded", GbId: 10, GbFileName: "Nahida.zip"},
+		{Filename: "imported"},
+		{Filename: "redownloaded", GbId: 10, GbFileName: "nahida.zip"},
+		{Filename: "other", GbId: 11, GbFileName: "Nahida.zip"},
+	} {
+		mod.Game, mod.Character, mod.CharacterId = types.Genshin, "Nahida", 1
+		dir := util.GetModDir(mod)
+		os.MkdirAll(filepath.Join(dir, "root"), os.ModePerm)
+		content := "[TextureOverride]"
+		if mod.Filename == "other" || mod.Filename == "redownloaded" {
+			content = mod.Filename
+		}
+		os.WriteFile(filepath.Join(dir, "root", "mod.ini"), []byte(content), 0666)
+
+		id, err := s.db.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[mod.Filename] = int(id)
+	}
+
+	groups, err := s.findDuplicates(types.Genshin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || len(groups[0].Mods) != 3 || len(groups[0].Reasons) != 2 {
+		t.Fatalf("expected downloaded, imported and redownloaded to be grouped got %+v", groups)
+	}
+
+	keep, _ := s.db.SelectModById(ids["downloaded"])
+	remove, _ := s.db.SelectModById(ids["imported"])
+	s.db.InsertTag("outfit", remove.Id)
+	s.db.InsertPlaylistWithMods(types.Genshin, "favorites", []int{remove.Id})
+	os.MkdirAll(filepath.Join(util.GetModDir(remove), "textures", "recolor"), os.ModePerm)
+	textureId, _ := s.db.InsertTexture(types.Texture{Filename: "recolor", ModId: remove.Id})
+	os.MkdirAll(util.GetKeyMapsDir(remove), os.ModePerm)
+	os.WriteFile(filepath.Join(util.GetKeyMapsDir(remove), "keymap.ini"), []byte{}, 0666)
+
+	if err = s.db.MergeMods(keep.Id, remove.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.db.SelectModById(remove.Id); err == nil {
+		t.Error("merged mod row was not deleted")
+	}
+	if exists, _ := util.FileExists(util.GetModDir(remove)); exists {
+		t.Error("merged mod dir was not deleted")
+	}
+	if tags, _ := s.db.SelectTagsByModId(int64(keep.Id)); len(tags) != 1 {
+		t.Errorf("expected the tag to be merged got %v", tags)
+	}
+	if texture, err := s.db.SelectTextureById(int(textureId)); err != nil || texture.ModId != keep.Id {
+		t.Errorf("expected the texture to be moved got %+v %v", texture, err)
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetModDir(keep), "textures", "recolor")); !exists {
+		t.Error("texture dir was not moved")
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetKeyMapsDir(keep), "keymap.ini")); !exists {
+		t.Error("keymap was not moved")
+	}
+	playlists, _ := s.db.SelectPlaylistWithModsAndTags(types.Genshin)
+	if len(playlists) != 1 || len(playlists[0].ModsWithTags) != 1 || playlists[0].ModsWithTags[0].Mod.Id != keep.Id {
+		t.Errorf("expected the playlist to contain the kept mod got %+v", playlists)
+	}
+}
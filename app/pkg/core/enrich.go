@@ -0,0 +1,109 @@
+package core
+
+import (
+	"hmm/pkg/api"
+	"hmm/pkg/core/dbh"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"slices"
+	"sync"
+)
+
+// ModPageFetcher is implemented by api.GbApi
+type ModPageFetcher interface {
+	ModPage(id int) (api.ModPageResponse, error)
+}
+
+// MetadataEnricher fills the author, version, description and content ratings
+// of mods downloaded from GameBanana and refreshes their size on disk
+type MetadataEnricher struct {
+	db      *dbh.DbHelper
+	fetcher ModPageFetcher
+	mutex   sync.Mutex
+}
+
+func NewMetadataEnricher(db *dbh.DbHelper, fetcher ModPageFetcher) *MetadataEnricher {
+	return &MetadataEnricher{
+		db:      db,
+		fetcher: fetcher,
+	}
+}
+
+// EnrichAll runs EnrichMods for every game, used after the startup sync
+func (e *MetadataEnricher) EnrichAll() {
+	for _, game := range types.Games {
+		if _, err := e.EnrichMods(game, false); err != nil {
+			log.LogError(err.Error())
+		}
+	}
+}
+
+// EnrichMods fetches the GameBanana page of mods with a gbId that were not enriched yet,
+// force fetches every mod again. returns the number of mods updated
+func (e *MetadataEnricher) EnrichMods(game types.Game, force bool) (int, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	mods, err := e.db.SelectModsWithGbIdByGame(game)
+	if err != nil {
+		return 0, err
+	}
+
+	// mods downloaded from the same page share one request
+	pages := map[int]api.ModPageResponse{}
+	updated := 0
+	for _, mod := range mods {
+		if size, err := util.DirSize(util.GetModDir(mod)); err == nil && size != mod.Size {
+			if err := e.db.UpdateModSize(mod.Id, size); err != nil {
+				log.LogError(err.Error())
+			}
+		}
+
+		if mod.GbUpdatedAt != 0 && !force {
+			continue
+		}
+
+		page, ok := pages[mod.GbId]
+		if !ok {
+			page, err = e.fetcher.ModPage(mod.GbId)
+			if err != nil {
+				log.LogError(err.Error())
+				continue
+			}
+			pages[mod.GbId] = page
+		}
+
+		if err := e.db.UpdateModGbMetadata(mod.Id, metadataFromPage(page)); err != nil {
+			log.LogError(err.Error())
+			continue
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func metadataFromPage(page api.ModPageResponse) types.Mod {
+	ratings := make([]string, 0, len(page.AContentRatings))
+	for _, rating := range page.AContentRatings {
+		ratings = append(ratings, rating)
+	}
+	slices.Sort(ratings)
+
+	updatedAt := page.TsDateUpdated
+	if updatedAt == 0 {
+		updatedAt = page.TsDateModified
+	}
+	if updatedAt == 0 {
+		updatedAt = page.TsDateAdded
+	}
+
+	return types.Mod{
+		GbName:         page.SName,
+		Author:         page.ASubmitter.SName,
+		Version:        page.SVersion,
+		Description:    page.SText,
+		ContentRatings: ratings,
+		GbUpdatedAt:    updatedAt,
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"hmm/pkg/api"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type fakeModPages struct {
+	pages    map[int]api.ModPageResponse
+	requests int
+}
+
+func (f *fakeModPages) ModPage(id int) (api.ModPageResponse, error) {
+	f.requests++
+	return f.pages[id], nil
+}
+
+func TestEnrichMods(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	s.db.UpsertCharacter(types.Character{Id: 1, Game: types.Genshin, Name: "Nahida"})
+
+	ids := []int{}
+	for _, mod := range []types.Mod{
+		{Filename: "first", GbId: 10},
+		{Filename: "second", GbId: 10},
+		{Filename: "imported"},
+	} {
+		mod.Game, mod.Character, mod.CharacterId = types.Genshin, "Nahida", 1
+		os.MkdirAll(util.GetModDir(mod), os.ModePerm)
+		os.WriteFile(filepath.Join(util.GetModDir(mod), "mod.ini"), []byte("[Constants]"), 0666)
+		id, err := s.db.InsertMod(mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, int(id))
+	}
+
+	fetcher := &fakeModPages{pages: map[int]api.ModPageResponse{
+		10: {
+			SName:           "Nahida outfit",
+			ASubmitter:      api.ASubmitter{SName: "author"},
+			SVersion:        "1.2",
+			SText:           "description",
+			AContentRatings: api.AContentRatings{"st": "Sexual Themes", "bg": "Blood & Gore"},
+			TsDateModified:  1700000000,
+		},
+	}}
+	enricher := NewMetadataEnricher(s.db, fetcher)
+
+	updated, err := enricher.EnrichMods(types.Genshin, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 2 || fetcher.requests != 1 {
+		t.Fatalf("expected 2 mods updated with 1 request got %d and %d", updated, fetcher.requests)
+	}
+
+	mod, _ := s.db.SelectModById(ids[0])
+	if mod.GbName != "Nahida outfit" || mod.Author != "author" || mod.Version != "1.2" || mod.Description != "description" || mod.GbUpdatedAt != 1700000000 {
+		t.Errorf("metadata was not saved got %+v", mod)
+	}
+	if !slices.Equal(mod.ContentRatings, []string{"Blood & Gore", "Sexual Themes"}) {
+		t.Errorf("expected sorted content ratings got %v", mod.ContentRatings)
+	}
+	if mod.Size == 0 || mod.AddedAt == 0 {
+		t.Errorf("expected size and added at to be set got %d %d", mod.Size, mod.AddedAt)
+	}
+	if imported, _ := s.db.SelectModById(ids[2]); imported.Author != "" {
+		t.Errorf("mod without a gbId was enriched %+v", imported)
+	}
+
+	if updated, _ := enricher.EnrichMods(types.Genshin, false); updated != 0 {
+		t.Errorf("enriched mods were fetched again %d", updated)
+	}
+	if updated, _ := enricher.EnrichMods(types.Genshin, true); updated != 2 {
+		t.Errorf("expected force to fetch every mod got %d", updated)
+	}
+
+	if _, err := s.db.UpdateModEnabledById(true, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if mod, _ := s.db.SelectModById(ids[0]); mod.LastEnabledAt == 0 {
+		t.Error("last enabled at was not set")
+	}
+}
@@ -13,86 +13,114 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/bodgit/sevenzip"
 	"github.com/klauspost/compress/flate"
 	"github.com/klauspost/compress/zip"
+	"github.com/klauspost/compress/zstd"
 	"github.com/mholt/archives"
+	"github.com/nwaples/rardecode/v2"
 
 	"gopkg.in/ini.v1"
 )
 
 var (
-	ErrNoCompressedFiles   = errors.New("no compressed files found")
-	ErrUnknownArchiveType  = errors.New("unknown archive file type")
-	ErrInvalidPath         = errors.New("archived file contains invalid path")
-	ErrInvalidHead         = errors.New("archived file contains invalid header file")
-	ErrStopWalkingDirError = errors.New("stop walking normally")
+	ErrNoCompressedFiles      = errors.New("no compressed files found")
+	ErrUnknownArchiveType     = errors.New("unknown archive file type")
+	ErrInvalidPath            = errors.New("archived file contains invalid path")
+	ErrInvalidHead            = errors.New("archived file contains invalid header file")
+	ErrStopWalkingDirError    = errors.New("stop walking normally")
+	ErrUnsupportedCompression = errors.New("compression format not supported")
+	ErrPasswordRequired       = errors.New("archive is encrypted and requires a password")
+	ErrIncorrectPassword      = errors.New("incorrect archive password")
+	ErrUnsupportedEncryption  = errors.New("archive encryption is not supported")
 )
 
-// WalkAndRezip walks through the directory, finds ZIP files, extracts and recompresses them
-func WalkAndRezip(root string, ctx context.Context, onProgress func(total int, complete int)) error {
+func ZipFolder(srcDir, destZip string, onProgress func(total int, complete int)) error {
+	return CompressFolder(srcDir, destZip, DefaultCompressionOptions, onProgress)
+}
 
-	rezipWithNewCompression := func(path string) error {
-		t, _ := os.MkdirTemp(os.TempDir(), "")
-		_, err := ArchiveExtract(path, t, false, true, func(progress int64, total int64) {})
-		if err != nil {
-			return nil
-		}
+// CompressFolder writes the contents of srcDir to dest using the given format and level
+func CompressFolder(srcDir, dest string, opts CompressionOptions, onProgress func(total int, complete int)) error {
 
-		newZip := strings.TrimSuffix(path, ".zip") + "_new.zip"
-		fmt.Println("extraxting to " + newZip + "from " + path)
-		err = ZipFolder(t, newZip, func(total, complete int) {})
-		if err != nil {
-			return nil
-		}
-		err = os.Rename(newZip, path)
-		if err != nil {
-			return nil
-		}
+	if !opts.Format.Supported() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCompression, opts.Format)
+	}
 
-		fmt.Println("Recompressed and cleaned:", path)
-		return os.RemoveAll(t)
+	err := os.MkdirAll(filepath.Dir(dest), os.ModePerm)
+	if err != nil {
+		return err
 	}
 
-	paths := []string{}
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	switch opts.Format {
+	case TarZstd:
+		return tarZstdFolder(srcDir, dest, opts.Level, onProgress)
+	case ZipZstd:
+		return zipFolder(srcDir, dest, zstd.ZipMethodWinZip, func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(opts.zstdLevel()))
+		}, onProgress)
+	default:
+		return zipFolder(srcDir, dest, zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, opts.deflateLevel())
+		}, onProgress)
+	}
+}
+
+func countFiles(srcDir string) (int, error) {
+	total := 0
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if filepath.Ext(path) == ".zip" {
-			paths = append(paths, path)
+		if path == srcDir {
+			return nil
 		}
+		total += 1
 		return nil
 	})
+	return total, err
+}
 
+func tarZstdFolder(srcDir, dest string, level int, onProgress func(total int, complete int)) error {
+
+	ctx := context.Background()
+
+	files, err := archives.FilesFromDisk(ctx, nil, map[string]string{
+		srcDir + string(filepath.Separator): "",
+	})
 	if err != nil {
 		return err
 	}
 
-	failed := []error{}
-	total := len(paths)
-	onProgress(total, 0)
-
-	for i, path := range paths {
-
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
+	total := len(files)
+	complete := 0
+	onProgress(total, complete)
 
-		if err := rezipWithNewCompression(path); err != nil {
-			failed = append(failed, err)
+	for i := range files {
+		open := files[i].Open
+		files[i].Open = func() (fs.File, error) {
+			complete += 1
+			onProgress(total, complete)
+			return open()
 		}
-		onProgress(total, i+1)
 	}
 
-	return errors.Join(failed...)
-}
-
-func ZipFolder(srcDir, destZip string, onProgress func(total int, complete int)) error {
-
-	err := os.MkdirAll(filepath.Dir(destZip), os.ModePerm)
+	out, err := os.Create(dest)
 	if err != nil {
 		return err
 	}
+	defer out.Close()
+
+	format := archives.CompressedArchive{
+		Archival: archives.Tar{},
+		Compression: archives.Zstd{
+			EncoderOptions: []zstd.EOption{zstd.WithEncoderLevel(CompressionOptions{Format: TarZstd, Level: level}.zstdLevel())},
+		},
+	}
+
+	return format.Archive(ctx, out, files)
+}
+
+func zipFolder(srcDir, destZip string, method uint16, compressor zip.Compressor, onProgress func(total int, complete int)) error {
 
 	zipFile, err := os.Create(destZip)
 	if err != nil {
@@ -103,27 +131,13 @@ func ZipFolder(srcDir, destZip string, onProgress func(total int, complete int))
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 
-	zipWriter.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
-		return flate.NewWriter(w, flate.BestCompression)
-	})
-
-	total := 0
-	complete := 0
-
-	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if path == srcDir {
-			return nil
-		}
-		total += 1
-		return nil
-	})
+	zipWriter.RegisterCompressor(method, compressor)
 
+	total, err := countFiles(srcDir)
 	if err != nil {
 		return err
 	}
+	complete := 0
 
 	onProgress(total, complete)
 
@@ -144,8 +158,8 @@ func ZipFolder(srcDir, destZip string, onProgress func(total int, complete int))
 		if err != nil {
 			return err
 		}
-		header.Name = relPath
-		header.Method = zip.Deflate
+		header.Name = filepath.ToSlash(relPath)
+		header.Method = method
 
 		writer, err := zipWriter.CreateHeader(header)
 		if err != nil {
@@ -244,10 +258,47 @@ func getUncompressedSize(ctx context.Context, archivePath string) (int64, []stri
 	return total, contents, err
 }
 
+// maps the errors returned by rar and 7z readers for encrypted content
+// to ErrPasswordRequired or ErrIncorrectPassword
+func passwordError(err error, password string) error {
+	if err == nil {
+		return nil
+	}
+
+	var szErr *sevenzip.ReadError
+	encrypted := errors.Is(err, rardecode.ErrArchiveEncrypted) ||
+		errors.Is(err, rardecode.ErrArchivedFileEncrypted) ||
+		errors.Is(err, rardecode.ErrBadPassword) ||
+		(errors.As(err, &szErr) && szErr.Encrypted)
+
+	switch {
+	case !encrypted:
+		return err
+	case password == "":
+		return fmt.Errorf("%w: %w", ErrPasswordRequired, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrIncorrectPassword, err)
+	}
+}
+
+func IsPasswordError(err error) bool {
+	return errors.Is(err, ErrPasswordRequired) || errors.Is(err, ErrIncorrectPassword)
+}
+
 func ArchiveExtract(
 	archivePath, path string,
 	unifyRoot, overwrite bool,
 	onProgress func(progress int64, total int64),
+) (string, error) {
+	return ArchiveExtractWithPassword(archivePath, path, "", unifyRoot, overwrite, onProgress)
+}
+
+// ArchiveExtractWithPassword extracts archives that may be encrypted, returns ErrPasswordRequired
+// if the archive is encrypted and password is empty
+func ArchiveExtractWithPassword(
+	archivePath, path, password string,
+	unifyRoot, overwrite bool,
+	onProgress func(progress int64, total int64),
 ) (string, error) {
 	ctx := context.Background()
 
@@ -284,6 +335,20 @@ func ArchiveExtract(
 		return "", err
 	}
 
+	switch f := format.(type) {
+	case archives.Zip:
+		// archives can not decrypt zip files
+		if isEncryptedZip(archivePath) {
+			return basePath, extractEncryptedZip(archivePath, basePath, password, overwrite, onProgress)
+		}
+	case archives.Rar:
+		f.Password = password
+		format = f
+	case archives.SevenZip:
+		f.Password = password
+		format = f
+	}
+
 	ex, ok := format.(archives.Extractor)
 	if !ok {
 		return "", errors.New("nothing to extract")
@@ -333,7 +398,7 @@ func ArchiveExtract(
 		return nil
 	})
 
-	return basePath, err
+	return basePath, passwordError(err, password)
 }
 
 func findUniqueDirName(basePath string) string {
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// bytes of an archive that are hashed, sizes are compared for the rest
+const fingerprintHeadSize = 64 * 1024
+
+// modFingerprint identifies the files of a mod independent of the mod dir name and the
+// name of the extracted root folder or archive. metadata dirs are skipped, returns an
+// empty string for a mod without files
+func modFingerprint(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{}
+	for _, e := range entries {
+		if slices.Contains(util.MetaDataDirs, e.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+
+		if !e.IsDir() {
+			head, size, err := headHash(path)
+			if err != nil {
+				return "", err
+			}
+			lines = append(lines, fmt.Sprintf("%d:%s", size, head))
+			continue
+		}
+
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(path, p)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, fmt.Sprintf("%s:%d", filepath.ToSlash(rel), info.Size()))
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	slices.Sort(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		io.WriteString(h, line+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func headHash(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	h := sha256.New()
+	if _, err = io.CopyN(h, f, fingerprintHeadSize); err != nil && err != io.EOF {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), info.Size(), nil
+}
+
+// iniCacheFingerprint changes when the files of the mod or its enabled textures change,
+// textures are copied over the mod and can add or replace ini files
+func iniCacheFingerprint(modFingerprint string, textures []types.Texture) string {
+	lines := make([]string, 0, len(textures))
+	for _, t := range textures {
+		lines = append(lines, fmt.Sprintf("%d:%s", t.Id, t.Filename))
+	}
+	slices.Sort(lines)
+
+	h := sha256.New()
+	io.WriteString(h, modFingerprint+"\n")
+	for _, line := range lines {
+		io.WriteString(h, line+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
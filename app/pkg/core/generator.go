@@ -20,6 +20,7 @@ import (
 
 	"github.com/alitto/pond/v2"
 	"github.com/mholt/archives"
+	"gopkg.in/ini.v1"
 )
 
 const (
@@ -75,6 +76,23 @@ func NewGenerator(
 	}
 }
 
+// category mods are exported into util.CategoriesDir so they are kept apart from character mods
+func exportDir(outputDir string, mod types.Mod) string {
+	if mod.Category {
+		return filepath.Join(outputDir, util.CategoriesDir)
+	}
+	return outputDir
+}
+
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
+
 func areModsSame(parts []string) func(m types.Mod) bool {
 	return func(m types.Mod) bool {
 		prevId, err := strconv.Atoi(parts[0])
@@ -186,23 +204,40 @@ func (g *Generator) generateWithContext(
 		log.LogError("err while saving config" + err.Error())
 	}
 
+	isCategory := func(m types.Mod) bool { return m.Category }
+
 	cleanTask := g.cleanOutputDir(
 		exported,
 		outputDir,
 		ignored,
-		selected,
+		slices.DeleteFunc(slices.Clone(selected), isCategory),
 		genPond,
 		ctx,
 	)
 
 	cleanTask.Wait()
 
+	// category mods are exported into their own dir and cleaned separately
+	categoriesDir := filepath.Join(outputDir, util.CategoriesDir)
+	os.MkdirAll(categoriesDir, os.ModePerm)
+	if categoryExported, err := readDirNames(categoriesDir); err == nil {
+		g.cleanOutputDir(
+			categoryExported,
+			categoriesDir,
+			ignored,
+			slices.DeleteFunc(slices.Clone(selected), func(m types.Mod) bool { return !m.Category }),
+			genPond,
+			ctx,
+		).Wait()
+	}
+
 	if ctx.Err() != nil {
 		genPond.StopAndWait()
 		return err
 	}
 
-	exportTask := g.copyToOutputDir(selected, outputDir, genPond, ctx)
+	exportedIds := &exportedMods{}
+	exportTask := g.copyToOutputDir(selected, outputDir, exportedIds, genPond, ctx)
 	exportTask.Wait()
 
 	if ctx.Err() != nil {
@@ -210,6 +245,14 @@ func (g *Generator) generateWithContext(
 		return err
 	}
 
+	generated := make([]int, len(selected))
+	for i, m := range selected {
+		generated[i] = m.Id
+	}
+	if err := g.db.RecordGeneration(game, generated, exportedIds.ids); err != nil {
+		log.LogError(err.Error())
+	}
+
 	err = runModFixExe(ctx, exported, outputDir)
 
 	return err
@@ -241,10 +284,23 @@ func runModFixExe(ctx context.Context, exported []string, outputDir string) erro
 	return err
 }
 
+// ids of the mods copied by copyToOutputDir
+type exportedMods struct {
+	mutex sync.Mutex
+	ids   []int
+}
+
+func (e *exportedMods) add(id int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.ids = append(e.ids, id)
+}
+
 // overwrites mods with textures and overwrites merged.ini with saved config and keymaps
 func (g *Generator) copyToOutputDir(
 	selected []types.Mod,
 	outputDir string,
+	exported *exportedMods,
 	pond pond.Pool,
 	ctx context.Context,
 ) pond.TaskGroup {
@@ -259,7 +315,7 @@ func (g *Generator) copyToOutputDir(
 
 			// IMPORTANT DONT CHANGE WITHOUT ALSO CHANGING configsaver.go
 			// all entries should have the mod id as a prefix
-			outputDir := filepath.Join(outputDir, fmt.Sprintf("%d_%s", mod.Id, mod.Filename))
+			outputDir := filepath.Join(exportDir(outputDir, mod), fmt.Sprintf("%d_%s", mod.Id, mod.Filename))
 
 			// error ignored and reported empty texture slice wont stop copying
 			textures, err := g.db.SelectTexturesByModId(mod.Id)
@@ -272,6 +328,7 @@ func (g *Generator) copyToOutputDir(
 				mod,
 				outputDir,
 				textures,
+				g.db.SelectArchivePasswordsForMod(mod),
 				ctx,
 			)
 			// dont ignore error here nothing to overwrite if copying failed
@@ -279,9 +336,16 @@ func (g *Generator) copyToOutputDir(
 				log.LogErrorf("failed to copy mod and textures #%d :%e", mod.Id, err)
 				return
 			}
+			exported.add(mod.Id)
+
+			// quarantined files are only copied once the user allowed them
+			quarantined, _ := g.db.SelectQuarantinedFiles(mod.Id)
+			if err = copyAllowedQuarantined(mod, outputDir, quarantined, textures); err != nil {
+				log.LogErrorf("failed to copy allowed quarantined files #%d :%e", mod.Id, err)
+			}
 
 			// error ignored and reported only affects keymap and config
-			err = overwriteMergedIniIfneeded(mod, outputDir, g.db)
+			err = overwriteMergedIniIfneeded(mod, outputDir, textures, g.db)
 			if err != nil {
 				log.LogErrorf("failed to overwrite merged.ini #%d :%e", mod.Id, err)
 			}
@@ -313,7 +377,7 @@ func (g *Generator) cleanOutputDir(
 				log.LogError(err.Error())
 				return
 			}
-			if !stat.IsDir() || file == "BufferValues" || slices.Contains(ignored, file) {
+			if !stat.IsDir() || file == "BufferValues" || file == util.CategoriesDir || slices.Contains(ignored, file) {
 				log.LogDebug("skipping file" + file)
 				return
 			}
@@ -352,6 +416,7 @@ func copyModWithTextures(
 	mod types.Mod,
 	dst string,
 	textures []types.Texture,
+	passwords []string,
 	ctx context.Context,
 ) error {
 
@@ -384,6 +449,20 @@ func copyModWithTextures(
 			overwrite,
 			nil,
 		)
+		// try the passwords that worked when the mod was downloaded
+		for _, password := range passwords {
+			if !IsPasswordError(err) {
+				break
+			}
+			_, err = ArchiveExtractWithPassword(
+				modArchive,
+				strings.TrimSuffix(dst, ext),
+				password,
+				false,
+				overwrite,
+				nil,
+			)
+		}
 	} else {
 		err = util.CopyRecursivley(modArchive, dst, overwrite)
 	}
@@ -396,13 +475,31 @@ func copyModWithTextures(
 	return err
 }
 
-func GetRelativeIniPath(m types.Mod, modDir string, cache dbh.IniCache) (string, error) {
-	var iniPath string
-	entry, err := cache.SelectIniEntryByModId(m.Id)
-	// couldnt find entry in cache search output for the relative
-	// path from the modDir and save it
-	if err != nil || entry.File == "" {
-		log.LogDebugf("Searching for ini Entry for mod %s %d", m.Filename, m.Id)
+// GetRelativeIniPaths finds every ini of the mod generated into modDir. the paths are cached
+// until the files of the mod or the enabled textures change
+func GetRelativeIniPaths(m types.Mod, modDir string, textures []types.Texture, cache dbh.IniCache) ([]string, error) {
+	files, err := cache.SelectModFingerprint(m.Id)
+	if err != nil || files == "" {
+		// mods synced before fingerprints were stored
+		files, _ = modFingerprint(util.GetModDir(m))
+	}
+	fingerprint := iniCacheFingerprint(files, textures)
+
+	entries, err := cache.SelectIniEntriesByModId(m.Id)
+	cached := err == nil && len(entries) > 0 && !slices.ContainsFunc(entries, func(e dbh.IniCacheEntry) bool {
+		return e.Fingerprint != fingerprint
+	})
+
+	iniPaths := []string{}
+	if cached {
+		log.LogDebugf("found ini entries from cache mod %s %d %v", m.Filename, m.Id, entries)
+		for _, e := range entries {
+			if e.File != "" {
+				iniPaths = append(iniPaths, e.File)
+			}
+		}
+	} else {
+		log.LogDebugf("Searching for ini entries for mod %s %d", m.Filename, m.Id)
 		err := filepath.WalkDir(modDir, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
@@ -410,48 +507,43 @@ func GetRelativeIniPath(m types.Mod, modDir string, cache dbh.IniCache) (string,
 			if d.IsDir() {
 				return nil
 			}
-			if filepath.Ext(d.Name()) == ".ini" && !strings.HasPrefix(d.Name(), "DISABLED") && !strings.HasPrefix(d.Name(), "help") {
+			if filepath.Ext(d.Name()) == ".ini" && !strings.HasPrefix(strings.ToUpper(d.Name()), "DISABLED") && !strings.HasPrefix(d.Name(), "help") {
 				log.LogDebugf("found ini Entry for mod %s %d %s", m.Filename, m.Id, path)
-				iniPath, err = filepath.Rel(modDir, path)
+				rel, err := filepath.Rel(modDir, path)
 				if err != nil {
 					return err
 				}
-				return fs.SkipAll
+				iniPaths = append(iniPaths, rel)
 			}
 			return nil
 		})
 
 		if err != nil {
-			return "", err
+			return iniPaths, err
 		}
 
-		if err := cache.InsertIniEntry(m.Id, iniPath); err != nil {
-			log.LogErrorf("failed writing ini Entry to cache mod %s %d %s %e", m.Filename, m.Id, entry.File, err)
+		if err := cache.ReplaceIniEntries(m.Id, fingerprint, iniPaths); err != nil {
+			log.LogErrorf("failed writing ini entries to cache mod %s %d %e", m.Filename, m.Id, err)
 		}
-	} else {
-		log.LogDebugf("found ini Entry from cache mod %s %d %s", m.Filename, m.Id, entry.File)
-		iniPath = entry.File
 	}
 
-	if iniPath == "" {
-		return "", errors.New("ini not found")
+	if len(iniPaths) == 0 {
+		return iniPaths, errors.New("ini not found")
 	}
-	return iniPath, nil
+	return iniPaths, nil
 }
 
-func overwriteMergedIniIfneeded(m types.Mod, outputDir string, cache dbh.IniCache) error {
-	// get the path of the original ini in the generated mods dir
-	relPath, err := GetRelativeIniPath(m, outputDir, cache)
-	iniPath := filepath.Join(outputDir, relPath)
+// overwrites every ini of the mod with its saved config, the enabled keymap replaces
+// the first ini which is the one the keymapper edits
+func overwriteMergedIniIfneeded(m types.Mod, outputDir string, textures []types.Texture, cache dbh.IniCache) error {
+	// get the paths of the original inis in the generated mods dir
+	relPaths, err := GetRelativeIniPaths(m, outputDir, textures, cache)
 	if err != nil {
 		return err
 	}
 	// the user defined keymaps ini
 	// this is saved as the full ini already merged
-	enabled, ok := GetEnabledKeymapPath(m)
-	if !ok {
-		enabled = iniPath
-	}
+	keymap, ok := GetEnabledKeymapPath(m)
 
 	// load the saved_conf.ini file that contains toggle states
 	config, err := GetEnabledConfig(m)
@@ -461,33 +553,47 @@ func overwriteMergedIniIfneeded(m types.Mod, outputDir string, cache dbh.IniCach
 		return nil
 	}
 
-	// create a new ini file merging keymaps and saved_conf
-	writeConfigToIni := func() ([]byte, error) {
-		f, err := os.Open(enabled)
-		if err != nil {
-			return make([]byte, 0), err
+	errs := []error{}
+	for i, relPath := range relPaths {
+		iniPath := filepath.Join(outputDir, relPath)
+
+		enabled := iniPath
+		if ok && i == 0 {
+			enabled = keymap
 		}
-		defer f.Close()
+		iniConfig := configForIni(config, relPath)
 
-		s, err := OverwriteIniFiles(f, config)
-		if err != nil {
-			return make([]byte, 0), err
+		if iniConfig == nil && enabled == iniPath {
+			continue
+		}
+		if err := writeMergedIni(enabled, iniPath, iniConfig); err != nil {
+			errs = append(errs, err)
 		}
-		return []byte(s), nil
 	}
+	return errors.Join(errs...)
+}
 
+// create a new ini file merging keymaps and saved_conf
+func writeMergedIni(src, dst string, config *ini.File) error {
 	var b []byte
 	if config != nil {
-		b, err = writeConfigToIni()
+		f, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		s, err := OverwriteIniFiles(f, config)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		b = []byte(s)
 	} else {
-		b, err = os.ReadFile(enabled)
-	}
-
-	if err != nil {
-		return err
+		var err error
+		if b, err = os.ReadFile(src); err != nil {
+			return err
+		}
 	}
-
-	return os.WriteFile(iniPath, b, os.ModePerm)
+	return os.WriteFile(dst, b, os.ModePerm)
 }
 
 func getModFixExe(exported []string) string {
@@ -2,7 +2,6 @@ package core
 
 import (
 	"database/sql"
-	"hmm/db"
 	"hmm/pkg/core/dbh"
 	"hmm/pkg/util"
 	"os"
@@ -27,8 +26,7 @@ func TestConfigFileGeneration(t *testing.T) {
 		panic(err)
 	}
 
-	queries := db.New(dbSql)
-	dbHelper := dbh.NewDbHelper(queries, dbSql)
+	dbHelper := dbh.NewDbHelper(dbSql)
 
 	keymapper := NewKeymapper(dbHelper)
 
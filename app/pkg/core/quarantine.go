@@ -0,0 +1,122 @@
+package core
+
+import (
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const quarantineDir = "quarantine"
+
+var quarantineExtensions = map[string]struct{}{
+	".exe":  {},
+	".bat":  {},
+	".cmd":  {},
+	".com":  {},
+	".scr":  {},
+	".msi":  {},
+	".ps1":  {},
+	".psm1": {},
+	".vbs":  {},
+	".dll":  {},
+	".py":   {},
+	".pyw":  {},
+	".jar":  {},
+	".sh":   {},
+	".lnk":  {},
+}
+
+func isQuarantined(path string) bool {
+	_, ok := quarantineExtensions[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// moves executables and scripts found in dir to the quarantine folder of modDir keeping
+// their path relative to modDir. returns the moved paths relative to modDir
+func quarantineExecutables(modDir, dir string) ([]string, error) {
+	moved := []string{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == filepath.Join(modDir, quarantineDir) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !isQuarantined(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(modDir, path)
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(modDir, quarantineDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Rename(path, dest); err != nil {
+			return err
+		}
+
+		log.LogPrintf("quarantined %s", path)
+		moved = append(moved, filepath.ToSlash(rel))
+		return nil
+	})
+
+	return moved, err
+}
+
+// path of a quarantined file inside the generated mod, the archive root
+// (or textures/<name>/<root> for textures) is dropped. returns false for
+// textures that are not enabled
+func quarantineOutputPath(path string, textures []types.Texture) (string, bool) {
+	parts := strings.Split(path, "/")
+
+	if parts[0] == "textures" {
+		if len(parts) < 4 {
+			return "", false
+		}
+		for _, t := range textures {
+			if t.Filename == parts[1] {
+				return filepath.Join(parts[3:]...), true
+			}
+		}
+		return "", false
+	}
+
+	if len(parts) < 2 {
+		return "", false
+	}
+	return filepath.Join(parts[1:]...), true
+}
+
+// copies the quarantined files the user allowed into the generated mod dir
+func copyAllowedQuarantined(mod types.Mod, dst string, files []types.QuarantinedFile, textures []types.Texture) error {
+	for _, file := range files {
+		if !file.Allowed {
+			continue
+		}
+
+		out, ok := quarantineOutputPath(file.Path, textures)
+		if !ok || !filepath.IsLocal(out) {
+			continue
+		}
+
+		src := filepath.Join(util.GetQuarantineDir(mod), filepath.FromSlash(file.Path))
+		os.MkdirAll(filepath.Join(dst, filepath.Dir(out)), os.ModePerm)
+
+		if err := util.CopyFile(src, filepath.Join(dst, out), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
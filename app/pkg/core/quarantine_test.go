@@ -0,0 +1,67 @@
+package core
+
+import (
+	"hmm/pkg/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestQuarantineExecutables(t *testing.T) {
+
+	modDir := t.TempDir()
+	root := filepath.Join(modDir, "mod")
+	os.MkdirAll(filepath.Join(root, "tools"), os.ModePerm)
+	os.WriteFile(filepath.Join(root, "mod.ini"), []byte("[Constants]"), 0644)
+	os.WriteFile(filepath.Join(root, "tools", "fix.EXE"), []byte("MZ"), 0644)
+	os.WriteFile(filepath.Join(root, "run.bat"), []byte("echo"), 0644)
+
+	moved, err := quarantineExecutables(modDir, modDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(moved)
+	if !slices.Equal(moved, []string{"mod/run.bat", "mod/tools/fix.EXE"}) {
+		t.Fatalf("unexpected quarantined files %v", moved)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "run.bat")); err == nil {
+		t.Error("run.bat was not moved")
+	}
+	if _, err := os.Stat(filepath.Join(modDir, quarantineDir, "mod", "tools", "fix.EXE")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "mod.ini")); err != nil {
+		t.Error(err)
+	}
+
+	// already quarantined files are not moved again
+	moved, err = quarantineExecutables(modDir, modDir)
+	if err != nil || len(moved) != 0 {
+		t.Errorf("expected nothing to be moved got %v %v", moved, err)
+	}
+}
+
+func TestQuarantineOutputPath(t *testing.T) {
+	textures := []types.Texture{{Filename: "skin"}}
+
+	cases := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"mod/tools/fix.exe", filepath.Join("tools", "fix.exe"), true},
+		{"fix.exe", "", false},
+		{"textures/skin/root/a.dll", "a.dll", true},
+		{"textures/other/root/a.dll", "", false},
+	}
+
+	for _, c := range cases {
+		got, ok := quarantineOutputPath(c.path, textures)
+		if got != c.want || ok != c.ok {
+			t.Errorf("quarantineOutputPath(%s) = %s %v want %s %v", c.path, got, ok, c.want, c.ok)
+		}
+	}
+}
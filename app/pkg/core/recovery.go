@@ -0,0 +1,442 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"hmm/pkg/api"
+	"hmm/pkg/core/dbh"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	RecoveryEvent = "recovery"
+
+	RECOVERY_SOURCE_SIDECAR    = "sidecar"
+	RECOVERY_SOURCE_BACKUP     = "backup"
+	RECOVERY_SOURCE_SAVED_CONF = "saved_conf"
+	RECOVERY_SOURCE_NONE       = "none"
+)
+
+// Recovery rebuilds hmm.db from the mod library when the db can not be used
+type Recovery struct {
+	db         *dbh.DbHelper
+	sync       *SyncHelper
+	emitter    EventEmmiter
+	migrations fs.FS
+	ddl        string
+}
+
+type RecoveryEntry struct {
+	Game      types.Game `json:"game"`
+	Character string     `json:"character"`
+	Mod       string     `json:"mod"`
+	Texture   string     `json:"texture"`
+	Playlist  string     `json:"playlist"`
+	Source    string     `json:"source"`
+	Reason    string     `json:"reason"`
+}
+
+type RecoveryReport struct {
+	// db file the broken db was moved to
+	MovedDb string `json:"movedDb"`
+	// backup used to restore data missing from the sidecars, empty if none could be read
+	Backup      string          `json:"backup"`
+	Characters  int             `json:"characters"`
+	Mods        int             `json:"mods"`
+	Textures    int             `json:"textures"`
+	Playlists   int             `json:"playlists"`
+	Keymaps     int             `json:"keymaps"`
+	Restored    []RecoveryEntry `json:"restored"`
+	Unrecovered []RecoveryEntry `json:"unrecovered"`
+}
+
+func NewRecovery(db *dbh.DbHelper, sync *SyncHelper, emitter EventEmmiter, migrations fs.FS, ddl string) *Recovery {
+	return &Recovery{
+		db:         db,
+		sync:       sync,
+		emitter:    emitter,
+		migrations: migrations,
+		ddl:        ddl,
+	}
+}
+
+// RebuildDatabase replaces hmm.db with a new db filled from the characters api, the
+// mod library, sidecars, saved configs and the newest readable backup
+func (r *Recovery) RebuildDatabase() (RecoveryReport, error) {
+	report := RecoveryReport{
+		Restored:    []RecoveryEntry{},
+		Unrecovered: []RecoveryEntry{},
+	}
+
+	// collected before anything is written so rows created by sync are not counted
+	sidecars := librarySidecars()
+	backups := dbh.BackupFiles()
+
+	moved, err := r.db.RecreateDatabase(r.migrations, r.ddl)
+	report.MovedDb = moved
+	if err != nil {
+		return report, err
+	}
+
+	var backup *dbh.DbHelper
+	for _, path := range backups {
+		b, close, err := dbh.OpenBackup(path, r.migrations, r.ddl)
+		if err != nil {
+			log.LogErrorf("skipping backup %s: %s", path, err.Error())
+			continue
+		}
+		defer close()
+		backup = b
+		report.Backup = path
+		break
+	}
+
+	for _, game := range types.Games {
+		r.restoreCharacters(&report, game, backup)
+
+		if err := r.sync.Sync(game, SyncRequestLocal); err != nil {
+			log.LogError(err.Error())
+		}
+
+		if err := r.restoreMods(&report, game, backup, sidecars); err != nil {
+			log.LogError(err.Error())
+		}
+		if backup != nil {
+			r.restorePlaylists(&report, game, backup)
+		}
+	}
+
+	r.emitter.Emit(RecoveryEvent, report)
+	return report, nil
+}
+
+// paths of all sidecars in the library
+func librarySidecars() map[string]bool {
+	sidecars := map[string]bool{}
+	root := util.GetRootModDir()
+	for _, pattern := range []string{
+		filepath.Join(root, "*", "*", "*", util.SidecarFile),
+		filepath.Join(root, "*", "*", "*", "textures", "*", util.SidecarFile),
+		filepath.Join(root, "*", util.CategoriesDir, "*", "*", util.SidecarFile),
+		filepath.Join(root, "*", util.CategoriesDir, "*", "*", "textures", "*", util.SidecarFile),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			sidecars[m] = true
+		}
+	}
+	return sidecars
+}
+
+func (r *Recovery) restoreCharacters(report *RecoveryReport, game types.Game, backup *dbh.DbHelper) {
+	characters := []types.Character{}
+	if backup != nil {
+		characters, _ = backup.SelectCharactersByGame(game)
+	}
+	if dataApi, ok := api.ApiList[game]; ok {
+		characters = append(characters, dataApi.Characters()...)
+		characters = append(characters, dataApi.Categories()...)
+	}
+
+	names := []string{}
+	for _, c := range characters {
+		if err := r.db.UpsertCharacter(c); err == nil && !c.IsCategory() && !slices.Contains(names, c.Name) {
+			names = append(names, c.Name)
+		}
+	}
+
+	r.restoreAliases(game, backup)
+	suggestions := suggestCharacterDirs(r.db, game)
+
+	// dirs of custom characters that were not in the backup, custom
+	// categories are created by sync
+	entries, _ := os.ReadDir(util.GetGameDir(game))
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == util.CategoriesDir || slices.Contains(names, e.Name()) {
+			continue
+		}
+		if err := r.db.CreateCustomCharacter(e.Name(), "", "", game); err != nil {
+			log.LogError(err.Error())
+			continue
+		}
+		names = append(names, e.Name())
+
+		reason := "created as a custom character without an image or element"
+		if i := slices.IndexFunc(suggestions, func(s CharacterDirSuggestion) bool { return s.Dir == e.Name() }); i != -1 {
+			reason += fmt.Sprintf(", it may be %s", suggestions[i].Character)
+		}
+		report.Unrecovered = append(report.Unrecovered, RecoveryEntry{
+			Game:      game,
+			Character: e.Name(),
+			Source:    RECOVERY_SOURCE_NONE,
+			Reason:    reason,
+		})
+	}
+
+	report.Characters += len(names)
+}
+
+// bundled aliases are seeded again, user aliases and disabled bundled ones come from the backup
+func (r *Recovery) restoreAliases(game types.Game, backup *dbh.DbHelper) {
+	if err := r.db.SeedCharacterAliases(game, api.CharacterAliases(game)); err != nil {
+		log.LogError(err.Error())
+	}
+	if backup == nil {
+		return
+	}
+
+	aliases, _ := backup.SelectCharacterAliases(game)
+	for _, a := range aliases {
+		var err error
+		switch {
+		case a.Custom:
+			err = r.db.AddCharacterAlias(game, a.CharacterId, a.Alias)
+		case a.Disabled:
+			err = r.db.DeleteCharacterAlias(game, a.Alias)
+		}
+		if err != nil {
+			log.LogError(err.Error())
+		}
+	}
+}
+
+func (r *Recovery) restoreMods(report *RecoveryReport, game types.Game, backup *dbh.DbHelper, sidecars map[string]bool) error {
+	mods, err := r.db.SelectModsByGame(game)
+	if err != nil {
+		return err
+	}
+
+	backupMods := map[string]types.Mod{}
+	if backup != nil {
+		bm, _ := backup.SelectModsByGame(game)
+		for _, m := range bm {
+			backupMods[m.Character+"/"+m.Filename] = m
+		}
+	}
+
+	for _, mod := range mods {
+		report.Mods++
+		modDir := util.GetModDir(mod)
+		entry := RecoveryEntry{
+			Game:      game,
+			Character: mod.Character,
+			Mod:       mod.Filename,
+		}
+
+		backupMod, inBackup := backupMods[mod.Character+"/"+mod.Filename]
+		delete(backupMods, mod.Character+"/"+mod.Filename)
+
+		switch {
+		case sidecars[filepath.Join(modDir, util.SidecarFile)]:
+			entry.Source = RECOVERY_SOURCE_SIDECAR
+			report.Restored = append(report.Restored, entry)
+		case inBackup:
+			if err := r.restoreModFromBackup(mod, backupMod, backup); err != nil {
+				entry.Source = RECOVERY_SOURCE_NONE
+				entry.Reason = err.Error()
+				report.Unrecovered = append(report.Unrecovered, entry)
+			} else {
+				entry.Source = RECOVERY_SOURCE_BACKUP
+				report.Restored = append(report.Restored, entry)
+			}
+		default:
+			// saved_conf.ini is only written for mods that were enabled when generating
+			if exists, _ := util.FileExists(filepath.Join(util.GetModConfigCache(mod), SavedConf)); exists {
+				r.db.UpdateModEnabledById(true, mod.Id)
+				entry.Source = RECOVERY_SOURCE_SAVED_CONF
+				entry.Reason = "only the enabled state was restored"
+			} else {
+				entry.Source = RECOVERY_SOURCE_NONE
+				entry.Reason = "no sidecar or backup entry"
+			}
+			report.Unrecovered = append(report.Unrecovered, entry)
+		}
+
+		if _, ok := GetEnabledKeymapPath(mod); ok {
+			report.Keymaps++
+		}
+
+		if quarantined := quarantinedOnDisk(mod); len(quarantined) > 0 {
+			if err := r.db.InsertQuarantinedFiles(mod.Id, quarantined); err != nil {
+				log.LogError(err.Error())
+			}
+			if inBackup {
+				files, _ := backup.SelectQuarantinedFiles(backupMod.Id)
+				for _, f := range files {
+					if f.Allowed && slices.Contains(quarantined, f.Path) {
+						r.db.UpdateQuarantineAllowed(mod.Id, f.Path, true)
+					}
+				}
+			}
+		}
+
+		r.restoreTextures(report, mod, backupMod, inBackup, backup, sidecars)
+	}
+
+	for _, m := range backupMods {
+		report.Unrecovered = append(report.Unrecovered, RecoveryEntry{
+			Game:      game,
+			Character: m.Character,
+			Mod:       m.Filename,
+			Source:    RECOVERY_SOURCE_BACKUP,
+			Reason:    "files not found in the library",
+		})
+	}
+
+	return nil
+}
+
+func (r *Recovery) restoreModFromBackup(mod, backupMod types.Mod, backup *dbh.DbHelper) error {
+	tags, err := backup.SelectTagsByModId(int64(backupMod.Id))
+	if err != nil {
+		return err
+	}
+	tagNames := make([]string, len(tags))
+	for i, t := range tags {
+		tagNames[i] = t.Name
+	}
+
+	err = r.db.ApplyModSidecar(mod.Id, dbh.ModSidecar{
+		Version:        dbh.SIDECAR_VERSION,
+		Enabled:        backupMod.Enabled,
+		PreviewImages:  backupMod.PreviewImages,
+		GbId:           backupMod.GbId,
+		ModLink:        backupMod.ModLink,
+		GbFileName:     backupMod.GbFileName,
+		GbDownloadLink: backupMod.GbDownloadLink,
+		Tags:           tagNames,
+	})
+	if err != nil {
+		return err
+	}
+
+	passwords, _ := backup.SelectArchivePasswords(dbh.PASSWORD_SCOPE_MOD, backupMod.Id)
+	for _, p := range passwords {
+		r.db.InsertArchivePassword(dbh.PASSWORD_SCOPE_MOD, mod.Id, p)
+	}
+	return nil
+}
+
+func (r *Recovery) restoreTextures(
+	report *RecoveryReport,
+	mod, backupMod types.Mod,
+	inBackup bool,
+	backup *dbh.DbHelper,
+	sidecars map[string]bool,
+) {
+	textures, err := r.db.SelectTexturesByModId(mod.Id)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+
+	backupTextures := []types.Texture{}
+	if inBackup {
+		backupTextures, _ = backup.SelectTexturesByModId(backupMod.Id)
+	}
+
+	for _, texture := range textures {
+		report.Textures++
+		entry := RecoveryEntry{
+			Game:      mod.Game,
+			Character: mod.Character,
+			Mod:       mod.Filename,
+			Texture:   texture.Filename,
+		}
+
+		if sidecars[filepath.Join(util.GetModDir(mod), "textures", texture.Filename, util.SidecarFile)] {
+			entry.Source = RECOVERY_SOURCE_SIDECAR
+			report.Restored = append(report.Restored, entry)
+			continue
+		}
+
+		i := slices.IndexFunc(backupTextures, func(t types.Texture) bool { return t.Filename == texture.Filename })
+		if i == -1 {
+			entry.Source = RECOVERY_SOURCE_NONE
+			entry.Reason = "no sidecar or backup entry"
+			report.Unrecovered = append(report.Unrecovered, entry)
+			continue
+		}
+
+		t := backupTextures[i]
+		err := r.db.ApplyTextureSidecar(texture.Id, dbh.TextureSidecar{
+			Version:        dbh.SIDECAR_VERSION,
+			Enabled:        t.Enabled,
+			PreviewImages:  t.PreviewImages,
+			GbId:           t.GbId,
+			ModLink:        t.ModLink,
+			GbFileName:     t.GbFileName,
+			GbDownloadLink: t.GbDownloadLink,
+		})
+		if err != nil {
+			entry.Source = RECOVERY_SOURCE_NONE
+			entry.Reason = err.Error()
+			report.Unrecovered = append(report.Unrecovered, entry)
+			continue
+		}
+		entry.Source = RECOVERY_SOURCE_BACKUP
+		report.Restored = append(report.Restored, entry)
+	}
+}
+
+func (r *Recovery) restorePlaylists(report *RecoveryReport, game types.Game, backup *dbh.DbHelper) {
+	playlists, err := backup.SelectPlaylistWithModsAndTags(game)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+
+	for _, p := range playlists {
+		ids := []int{}
+		for _, m := range p.ModsWithTags {
+			mod, err := r.db.SelectModByFileCharacterGame(m.Mod.Filename, m.Mod.Character, game)
+			if err != nil {
+				report.Unrecovered = append(report.Unrecovered, RecoveryEntry{
+					Game:      game,
+					Character: m.Mod.Character,
+					Mod:       m.Mod.Filename,
+					Playlist:  p.Playlist.Name,
+					Source:    RECOVERY_SOURCE_BACKUP,
+					Reason:    "mod of the playlist not found in the library",
+				})
+				continue
+			}
+			if !slices.Contains(ids, mod.Id) {
+				ids = append(ids, mod.Id)
+			}
+		}
+
+		if err := r.db.InsertPlaylistWithMods(game, p.Playlist.Name, ids); err != nil {
+			log.LogError(err.Error())
+			continue
+		}
+		report.Playlists++
+	}
+}
+
+// quarantined files stay on disk so their rows can be rebuilt from the quarantine dir
+func quarantinedOnDisk(mod types.Mod) []string {
+	dir := util.GetQuarantineDir(mod)
+	paths := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.LogError(err.Error())
+	}
+	return paths
+}
@@ -6,13 +6,17 @@ import (
 	"hmm/pkg/api"
 	"hmm/pkg/core/dbh"
 	"hmm/pkg/log"
+	"hmm/pkg/pref"
 	"hmm/pkg/types"
 	"hmm/pkg/util"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/alitto/pond/v2"
 )
@@ -21,7 +25,10 @@ const (
 	StartupRequest          SyncRequest = 0
 	SyncRequestLocal        SyncRequest = 1
 	SyncRequestForceNetwork SyncRequest = 2
+	SyncRequestWatcher      SyncRequest = 3
+	SyncRequestConfirm      SyncRequest = 4
 	SyncEvent                           = "sync"
+	SyncReportEvent                     = "sync_report"
 )
 
 type SyncRequest int
@@ -32,6 +39,69 @@ type SyncHelper struct {
 	initialComplete map[types.Game]*sync.Once
 	emitter         EventEmmiter
 	notifier        Notifier
+	// days a row with user data is kept after its dir is gone, 0 waits for confirmation
+	graceDays pref.Preference[int]
+	// Game=version of the bundled characters each game was last seeded with
+	dataVersions pref.Preference[[]string]
+	reportMutex  sync.RWMutex
+	reports      map[types.Game]SyncReport
+}
+
+type SyncReportEntry struct {
+	ModId     int    `json:"modId"`
+	TextureId int    `json:"textureId"`
+	Character string `json:"character"`
+	Filename  string `json:"filename"`
+	// only set for renames
+	PrevFilename string `json:"prevFilename,omitempty"`
+	// only set for mods moved to another character
+	PrevCharacter string `json:"prevCharacter,omitempty"`
+}
+
+// SyncReport lists what a sync changed, rows in Pending were not found on disk
+// but hold user data and are kept until confirmed or the grace period ends
+type SyncReport struct {
+	Game    types.Game        `json:"game"`
+	Request SyncRequest       `json:"request"`
+	Time    time.Time         `json:"time"`
+	Added   []SyncReportEntry `json:"added"`
+	Removed []SyncReportEntry `json:"removed"`
+	Renamed []SyncReportEntry `json:"renamed"`
+	Pending []SyncReportEntry `json:"pending"`
+	// rows that were filled from the sidecar in the dir
+	Repaired []SyncReportEntry `json:"repaired"`
+	// dirs that are not a character but look like one, see AdoptCharacterDir
+	Suggested []CharacterDirSuggestion `json:"suggested"`
+}
+
+// a dir in the game dir whose name matches a character
+type CharacterDirSuggestion struct {
+	Dir         string `json:"dir"`
+	CharacterId int    `json:"characterId"`
+	Character   string `json:"character"`
+}
+
+type pendingKey struct {
+	kind dbh.PendingDeleteKind
+	id   int
+}
+
+// state shared by a single sync of a game
+type syncPass struct {
+	game            types.Game
+	report          SyncReport
+	pending         map[pendingKey]time.Time
+	textures        map[int][]types.Texture
+	fingerprints    map[int]string
+	missingMods     []types.Mod
+	missingTextures []types.Texture
+	// every row change of the pass, written by applyPass
+	batch *dbh.SyncBatch
+	// character names of the mods in batch.Textures for the report
+	modCharacters map[int]string
+	// rows with metadata whose sidecar file is missing
+	modSidecars     []int
+	textureSidecars []int
 }
 
 func (s *SyncHelper) RunAll(request SyncRequest) {
@@ -51,11 +121,20 @@ func (s *SyncHelper) RunAll(request SyncRequest) {
 	wg.Wait()
 }
 
-func NewSyncHelper(db *dbh.DbHelper, emitter EventEmmiter, notifier Notifier) *SyncHelper {
+func NewSyncHelper(
+	db *dbh.DbHelper,
+	emitter EventEmmiter,
+	notifier Notifier,
+	graceDays pref.Preference[int],
+	dataVersions pref.Preference[[]string],
+) *SyncHelper {
 	return &SyncHelper{
-		db:       db,
-		emitter:  emitter,
-		notifier: notifier,
+		db:           db,
+		emitter:      emitter,
+		notifier:     notifier,
+		graceDays:    graceDays,
+		dataVersions: dataVersions,
+		reports:      map[types.Game]SyncReport{},
 		running: map[types.Game]pond.Pool{
 			types.Genshin:  pond.NewPool(1),
 			types.StarRail: pond.NewPool(1),
@@ -71,6 +150,39 @@ func NewSyncHelper(db *dbh.DbHelper, emitter EventEmmiter, notifier Notifier) *S
 	}
 }
 
+// LastSyncReport returns the report of the last sync for the game
+func (s *SyncHelper) LastSyncReport(game types.Game) SyncReport {
+	s.reportMutex.RLock()
+	defer s.reportMutex.RUnlock()
+
+	if report, ok := s.reports[game]; ok {
+		return report
+	}
+	return newSyncReport(game, SyncRequestLocal)
+}
+
+func (s *SyncHelper) publishReport(report SyncReport) {
+	s.reportMutex.Lock()
+	s.reports[report.Game] = report
+	s.reportMutex.Unlock()
+
+	s.emitter.Emit(SyncReportEvent, report)
+}
+
+func newSyncReport(game types.Game, request SyncRequest) SyncReport {
+	return SyncReport{
+		Game:      game,
+		Request:   request,
+		Time:      time.Now(),
+		Added:     []SyncReportEntry{},
+		Removed:   []SyncReportEntry{},
+		Renamed:   []SyncReportEntry{},
+		Pending:   []SyncReportEntry{},
+		Repaired:  []SyncReportEntry{},
+		Suggested: []CharacterDirSuggestion{},
+	}
+}
+
 func (s *SyncHelper) Sync(game types.Game, request SyncRequest) error {
 	dataApi, ok := api.ApiList[game]
 	if !ok {
@@ -95,8 +207,6 @@ func (s *SyncHelper) Sync(game types.Game, request SyncRequest) error {
 
 	task := pool.SubmitErr(func() error {
 
-		seenMods := []string{}
-		modIdToSeenTextures := map[int][]string{}
 		characters, err := s.db.SelectCharactersByGame(game)
 		if err != nil {
 			return err
@@ -104,113 +214,888 @@ func (s *SyncHelper) Sync(game types.Game, request SyncRequest) error {
 		log.LogPrint(fmt.Sprintf("characters size: %d synctype: %d game: %d", len(characters), request, game))
 
 		if len(characters) <= 0 || request == SyncRequestForceNetwork {
-			characters = dataApi.Characters()
-			for _, c := range characters {
-
-				if c.Id != 0 && c.Name != "" {
-					log.LogPrint("inserting " + c.Name)
-
-					err := s.db.UpsertCharacter(c)
+			upsertCharacters(s.db, characters, dataApi.Characters(), false)
+			s.setCharacterDataVersion(game)
+		} else if s.characterDataVersion(game) < api.CharacterDataVersion() {
+			// add the characters of a newer bundled dataset without going to the network
+			upsertCharacters(s.db, characters, api.BundledCharacters(game), true)
+			s.setCharacterDataVersion(game)
+		}
+		// reload so custom characters and categories are synced too
+		characters, err = s.db.SelectCharactersByGame(game)
+		if err != nil {
+			return err
+		}
 
-					if err != nil {
-						log.LogPrint(err.Error())
-					}
+		if !slices.ContainsFunc(characters, types.Character.IsCategory) {
+			for _, c := range dataApi.Categories() {
+				if err := s.db.UpsertCharacter(c); err != nil {
+					log.LogPrint(err.Error())
+					continue
 				}
+				characters = append(characters, c)
 			}
 		}
 
+		if err := s.db.SeedCharacterAliases(game, api.CharacterAliases(game)); err != nil {
+			log.LogError(err.Error())
+		}
+
 		gameDir := util.GetGameDir(game)
 		os.MkdirAll(gameDir, 0777)
 
-		file, err := os.Open(gameDir)
+		characters = append(characters, s.syncCategoryDirs(game, characters)...)
+
+		mods, err := s.db.SelectModsByGame(game)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
 
-		for _, character := range characters {
+		pass, err := s.newSyncPass(game, request)
+		if err != nil {
+			return err
+		}
+
+		if err := s.syncLibrary(pass, characters, mods); err != nil {
+			return err
+		}
+		s.publishReport(pass.report)
 
-			if character.Name == "" {
+		return nil
+	})
+
+	return task.Wait()
+}
+
+// reads the dirs of the characters, diffs them against the rows and applies the
+// changes in one transaction
+func (s *SyncHelper) syncLibrary(pass *syncPass, characters []types.Character, mods []types.Mod) error {
+	s.syncCharacters(pass, characters, mods)
+	if err := s.applyPass(pass); err != nil {
+		return err
+	}
+	s.syncDependencies(pass)
+	return nil
+}
+
+// dirs in util.CategoriesDir without a row are added as custom categories
+func (s *SyncHelper) syncCategoryDirs(game types.Game, characters []types.Character) []types.Character {
+	categoriesDir := filepath.Join(util.GetGameDir(game), util.CategoriesDir)
+	os.MkdirAll(categoriesDir, 0777)
+
+	entries, err := os.ReadDir(categoriesDir)
+	if err != nil {
+		return []types.Character{}
+	}
+
+	created := []types.Character{}
+	for _, e := range entries {
+		exists := slices.ContainsFunc(characters, func(c types.Character) bool {
+			return c.IsCategory() && c.Name == e.Name()
+		})
+		if !e.IsDir() || exists {
+			continue
+		}
+
+		if err := s.db.CreateCustomCategory(e.Name(), types.CATEGORY_OTHER, game); err != nil {
+			log.LogError(err.Error())
+			continue
+		}
+		created = append(created, types.Character{
+			Id:       util.CategoryId(e.Name()),
+			Game:     game,
+			Name:     e.Name(),
+			Custom:   true,
+			Category: types.CATEGORY_OTHER,
+		})
+	}
+	return created
+}
+
+func (s *SyncHelper) characterDataVersion(game types.Game) int {
+	for _, entry := range s.dataVersions.Get() {
+		name, version, ok := strings.Cut(entry, "=")
+		if ok && name == game.Name() {
+			v, _ := strconv.Atoi(version)
+			return v
+		}
+	}
+	return 0
+}
+
+func (s *SyncHelper) setCharacterDataVersion(game types.Game) {
+	versions := slices.DeleteFunc(slices.Clone(s.dataVersions.Get()), func(entry string) bool {
+		return strings.HasPrefix(entry, game.Name()+"=")
+	})
+	s.dataVersions.Set(append(versions, fmt.Sprintf("%s=%d", game.Name(), api.CharacterDataVersion())))
+}
+
+// upsertCharacters saves the fetched or bundled characters. a character that is already saved
+// under another id or name, like the bundled Anby Demara and the fetched Anby, keeps the saved
+// id and name so its mod dir and links stay the same. onlyMissing skips characters that are saved
+func upsertCharacters(db *dbh.DbHelper, existing []types.Character, incoming []types.Character, onlyMissing bool) {
+	for _, c := range incoming {
+		if c.Id == 0 || c.Name == "" {
+			continue
+		}
+
+		i := slices.IndexFunc(existing, func(e types.Character) bool {
+			return !e.Custom && !e.IsCategory() &&
+				(e.Id == c.Id || api.SameCharacter(c.Game, e.Name, c.Name))
+		})
+		if i != -1 {
+			if onlyMissing {
 				continue
 			}
+			c.Id, c.Name = existing[i].Id, existing[i].Name
+		}
+
+		log.LogPrint("inserting " + c.Name)
+		if err := db.UpsertCharacter(c); err != nil {
+			log.LogPrint(err.Error())
+		}
+	}
+}
+
+// suggestCharacterDirs matches dirs of the game dir that are not a character against the
+// characters, like Raiden to Raiden Shogun. nothing is moved until AdoptCharacterDir is called
+func suggestCharacterDirs(db *dbh.DbHelper, game types.Game) []CharacterDirSuggestion {
+	suggestions := []CharacterDirSuggestion{}
+	entries, err := os.ReadDir(util.GetGameDir(game))
+	if err != nil {
+		return suggestions
+	}
+	characters, err := db.SelectCharactersByGame(game)
+	if err != nil {
+		return suggestions
+	}
+
+	for _, e := range entries {
+		known := slices.ContainsFunc(characters, func(c types.Character) bool {
+			return !c.IsCategory() && strings.EqualFold(c.Name, e.Name())
+		})
+		if !e.IsDir() || e.Name() == util.CategoriesDir || known {
+			continue
+		}
+
+		character, err := db.SelectClosestCharacter(e.Name(), game)
+		if err != nil || character.IsCategory() {
+			continue
+		}
+		suggestions = append(suggestions, CharacterDirSuggestion{
+			Dir:         e.Name(),
+			CharacterId: character.Id,
+			Character:   character.Name,
+		})
+	}
+	return suggestions
+}
+
+// AdoptCharacterDir moves the mods of a dir in the game dir that is not a character into
+// the character, the emptied dir is removed and the game is synced
+func (s *SyncHelper) AdoptCharacterDir(game types.Game, dir string, characterId int) error {
+	character, err := s.db.SelectCharacterById(characterId, game)
+	if err != nil {
+		return err
+	}
+	if err := adoptCharacterDir(dir, character); err != nil {
+		return err
+	}
+	return s.Sync(game, SyncRequestLocal)
+}
+
+func adoptCharacterDir(dir string, character types.Character) error {
+	if dir == "" || dir != filepath.Base(dir) || dir == util.CategoriesDir {
+		return fmt.Errorf("invalid dir %s", dir)
+	}
+
+	src := filepath.Join(util.GetGameDir(character.Game), dir)
+	charDir := util.GetCharacterOrCategoryDir(character)
+	if src == charDir {
+		return nil
+	}
+	if err := os.MkdirAll(charDir, 0777); err != nil {
+		return err
+	}
+	log.LogPrint(fmt.Sprintf("moving the mods of %s into %s", dir, character.Name))
+
+	for _, mod := range sortedDirNames(src) {
+		if err := os.Rename(filepath.Join(src, mod), findUniqueDirName(filepath.Join(charDir, mod))); err != nil {
+			return err
+		}
+	}
+	return os.Remove(src)
+}
+
+// SyncChanges syncs only the character and mod dirs affected by changes
+// instead of the full game dir
+func (s *SyncHelper) SyncChanges(game types.Game, changes []LibraryChange) error {
+
+	characters, err := s.db.SelectCharactersByGame(game)
+	if err != nil {
+		return err
+	}
+
+	defer s.emitter.Emit(SyncEvent, game, SyncRequestWatcher, changes)
+
+	task := s.running[game].SubmitErr(func() error {
+		characters = append(characters, s.syncCategoryDirs(game, characters)...)
+
+		// a changed character dir syncs all of its mods, otherwise only the textures
+		// of the changed mods are synced. changes are keyed by the character or category dir
+		characterDirs := map[string]bool{}
+		modDirs := map[string][]string{}
+		for _, change := range changes {
+			dir := change.characterDir()
+			if change.Mod == "" || change.Texture == "" {
+				characterDirs[dir] = true
+			} else if !slices.Contains(modDirs[dir], change.Mod) {
+				modDirs[dir] = append(modDirs[dir], change.Mod)
+			}
+		}
+
+		pass, err := s.newSyncPass(game, SyncRequestWatcher)
+		if err != nil {
+			return err
+		}
 
-			charDir := util.GetCharacterDir(character.Name, game)
-			os.MkdirAll(charDir, 0777)
+		rows, err := s.db.SelectModsByGame(game)
+		if err != nil {
+			return err
+		}
+		modsByCharacter := map[int][]types.Mod{}
+		for _, mod := range rows {
+			modsByCharacter[mod.CharacterId] = append(modsByCharacter[mod.CharacterId], mod)
+		}
 
-			file, err := os.Open(charDir)
-			if err != nil {
+		// changed characters are synced together so mods moved between them keep their rows
+		changed := []types.Character{}
+		mods := []types.Mod{}
+		for _, character := range characters {
+			dir := util.GetCharacterOrCategoryDir(character)
+			if characterDirs[dir] {
+				changed = append(changed, character)
+				mods = append(mods, modsByCharacter[character.Id]...)
 				continue
 			}
-			defer file.Close()
 
-			modDirs, err := file.Readdirnames(-1)
-			modDirs = slices.DeleteFunc(modDirs, func(e string) bool { return e == "textures" })
+			for _, mod := range modsByCharacter[character.Id] {
+				if slices.Contains(modDirs[dir], mod.Filename) {
+					s.syncTextures(pass, mod, sortedDirNames(filepath.Join(util.GetModDir(mod), "textures")))
+				}
+			}
+		}
+
+		if err := s.syncLibrary(pass, changed, mods); err != nil {
+			return err
+		}
+		s.publishReport(pass.report)
+		return nil
+	})
+
+	return task.Wait()
+}
+
+// ConfirmPendingDeletes deletes every row of the game that is waiting for confirmation
+func (s *SyncHelper) ConfirmPendingDeletes(game types.Game) (SyncReport, error) {
+
+	defer s.emitter.Emit(SyncEvent, game, SyncRequestConfirm)
+
+	report := s.LastSyncReport(game)
+	report.Request = SyncRequestConfirm
+	report.Time = time.Now()
+
+	task := s.running[game].SubmitErr(func() error {
+		pending, err := s.db.SelectPendingDeletes(game)
+		if err != nil {
+			return err
+		}
+
+		batch := dbh.NewSyncBatch(game)
+		for _, p := range pending {
+			switch p.Kind {
+			case dbh.PENDING_DELETE_MOD:
+				batch.DeleteMods = append(batch.DeleteMods, p.Id)
+			case dbh.PENDING_DELETE_TEXTURE:
+				batch.DeleteTextures = append(batch.DeleteTextures, p.Id)
+			}
+		}
+		batch.DeletePending = pending
+
+		if err := s.db.ApplySyncBatch(batch); err != nil {
+			return err
+		}
+
+		report.Removed = append(report.Removed, report.Pending...)
+		report.Pending = []SyncReportEntry{}
+		return nil
+	})
+
+	if err := task.Wait(); err != nil {
+		return report, err
+	}
+
+	s.publishReport(report)
+	return report, nil
+}
+
+func (s *SyncHelper) newSyncPass(game types.Game, request SyncRequest) (*syncPass, error) {
+	textures, err := s.db.SelectTexturesByGame(game)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.db.SelectPendingDeletes(game)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints, err := s.db.SelectModFingerprints(game)
+	if err != nil {
+		return nil, err
+	}
+
+	pass := &syncPass{
+		game:          game,
+		report:        newSyncReport(game, request),
+		pending:       make(map[pendingKey]time.Time, len(pending)),
+		textures:      map[int][]types.Texture{},
+		fingerprints:  fingerprints,
+		batch:         dbh.NewSyncBatch(game),
+		modCharacters: map[int]string{},
+	}
+	pass.report.Suggested = suggestCharacterDirs(s.db, game)
+
+	for _, p := range pending {
+		pass.pending[pendingKey{p.Kind, p.Id}] = p.Since
+	}
+	for _, t := range textures {
+		pass.textures[t.ModId] = append(pass.textures[t.ModId], t)
+	}
+
+	return pass, nil
+}
+
+// a row that is found on disk again is no longer waiting to be deleted
+func (s *SyncHelper) markSeen(pass *syncPass, kind dbh.PendingDeleteKind, id int) {
+	key := pendingKey{kind, id}
+	if _, ok := pass.pending[key]; ok {
+		delete(pass.pending, key)
+		pass.batch.DeletePending = append(pass.batch.DeletePending, dbh.PendingDelete{Kind: kind, Id: id})
+	}
+}
+
+// splits the rows into the ones found in dirs and the missing ones, added are
+// the dirs without a row
+func splitDirs[T any](rows []T, dirs []string, filename func(T) string) (matched []T, added []string, missing []T) {
+	for _, row := range rows {
+		if slices.Contains(dirs, filename(row)) {
+			matched = append(matched, row)
+		} else {
+			missing = append(missing, row)
+		}
+	}
+	for _, dir := range dirs {
+		if !slices.ContainsFunc(rows, func(row T) bool { return filename(row) == dir }) {
+			added = append(added, dir)
+		}
+	}
+	return matched, added, missing
+}
 
-			if err != nil {
+// diffs the dir names against the rows by filename, a new dir that is the same row
+// as a missing one is treated as a rename so the row keeps its data
+func diffDirs[T any](
+	rows []T,
+	dirs []string,
+	filename func(T) string,
+	sameRow func(T, string) bool,
+) (matched []T, added []string, missing []T, renamed []types.Pair[T, string]) {
+	matched, dirs, missing = splitDirs(rows, dirs, filename)
+
+	added = []string{}
+	for _, dir := range dirs {
+		i := slices.IndexFunc(missing, func(row T) bool { return sameRow(row, dir) })
+		if i == -1 {
+			added = append(added, dir)
+			continue
+		}
+		renamed = append(renamed, types.PairOf(missing[i], dir))
+		missing = slices.Delete(missing, i, i+1)
+	}
+	return matched, added, missing, renamed
+}
+
+// a mod dir without a row
+type addedModDir struct {
+	character   types.Character
+	filename    string
+	fingerprint string
+	textures    []string
+}
+
+// the entries of dir sorted by name, empty if it can not be read
+func sortedDirNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{}
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// reads the mod dirs of every character and the texture dirs inside them before any
+// row is compared, keyed by character id and mod dir. characters whose dir can not
+// be read are left out
+func scanCharacters(characters []types.Character) map[int]map[string][]string {
+	scan := make(map[int]map[string][]string, len(characters))
+
+	for _, character := range characters {
+		if character.Name == "" {
+			continue
+		}
+
+		charDir := util.GetCharacterOrCategoryDir(character)
+		os.MkdirAll(charDir, 0777)
+
+		entries, err := os.ReadDir(charDir)
+		if err != nil {
+			continue
+		}
+
+		modDirs := make(map[string][]string, len(entries))
+		for _, e := range entries {
+			if e.Name() == "textures" {
 				continue
 			}
+			modDirs[e.Name()] = sortedDirNames(filepath.Join(charDir, e.Name(), "textures"))
+		}
+		scan[character.Id] = modDirs
+	}
+	return scan
+}
 
-			for _, modFilename := range modDirs {
+// mods are the existing rows for the characters, rows of characters
+// that are not in the list are also treated as missing
+func (s *SyncHelper) syncCharacters(pass *syncPass, characters []types.Character, mods []types.Mod) {
 
-				seenMods = append(seenMods, modFilename)
-				mod := types.Mod{
-					Filename:    modFilename,
-					Game:        game,
-					CharacterId: character.Id,
-					Character:   character.Name,
-					Enabled:     false,
-				}
+	scan := scanCharacters(characters)
 
-				modId, err := s.db.InsertMod(mod)
-				mod = types.Mod{
-					Filename:    modFilename,
-					Game:        game,
-					CharacterId: character.Id,
-					Character:   character.Name,
-					Enabled:     false,
-					Id:          int(modId),
-				}
+	modsByCharacter := map[int][]types.Mod{}
+	for _, mod := range mods {
+		modsByCharacter[mod.CharacterId] = append(modsByCharacter[mod.CharacterId], mod)
+	}
 
-				if err != nil {
-					m, err := s.db.SelectModByFileCharacterGame(mod.Filename, mod.Character, mod.Game)
-					mod = m
-					if err != nil {
-						continue
-					}
-				}
+	added := []addedModDir{}
+	missing := []types.Mod{}
 
-				modDir := util.GetModDir(mod)
-				texturesDir := filepath.Join(modDir, "textures")
-				tDirs, err := os.ReadDir(texturesDir)
+	for _, character := range characters {
 
-				if err != nil {
-					continue
-				}
+		if character.Name == "" {
+			continue
+		}
 
-				for _, tdir := range tDirs {
-
-					if entry, ok := modIdToSeenTextures[mod.Id]; ok {
-						modIdToSeenTextures[mod.Id] = append(entry, tdir.Name())
-					} else {
-						modIdToSeenTextures[mod.Id] = []string{tdir.Name()}
-					}
-					texture := types.Texture{
-						Filename: tdir.Name(),
-						ModId:    mod.Id,
-					}
-					s.db.InsertTexture(texture)
+		rows := modsByCharacter[character.Id]
+		delete(modsByCharacter, character.Id)
+
+		modDirs, ok := scan[character.Id]
+		if !ok {
+			continue
+		}
+
+		matched, addedDirs, missingRows := splitDirs(rows, slices.Sorted(maps.Keys(modDirs)), func(m types.Mod) string { return m.Filename })
+
+		for _, mod := range matched {
+			s.syncExistingMod(pass, mod, modDirs[mod.Filename])
+		}
+		for _, fname := range addedDirs {
+			added = append(added, addedModDir{character: character, filename: fname, textures: modDirs[fname]})
+		}
+		missing = append(missing, missingRows...)
+	}
+
+	for _, rows := range modsByCharacter {
+		missing = append(missing, rows...)
+	}
+
+	added, missing = s.matchMovedMods(pass, added, missing)
+
+	for _, dir := range added {
+		mod := types.Mod{
+			Filename:    dir.filename,
+			Game:        pass.game,
+			CharacterId: dir.character.Id,
+			Character:   dir.character.Name,
+			Category:    dir.character.IsCategory(),
+			Enabled:     false,
+		}
+		modDir := util.GetModDir(mod)
+
+		if size, err := util.DirSize(modDir); err == nil {
+			mod.Size = size
+		} else {
+			log.LogError(err.Error())
+		}
+
+		textures := make([]dbh.SyncTexture, len(dir.textures))
+		for i, fname := range dir.textures {
+			textures[i] = dbh.SyncTexture{
+				Texture: types.Texture{Filename: fname},
+				Sidecar: readNewSidecar(filepath.Join(modDir, "textures", fname), dbh.ReadTextureSidecar),
+			}
+		}
+
+		pass.batch.Mods = append(pass.batch.Mods, dbh.SyncMod{
+			Mod:         mod,
+			Fingerprint: dir.fingerprint,
+			Sidecar:     readNewSidecar(modDir, dbh.ReadModSidecar),
+			Textures:    textures,
+		})
+	}
+
+	pass.missingMods = append(pass.missingMods, missing...)
+}
+
+// existing mods are only measured while their size is unknown, the enricher refreshes the rest,
+// walking every mod dir on each sync would be slow for large libraries. the fingerprint only
+// hashes the heads of the files and is refreshed so edits in place invalidate the ini caches
+func (s *SyncHelper) syncExistingMod(pass *syncPass, mod types.Mod, textures []string) {
+	s.markSeen(pass, dbh.PENDING_DELETE_MOD, mod.Id)
+
+	if fingerprint, err := modFingerprint(util.GetModDir(mod)); err == nil && fingerprint != "" && pass.fingerprints[mod.Id] != fingerprint {
+		pass.batch.Fingerprints[mod.Id] = fingerprint
+		pass.fingerprints[mod.Id] = fingerprint
+	}
+	if mod.Size == 0 {
+		if size, err := util.DirSize(util.GetModDir(mod)); err == nil {
+			pass.batch.Sizes[mod.Id] = size
+		} else {
+			log.LogError(err.Error())
+		}
+	}
+
+	s.syncModSidecar(pass, mod)
+	s.syncTextures(pass, mod, textures)
+}
+
+// finds the rows of renamed or moved mod dirs by the id in their sidecar or their
+// fingerprint, the rows are updated in place so ids used by playlists and exports stay
+// the same. dirs that could not be matched are added and the missing rows removed
+func (s *SyncHelper) matchMovedMods(pass *syncPass, added []addedModDir, missing []types.Mod) ([]addedModDir, []types.Mod) {
+	if len(missing) == 0 {
+		for i := range added {
+			added[i].fingerprint, _ = modFingerprint(filepath.Join(util.GetCharacterOrCategoryDir(added[i].character), added[i].filename))
+		}
+		return added, missing
+	}
+
+	unmatched := []addedModDir{}
+
+	for _, dir := range added {
+		modDir := filepath.Join(util.GetCharacterOrCategoryDir(dir.character), dir.filename)
+		dir.fingerprint, _ = modFingerprint(modDir)
+		sidecar, _ := dbh.ReadModSidecar(modDir)
+
+		i := slices.IndexFunc(missing, func(m types.Mod) bool {
+			if sidecar.Id != m.Id {
+				return false
+			}
+			stored := pass.fingerprints[m.Id]
+			// sidecars copied from another library can hold the id of an unrelated row
+			return stored == "" || stored == dir.fingerprint || (m.GbId != 0 && m.GbId == sidecar.GbId)
+		})
+
+		if i == -1 && dir.fingerprint != "" {
+			candidates := 0
+			for j, m := range missing {
+				if pass.fingerprints[m.Id] == dir.fingerprint {
+					candidates++
+					i = j
 				}
 			}
+			// identical copies can not be told apart
+			if candidates != 1 {
+				i = -1
+			}
 		}
 
-		log.LogPrint("Deleting mods not in: " + strings.Join(seenMods, "\n - "))
-		log.LogPrintf("Deleting textures not in: %v", modIdToSeenTextures)
+		if i == -1 {
+			unmatched = append(unmatched, dir)
+			continue
+		}
+
+		s.moveMod(pass, missing[i], dir)
+		missing = slices.Delete(missing, i, i+1)
+	}
+
+	return unmatched, missing
+}
+
+func (s *SyncHelper) moveMod(pass *syncPass, mod types.Mod, dir addedModDir) {
+	entry := SyncReportEntry{
+		ModId:        mod.Id,
+		Character:    dir.character.Name,
+		Filename:     dir.filename,
+		PrevFilename: mod.Filename,
+	}
+	if mod.Character != dir.character.Name {
+		entry.PrevCharacter = mod.Character
+	}
+	pass.report.Renamed = append(pass.report.Renamed, entry)
 
-		s.db.DeleteUnusedMods(seenMods, game)
-		s.db.DeleteUnusedTextureFromMap(modIdToSeenTextures)
+	if dir.fingerprint != "" && pass.fingerprints[mod.Id] != dir.fingerprint {
+		pass.batch.Fingerprints[mod.Id] = dir.fingerprint
+		pass.fingerprints[mod.Id] = dir.fingerprint
+	}
 
+	mod.Filename = dir.filename
+	mod.Character = dir.character.Name
+	mod.CharacterId = dir.character.Id
+	mod.Category = dir.character.IsCategory()
+	pass.batch.Moved = append(pass.batch.Moved, mod)
+	s.syncExistingMod(pass, mod, dir.textures)
+}
+
+func (s *SyncHelper) syncTextures(pass *syncPass, mod types.Mod, textureDirs []string) {
+	texturesDir := filepath.Join(util.GetModDir(mod), "textures")
+	matched, added, missing, renamed := diffDirs(
+		pass.textures[mod.Id],
+		textureDirs,
+		func(t types.Texture) string { return t.Filename },
+		// a renamed dir keeps the sidecar with the id of its row
+		func(t types.Texture, dir string) bool {
+			sidecar, err := dbh.ReadTextureSidecar(filepath.Join(texturesDir, dir))
+			return err == nil && sidecar.Id == t.Id
+		},
+	)
+
+	for _, rename := range renamed {
+		texture, fname := rename.Pair()
+		pass.report.Renamed = append(pass.report.Renamed, SyncReportEntry{
+			ModId:        mod.Id,
+			TextureId:    texture.Id,
+			Character:    mod.Character,
+			Filename:     fname,
+			PrevFilename: texture.Filename,
+		})
+		texture.Filename = fname
+		pass.batch.RenamedTextures = append(pass.batch.RenamedTextures, texture)
+		matched = append(matched, texture)
+	}
+
+	for _, texture := range matched {
+		s.markSeen(pass, dbh.PENDING_DELETE_TEXTURE, texture.Id)
+		s.syncTextureSidecar(pass, mod, texture)
+	}
+
+	for _, textureFilename := range added {
+		pass.batch.Textures = append(pass.batch.Textures, dbh.SyncTexture{
+			Texture: types.Texture{Filename: textureFilename, ModId: mod.Id},
+			Sidecar: readNewSidecar(filepath.Join(texturesDir, textureFilename), dbh.ReadTextureSidecar),
+		})
+		pass.modCharacters[mod.Id] = mod.Character
+	}
+
+	pass.missingTextures = append(pass.missingTextures, missing...)
+}
+
+// the sidecar of a dir without a row, nil when there is none or it is invalid
+func readNewSidecar[T any](dir string, read func(string) (T, error)) *T {
+	sidecar, err := read(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.LogErrorf("invalid sidecar for %s: %s", dir, err.Error())
+		}
 		return nil
+	}
+	return &sidecar
+}
+
+// rows without metadata are filled from the sidecar in the dir,
+// dirs without a sidecar get one written from the row
+func (s *SyncHelper) syncModSidecar(pass *syncPass, mod types.Mod) {
+	dir := util.GetModDir(mod)
+	hasMetadata := mod.GbId != 0 || mod.ModLink != "" || len(mod.PreviewImages) > 0
+
+	if hasMetadata {
+		if exists, _ := util.FileExists(filepath.Join(dir, util.SidecarFile)); !exists {
+			pass.modSidecars = append(pass.modSidecars, mod.Id)
+		}
+		return
+	}
+
+	sidecar, err := dbh.ReadModSidecar(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			pass.modSidecars = append(pass.modSidecars, mod.Id)
+		} else {
+			log.LogErrorf("invalid sidecar for %s: %s", dir, err.Error())
+		}
+		return
+	}
+
+	if !sidecar.HasMetadata() {
+		return
+	}
+
+	pass.batch.ModSidecars[mod.Id] = sidecar
+	pass.report.Repaired = append(pass.report.Repaired, SyncReportEntry{
+		ModId:     mod.Id,
+		Character: mod.Character,
+		Filename:  mod.Filename,
 	})
+}
 
-	return task.Wait()
+func (s *SyncHelper) syncTextureSidecar(pass *syncPass, mod types.Mod, texture types.Texture) {
+	dir := filepath.Join(util.GetModDir(mod), "textures", texture.Filename)
+	hasMetadata := texture.GbId != 0 || texture.ModLink != "" || len(texture.PreviewImages) > 0
+
+	sidecar, err := dbh.ReadTextureSidecar(dir)
+	if hasMetadata {
+		// sidecars written before they held the id are rewritten so renames can be followed
+		if err != nil || sidecar.Id != texture.Id {
+			pass.textureSidecars = append(pass.textureSidecars, texture.Id)
+		}
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			pass.textureSidecars = append(pass.textureSidecars, texture.Id)
+		} else {
+			log.LogErrorf("invalid sidecar for %s: %s", dir, err.Error())
+		}
+		return
+	}
+
+	if !sidecar.HasMetadata() {
+		if sidecar.Id != texture.Id {
+			pass.textureSidecars = append(pass.textureSidecars, texture.Id)
+		}
+		return
+	}
+
+	pass.batch.TextureSidecars[texture.Id] = sidecar
+	pass.report.Repaired = append(pass.report.Repaired, SyncReportEntry{
+		ModId:     mod.Id,
+		TextureId: texture.Id,
+		Character: mod.Character,
+		Filename:  texture.Filename,
+	})
+}
+
+// writes the batch of the pass and adds the new rows to the report
+func (s *SyncHelper) applyPass(pass *syncPass) error {
+	s.removeMissing(pass)
+
+	if err := s.db.ApplySyncBatch(pass.batch); err != nil {
+		return err
+	}
+
+	for _, m := range pass.batch.Mods {
+		pass.report.Added = append(pass.report.Added, SyncReportEntry{
+			ModId:     m.Mod.Id,
+			Character: m.Mod.Character,
+			Filename:  m.Mod.Filename,
+		})
+		for _, t := range m.Textures {
+			pass.report.Added = append(pass.report.Added, SyncReportEntry{
+				ModId:     m.Mod.Id,
+				TextureId: t.Texture.Id,
+				Character: m.Mod.Character,
+				Filename:  t.Texture.Filename,
+			})
+		}
+	}
+	for _, t := range pass.batch.Textures {
+		pass.report.Added = append(pass.report.Added, SyncReportEntry{
+			ModId:     t.Texture.ModId,
+			TextureId: t.Texture.Id,
+			Character: pass.modCharacters[t.Texture.ModId],
+			Filename:  t.Texture.Filename,
+		})
+	}
+
+	for _, id := range pass.modSidecars {
+		s.db.WriteModSidecar(id)
+	}
+	for _, id := range pass.textureSidecars {
+		s.db.WriteTextureSidecar(id)
+	}
+	return nil
+}
+
+// rows without user data are deleted right away, the others wait for
+// confirmation or until the grace period is over
+func (s *SyncHelper) removeMissing(pass *syncPass) {
+	if len(pass.missingMods) == 0 && len(pass.missingTextures) == 0 {
+		return
+	}
+
+	modsWithData, err := s.db.SelectModIdsWithUserData(pass.game)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+	texturesWithData, err := s.db.SelectTextureIdsWithUserData(pass.game)
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+
+	now := time.Now()
+	grace := time.Duration(s.graceDays.Get()) * time.Hour * 24
+
+	// returns true if the row should be deleted now
+	expired := func(kind dbh.PendingDeleteKind, id int, hasData bool) bool {
+		if !hasData {
+			return true
+		}
+		since, ok := pass.pending[pendingKey{kind, id}]
+		if !ok {
+			since = now
+			pass.batch.InsertPending = append(pass.batch.InsertPending, dbh.PendingDelete{Kind: kind, Id: id, Since: since})
+		}
+		if grace > 0 && now.Sub(since) >= grace {
+			pass.batch.DeletePending = append(pass.batch.DeletePending, dbh.PendingDelete{Kind: kind, Id: id})
+			return true
+		}
+		return false
+	}
+
+	modCharacters := map[int]string{}
+	for _, mod := range pass.missingMods {
+		modCharacters[mod.Id] = mod.Character
+		entry := SyncReportEntry{
+			ModId:     mod.Id,
+			Character: mod.Character,
+			Filename:  mod.Filename,
+		}
+		if expired(dbh.PENDING_DELETE_MOD, mod.Id, slices.Contains(modsWithData, mod.Id)) {
+			pass.batch.DeleteMods = append(pass.batch.DeleteMods, mod.Id)
+			pass.report.Removed = append(pass.report.Removed, entry)
+		} else {
+			pass.report.Pending = append(pass.report.Pending, entry)
+		}
+	}
+
+	for _, texture := range pass.missingTextures {
+		entry := SyncReportEntry{
+			ModId:     texture.ModId,
+			TextureId: texture.Id,
+			Character: modCharacters[texture.ModId],
+			Filename:  texture.Filename,
+		}
+		if expired(dbh.PENDING_DELETE_TEXTURE, texture.Id, slices.Contains(texturesWithData, texture.Id)) {
+			pass.batch.DeleteTextures = append(pass.batch.DeleteTextures, texture.Id)
+			pass.report.Removed = append(pass.report.Removed, entry)
+		} else {
+			pass.report.Pending = append(pass.report.Pending, entry)
+		}
+	}
+
+	log.LogPrintf("Deleting mods: %v textures: %v", pass.batch.DeleteMods, pass.batch.DeleteTextures)
 }
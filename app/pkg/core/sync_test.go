@@ -0,0 +1,460 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"hmm/pkg/core/dbh"
+	"hmm/pkg/pref"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiffDirs(t *testing.T) {
+	filename := func(m types.Mod) string { return m.Filename }
+	// stands in for the id in the sidecar of the dir
+	sidecarIds := map[string]int{"c": 2}
+	sameRow := func(m types.Mod, dir string) bool { return sidecarIds[dir] == m.Id }
+
+	rows := []types.Mod{
+		{Id: 1, Filename: "a"},
+		{Id: 2, Filename: "b"},
+	}
+
+	matched, added, missing, renamed := diffDirs(rows, []string{"a", "c"}, filename, sameRow)
+	if len(matched) != 1 || matched[0].Id != 1 {
+		t.Errorf("expected a to match got %v", matched)
+	}
+	if len(renamed) != 1 || renamed[0].X.Id != 2 || renamed[0].Y != "c" {
+		t.Fatalf("expected b to be renamed to c got %v", renamed)
+	}
+	if len(added) != 0 || len(missing) != 0 {
+		t.Errorf("rename should not be reported as added or missing %v %v", added, missing)
+	}
+
+	// a single new dir without the id of the missing row is not a rename
+	_, added, missing, renamed = diffDirs(rows, []string{"a", "d"}, filename, sameRow)
+	if len(renamed) != 0 {
+		t.Errorf("expected no rename for an unrelated dir got %v", renamed)
+	}
+	if !slices.Equal(added, []string{"d"}) || len(missing) != 1 {
+		t.Errorf("unexpected diff added: %v missing: %v", added, missing)
+	}
+
+	_, added, missing, renamed = diffDirs(rows, []string{"c", "d"}, filename, sameRow)
+	if len(renamed) != 1 || !slices.Equal(added, []string{"d"}) || len(missing) != 1 || missing[0].Id != 1 {
+		t.Errorf("unexpected diff renamed: %v added: %v missing: %v", renamed, added, missing)
+	}
+}
+
+func newTestSyncHelper(t testing.TB) *SyncHelper {
+	ddl, err := os.ReadFile("../../db/sql/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, dbSql, err := dbh.OpenDb(context.Background(), filepath.Join(t.TempDir(), "hmm.db"), os.DirFS("../../"), string(ddl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbSql.Close() })
+
+	prefs := pref.NewPrefs(pref.NewInMemoryStore(context.Background()))
+	return &SyncHelper{
+		db:           dbh.NewDbHelper(dbSql),
+		graceDays:    prefs.GetInt("sync_delete_grace_days", 7),
+		dataVersions: prefs.GetStringSlice("character_data_versions", []string{}),
+	}
+}
+
+func TestSyncKeepsModIdOnMove(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	characters := []types.Character{
+		{Id: 1, Game: types.Genshin, Name: "Nahida"},
+		{Id: 2, Game: types.Genshin, Name: "Furina"},
+	}
+
+	modDir := filepath.Join(util.GetCharacterDir("Nahida", types.Genshin), "mod")
+	os.MkdirAll(filepath.Join(modDir, "root"), os.ModePerm)
+	os.WriteFile(filepath.Join(modDir, "root", "mod.ini"), []byte("[Constants]"), 0666)
+
+	sync := func() SyncReport {
+		mods, _ := s.db.SelectModsByGame(types.Genshin)
+		pass, err := s.newSyncPass(types.Genshin, SyncRequestLocal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.syncLibrary(pass, characters, mods); err != nil {
+			t.Fatal(err)
+		}
+		return pass.report
+	}
+
+	sync()
+	mod, err := s.db.SelectModByFileCharacterGame("mod", "Nahida", types.Genshin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// moved to another character and an unrelated mod added next to it, matched by the sidecar id
+	movedDir := filepath.Join(util.GetCharacterDir("Furina", types.Genshin), "moved")
+	os.MkdirAll(filepath.Dir(movedDir), os.ModePerm)
+	if err = os.Rename(modDir, movedDir); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(util.GetCharacterDir("Furina", types.Genshin), "other", "root"), os.ModePerm)
+
+	report := sync()
+	if len(report.Renamed) != 1 || report.Renamed[0].PrevCharacter != "Nahida" {
+		t.Fatalf("expected the move to be reported got %+v", report)
+	}
+	moved, err := s.db.SelectModByFileCharacterGame("moved", "Furina", types.Genshin)
+	if err != nil || moved.Id != mod.Id {
+		t.Fatalf("expected mod %d to keep its id got %d %v", mod.Id, moved.Id, err)
+	}
+
+	// without a sidecar the fingerprint is used
+	os.Remove(filepath.Join(movedDir, util.SidecarFile))
+	os.MkdirAll(filepath.Join(util.GetCharacterDir("Furina", types.Genshin), "another", "root"), os.ModePerm)
+	if err = os.Rename(movedDir, filepath.Join(filepath.Dir(movedDir), "renamed")); err != nil {
+		t.Fatal(err)
+	}
+
+	sync()
+	renamed, err := s.db.SelectModByFileCharacterGame("renamed", "Furina", types.Genshin)
+	if err != nil || renamed.Id != mod.Id {
+		t.Errorf("expected mod %d to keep its id got %d %v", mod.Id, renamed.Id, err)
+	}
+
+	// a deleted mod and an unrelated one added to the same character are not a rename
+	os.RemoveAll(filepath.Join(filepath.Dir(movedDir), "renamed"))
+	freshDir := filepath.Join(util.GetCharacterDir("Furina", types.Genshin), "fresh", "root")
+	os.MkdirAll(freshDir, os.ModePerm)
+	os.WriteFile(filepath.Join(freshDir, "fresh.ini"), []byte("[TextureOverride]"), 0666)
+
+	sync()
+	fresh, err := s.db.SelectModByFileCharacterGame("fresh", "Furina", types.Genshin)
+	if err != nil || fresh.Id == mod.Id {
+		t.Errorf("expected the unrelated mod to get a new id got %d %v", fresh.Id, err)
+	}
+}
+
+func TestSyncInvalidatesIniCacheOnEdit(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	characters := []types.Character{{Id: 1, Game: types.Genshin, Name: "Nahida"}}
+
+	modDir := filepath.Join(util.GetCharacterDir("Nahida", types.Genshin), "mod")
+	os.MkdirAll(filepath.Join(modDir, "root"), os.ModePerm)
+	os.WriteFile(filepath.Join(modDir, "root", "mod.ini"), []byte("[Constants]"), 0666)
+
+	sync := func() {
+		mods, _ := s.db.SelectModsByGame(types.Genshin)
+		pass, err := s.newSyncPass(types.Genshin, SyncRequestLocal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.syncLibrary(pass, characters, mods); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cachedFingerprint := func(mod types.Mod) string {
+		if _, err := GetRelativeIniPaths(mod, modDir, nil, s.db); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := s.db.SelectIniEntriesByModId(mod.Id)
+		if err != nil || len(entries) == 0 {
+			t.Fatalf("expected cached ini entries got %v %v", entries, err)
+		}
+		return entries[0].Fingerprint
+	}
+
+	sync()
+	mod, err := s.db.SelectModByFileCharacterGame("mod", "Nahida", types.Genshin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := cachedFingerprint(mod)
+
+	// edited in place, the dir keeps its name so only the fingerprint can tell
+	os.WriteFile(filepath.Join(modDir, "root", "mod.ini"), []byte("[Constants]\nglobal $swapvar = 0\n"), 0666)
+	os.WriteFile(filepath.Join(modDir, "root", "face.ini"), []byte("[Constants]"), 0666)
+	sync()
+
+	if after := cachedFingerprint(mod); after == before {
+		t.Error("expected the ini cache to be rebuilt after the mod was edited")
+	}
+	if paths, _ := GetRelativeIniPaths(mod, modDir, nil, s.db); len(paths) != 2 {
+		t.Errorf("expected the added ini to be found got %v", paths)
+	}
+}
+
+func TestSyncCategories(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	weapons := types.Character{Id: util.CategoryId("Weapons"), Game: types.Genshin, Name: "Weapons", Category: types.CATEGORY_WEAPON}
+	// a character with the same name as the category keeps its own mods
+	character := types.Character{Id: util.HashForName("Weapons"), Game: types.Genshin, Name: "Weapons"}
+	for _, c := range []types.Character{weapons, character} {
+		if err := s.db.UpsertCharacter(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.MkdirAll(filepath.Join(util.GetCategoryDir("Weapons", types.Genshin), "sword", "root"), os.ModePerm)
+	os.MkdirAll(filepath.Join(util.GetCharacterDir("Weapons", types.Genshin), "skin", "root"), os.ModePerm)
+	os.MkdirAll(filepath.Join(util.GetCategoryDir("Gadgets", types.Genshin), "gadget", "root"), os.ModePerm)
+
+	characters, _ := s.db.SelectCharactersByGame(types.Genshin)
+	characters = append(characters, s.syncCategoryDirs(types.Genshin, characters)...)
+
+	pass, err := s.newSyncPass(types.Genshin, SyncRequestLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.syncLibrary(pass, characters, []types.Mod{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mods, _ := s.db.SelectModsByGame(types.Genshin)
+	if len(mods) != 3 {
+		t.Fatalf("expected 3 mods got %+v", mods)
+	}
+	for _, mod := range mods {
+		if exists, _ := util.FileExists(filepath.Join(util.GetModDir(mod), "root")); !exists {
+			t.Errorf("mod dir of %+v does not exist", mod)
+		}
+		if mod.Category != (mod.Filename != "skin") {
+			t.Errorf("unexpected category flag %+v", mod)
+		}
+	}
+
+	categories, _ := s.db.SelectCategoriesByGame(types.Genshin)
+	if len(categories) != 2 || !slices.ContainsFunc(categories, func(c types.Character) bool { return c.Name == "Gadgets" && c.Custom }) {
+		t.Errorf("expected the gadgets dir to be added as a custom category got %+v", categories)
+	}
+}
+
+func TestAdoptCharacterDirs(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	raiden := types.Character{Id: 1, Game: types.Genshin, Name: "Raiden Shogun"}
+	s.db.UpsertCharacter(raiden)
+	s.db.SeedCharacterAliases(types.Genshin, map[string][]string{"Raiden Shogun": {"Ei"}})
+
+	os.MkdirAll(filepath.Join(util.GetCharacterDir("Raiden Shogun", types.Genshin), "outfit"), os.ModePerm)
+	for _, dir := range []string{"Ei/outfit", "Ei/swimsuit", "Unknown/mod"} {
+		os.MkdirAll(filepath.Join(util.GetGameDir(types.Genshin), dir), os.ModePerm)
+	}
+
+	suggestions := suggestCharacterDirs(s.db, types.Genshin)
+	want := []CharacterDirSuggestion{{Dir: "Ei", CharacterId: raiden.Id, Character: raiden.Name}}
+	if !slices.Equal(suggestions, want) {
+		t.Fatalf("expected only Ei to be suggested got %v", suggestions)
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetGameDir(types.Genshin), "Ei", "outfit")); !exists {
+		t.Fatal("a suggested dir was moved without confirmation")
+	}
+
+	raiden, _ = s.db.SelectCharacterById(raiden.Id, types.Genshin)
+	if err := adoptCharacterDir("Ei", raiden); err != nil {
+		t.Fatal(err)
+	}
+	for _, mod := range []string{"outfit", "outfit (1)", "swimsuit"} {
+		if exists, _ := util.FileExists(filepath.Join(util.GetCharacterDir("Raiden Shogun", types.Genshin), mod)); !exists {
+			t.Errorf("%s was not moved into the character dir", mod)
+		}
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetGameDir(types.Genshin), "Ei")); exists {
+		t.Error("the adopted dir was not removed")
+	}
+	if exists, _ := util.FileExists(filepath.Join(util.GetGameDir(types.Genshin), "Unknown", "mod")); !exists {
+		t.Error("a dir without a match was moved")
+	}
+}
+
+func TestUpsertCharactersKeepsSavedNames(t *testing.T) {
+	s := newTestSyncHelper(t)
+	anby := types.Character{Id: util.HashForName("Anby Demara"), Game: types.ZZZ, Name: "Anby Demara"}
+	s.db.UpsertCharacter(anby)
+
+	existing, _ := s.db.SelectCharactersByGame(types.ZZZ)
+	fetched := []types.Character{
+		{Id: util.HashForName("Anby"), Game: types.ZZZ, Name: "Anby", Element: "Electric"},
+		{Id: util.HashForName("Nicole Demara"), Game: types.ZZZ, Name: "Nicole Demara"},
+	}
+	upsertCharacters(s.db, existing, fetched, false)
+
+	characters, _ := s.db.SelectCharactersByGame(types.ZZZ)
+	if len(characters) != 2 {
+		t.Fatalf("expected Anby to be updated and Nicole added got %+v", characters)
+	}
+	saved, err := s.db.SelectCharacterById(anby.Id, types.ZZZ)
+	if err != nil || saved.Name != anby.Name || saved.Element != "Electric" {
+		t.Errorf("expected Anby to keep the saved id and name got %+v %v", saved, err)
+	}
+
+	s.db.UpsertCharacter(types.Character{Id: anby.Id, Game: types.ZZZ, Name: anby.Name, Element: "Ice"})
+	upsertCharacters(s.db, characters, fetched, true)
+	if saved, _ := s.db.SelectCharacterById(anby.Id, types.ZZZ); saved.Element != "Ice" {
+		t.Errorf("a saved character was updated when only missing ones should be added %+v", saved)
+	}
+}
+
+func TestSyncTexturesAndRemovals(t *testing.T) {
+	root := t.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	s := newTestSyncHelper(t)
+	characters := []types.Character{{Id: 1, Game: types.Genshin, Name: "Nahida"}}
+
+	dir := util.GetCharacterDir("Nahida", types.Genshin)
+	os.MkdirAll(filepath.Join(dir, "mod", "textures", "texture"), os.ModePerm)
+	os.MkdirAll(filepath.Join(dir, "removed"), os.ModePerm)
+
+	sync := func() SyncReport {
+		mods, _ := s.db.SelectModsByGame(types.Genshin)
+		pass, err := s.newSyncPass(types.Genshin, SyncRequestLocal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.syncLibrary(pass, characters, mods); err != nil {
+			t.Fatal(err)
+		}
+		return pass.report
+	}
+
+	if report := sync(); len(report.Added) != 3 {
+		t.Fatalf("expected 2 mods and a texture to be added got %+v", report.Added)
+	}
+	mod, err := s.db.SelectModByFileCharacterGame("mod", "Nahida", types.Genshin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	textures, _ := s.db.SelectTexturesByModId(mod.Id)
+	if len(textures) != 1 {
+		t.Fatalf("expected the texture to have a row got %+v", textures)
+	}
+	if sidecar, err := dbh.ReadModSidecar(util.GetModDir(mod)); err != nil || sidecar.Id != mod.Id {
+		t.Errorf("expected a sidecar with the id of the row got %+v %v", sidecar, err)
+	}
+
+	os.Rename(filepath.Join(dir, "mod", "textures", "texture"), filepath.Join(dir, "mod", "textures", "renamed"))
+	os.RemoveAll(filepath.Join(dir, "removed"))
+
+	report := sync()
+	if len(report.Renamed) != 1 || report.Renamed[0].TextureId != textures[0].Id || len(report.Removed) != 1 {
+		t.Fatalf("expected the texture rename and the removed mod got %+v", report)
+	}
+	if renamed, _ := s.db.SelectTexturesByModId(mod.Id); len(renamed) != 1 || renamed[0].Filename != "renamed" {
+		t.Errorf("expected the texture row to be renamed got %+v", renamed)
+	}
+	if mods, _ := s.db.SelectModsByGame(types.Genshin); len(mods) != 1 {
+		t.Errorf("expected the removed mod to be deleted got %+v", mods)
+	}
+
+	// a deleted texture and a different one added are not a rename
+	os.RemoveAll(filepath.Join(dir, "mod", "textures", "renamed"))
+	os.MkdirAll(filepath.Join(dir, "mod", "textures", "recolor"), os.ModePerm)
+
+	if report := sync(); len(report.Renamed) != 0 || len(report.Added) != 1 {
+		t.Fatalf("expected the new texture to be added got %+v", report)
+	}
+	if recolor, _ := s.db.SelectTexturesByModId(mod.Id); len(recolor) != 1 || recolor[0].Id == textures[0].Id {
+		t.Errorf("expected the new texture to get a new row got %+v", recolor)
+	}
+}
+
+// writes a library of 5000 mod dirs, every tenth mod has a texture
+func writeBenchmarkLibrary(b *testing.B) []types.Character {
+	characters := []types.Character{}
+	for c := range 50 {
+		character := types.Character{Id: c + 1, Game: types.Genshin, Name: fmt.Sprintf("character%d", c)}
+		characters = append(characters, character)
+
+		for m := range 100 {
+			modDir := filepath.Join(util.GetCharacterDir(character.Name, types.Genshin), fmt.Sprintf("mod%d", m))
+			if err := os.MkdirAll(modDir, os.ModePerm); err != nil {
+				b.Fatal(err)
+			}
+			os.WriteFile(filepath.Join(modDir, "mod.ini"), []byte(fmt.Sprintf("[Constants]\nglobal $v%d_%d = 1", c, m)), 0666)
+			if m%10 == 0 {
+				os.MkdirAll(filepath.Join(modDir, "textures", "texture"), os.ModePerm)
+			}
+		}
+	}
+	return characters
+}
+
+// sidecars written by a previous run would be applied to the new rows
+func removeSidecars(b *testing.B, root string) {
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.Name() == util.SidecarFile {
+			return os.Remove(path)
+		}
+		return err
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkSync(b *testing.B) {
+	root := b.TempDir()
+	util.SetRootModDirFn(func() string { return root })
+	defer util.SetRootModDirFn(nil)
+
+	characters := writeBenchmarkLibrary(b)
+
+	sync := func(s *SyncHelper) {
+		mods, err := s.db.SelectModsByGame(types.Genshin)
+		if err != nil {
+			b.Fatal(err)
+		}
+		pass, err := s.newSyncPass(types.Genshin, SyncRequestLocal)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := s.syncLibrary(pass, characters, mods); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("initial", func(b *testing.B) {
+		for range b.N {
+			b.StopTimer()
+			removeSidecars(b, root)
+			s := newTestSyncHelper(b)
+			b.StartTimer()
+
+			sync(s)
+		}
+	})
+
+	b.Run("unchanged", func(b *testing.B) {
+		removeSidecars(b, root)
+		s := newTestSyncHelper(b)
+		sync(s)
+		b.ResetTimer()
+
+		for range b.N {
+			sync(s)
+		}
+	})
+}
@@ -44,7 +44,7 @@ func (t *ToastEmitter) Error(err error) {
 	})
 }
 
-func (t *ToastEmitter) Warning(err error) {
+func (t *ToastEmitter) Warn(err error) {
 	t.emitter.Emit(TOAST_EVENT, ToastEvent{
 		Level: TOAST_WARN,
 		Msg:   err.Error(),
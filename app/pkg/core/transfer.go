@@ -19,6 +19,9 @@ const (
 	Finished         ChangeDirEvent = "finished"
 )
 
+// emitted with the issues found by DbHelper.Doctor after the root dir changed
+const DOCTOR_EVENT = "doctor"
+
 type Transfer struct {
 	sync      *SyncHelper
 	cancel    chan struct{}
@@ -53,6 +56,7 @@ func (t *Transfer) ChangeRootModDir(dest string, copyOver bool) (state ChangeDir
 				log.LogError(err.Error())
 			}
 			t.sync.RunAll(SyncRequestLocal)
+			t.runDoctor()
 		}
 	}()
 
@@ -88,6 +92,16 @@ func (t *Transfer) ChangeRootModDir(dest string, copyOver bool) (state ChangeDir
 	return Finished, nil
 }
 
+// rows that still point at the previous dir are reported after the sync
+func (t *Transfer) runDoctor() {
+	issues, err := t.sync.db.Doctor()
+	if err != nil {
+		log.LogError(err.Error())
+		return
+	}
+	t.emitter.Emit(DOCTOR_EVENT, issues)
+}
+
 func (t *Transfer) RemoveAll(path string) error {
 
 	if _, ok := t.canRemove[path]; !ok {
@@ -0,0 +1,310 @@
+package core
+
+import (
+	"context"
+	"hmm/pkg/log"
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	LIBRARY_CHANGE_CREATE = "create"
+	LIBRARY_CHANGE_REMOVE = "remove"
+
+	// root/game/character/mod/textures/texture, categories are
+	// one level deeper in util.CategoriesDir and use the same depths
+	watchDepthGame      = 1
+	watchDepthCharacter = 2
+	watchDepthMod       = 3
+	watchDepthTextures  = 4
+	watchDepthTexture   = 5
+)
+
+// a dir in the mod library that was created or removed, Mod and Texture are
+// empty when the change was to a parent dir. Character is the category name
+// when Category is set
+type LibraryChange struct {
+	Game      types.Game `json:"game"`
+	Character string     `json:"character"`
+	Category  bool       `json:"category"`
+	Mod       string     `json:"mod"`
+	Texture   string     `json:"texture"`
+	Path      string     `json:"path"`
+	Op        string     `json:"op"`
+}
+
+func (c LibraryChange) characterDir() string {
+	if c.Category {
+		return util.GetCategoryDir(c.Character, c.Game)
+	}
+	return util.GetCharacterDir(c.Character, c.Game)
+}
+
+// LibraryWatcher watches util.GetRootModDir() and runs a targeted sync for the
+// character and mod dirs that changed. falls back to polling if fsnotify fails
+type LibraryWatcher struct {
+	sync         *SyncHelper
+	debounce     time.Duration
+	pollInterval time.Duration
+	mutex        sync.Mutex
+	pending      map[string]LibraryChange
+	timer        *time.Timer
+}
+
+func NewLibraryWatcher(sync *SyncHelper) *LibraryWatcher {
+	return &LibraryWatcher{
+		sync:         sync,
+		debounce:     time.Second,
+		pollInterval: time.Second * 5,
+		pending:      map[string]LibraryChange{},
+	}
+}
+
+// Watch blocks until ctx is cancelled, the root dir is re-checked so the
+// watcher follows the library when it is moved
+func (w *LibraryWatcher) Watch(ctx context.Context) {
+	for ctx.Err() == nil {
+		root := util.GetRootModDir()
+		rootCtx, cancel := context.WithCancel(ctx)
+
+		go func() {
+			ticker := time.NewTicker(w.pollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-rootCtx.Done():
+					return
+				case <-ticker.C:
+					if util.GetRootModDir() != root {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		if err := w.watchFs(rootCtx, root); err != nil {
+			log.LogErrorf("fsnotify unavailable falling back to polling: %s", err.Error())
+			w.poll(rootCtx, root)
+		}
+		cancel()
+	}
+}
+
+func (w *LibraryWatcher) watchFs(ctx context.Context, root string) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	os.MkdirAll(root, os.ModePerm)
+	if err := w.addWatches(fw, root, root); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+
+			switch {
+			case event.Has(fsnotify.Create):
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.addWatches(fw, root, event.Name); err != nil {
+						log.LogError(err.Error())
+					}
+				}
+				w.onChange(root, event.Name, LIBRARY_CHANGE_CREATE)
+			case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+				// renames are sent as a remove of the old name and a create of the new one
+				w.onChange(root, event.Name, LIBRARY_CHANGE_REMOVE)
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			log.LogError(err.Error())
+		}
+	}
+}
+
+// only the dirs that sync reads are watched, mod contents are skipped
+func (w *LibraryWatcher) addWatches(fw *fsnotify.Watcher, root, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		parts, _ := libraryPathParts(root, path)
+		depth := len(parts)
+		if depth > watchDepthTextures || (depth == watchDepthTextures && parts[3] != "textures") {
+			return filepath.SkipDir
+		}
+
+		return fw.Add(path)
+	})
+}
+
+// polling snapshots the dir names that sync reads and diffs them
+func (w *LibraryWatcher) poll(ctx context.Context, root string) {
+	prev := librarySnapshot(root)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			next := librarySnapshot(root)
+			for path := range next {
+				if _, ok := prev[path]; !ok {
+					w.onChange(root, path, LIBRARY_CHANGE_CREATE)
+				}
+			}
+			for path := range prev {
+				if _, ok := next[path]; !ok {
+					w.onChange(root, path, LIBRARY_CHANGE_REMOVE)
+				}
+			}
+			prev = next
+		}
+	}
+}
+
+func librarySnapshot(root string) map[string]struct{} {
+	snapshot := map[string]struct{}{}
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		parts, _ := libraryPathParts(root, path)
+		depth := len(parts)
+		if depth > watchDepthTexture || (depth >= watchDepthTextures && parts[3] != "textures") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		snapshot[path] = struct{}{}
+		return nil
+	})
+	return snapshot
+}
+
+// the categories dir is removed from the parts so category paths
+// have the same depths as character paths
+func libraryPathParts(root, path string) (parts []string, category bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return []string{}, false
+	}
+	parts = strings.Split(rel, string(filepath.Separator))
+	if len(parts) >= 2 && parts[1] == util.CategoriesDir {
+		return slices.Delete(parts, 1, 2), true
+	}
+	return parts, false
+}
+
+// maps a changed path to the character, mod or texture it belongs to
+func libraryChangeFromPath(root, path, op string) (LibraryChange, bool) {
+	parts, category := libraryPathParts(root, path)
+	depth := len(parts)
+
+	if depth < watchDepthCharacter || depth > watchDepthTexture {
+		return LibraryChange{}, false
+	}
+	if depth >= watchDepthTextures && parts[3] != "textures" {
+		return LibraryChange{}, false
+	}
+
+	change := LibraryChange{
+		Character: parts[1],
+		Category:  category,
+		Path:      path,
+		Op:        op,
+	}
+
+	found := false
+	for _, game := range types.Games {
+		if game.Name() == parts[0] {
+			change.Game = game
+			found = true
+		}
+	}
+	if !found {
+		return LibraryChange{}, false
+	}
+
+	if depth >= watchDepthMod {
+		change.Mod = parts[2]
+	}
+	if depth == watchDepthTexture {
+		change.Texture = parts[4]
+	}
+
+	return change, true
+}
+
+func (w *LibraryWatcher) onChange(root, path, op string) {
+	change, ok := libraryChangeFromPath(root, path, op)
+	if !ok {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.pending[path] = change
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, w.flush)
+}
+
+// changes to dirs the app is still writing, like a download that is extracting,
+// are kept until the write is done so the app saves the row first
+func (w *LibraryWatcher) flush() {
+	w.mutex.Lock()
+	pending := w.pending
+	w.pending = map[string]LibraryChange{}
+	for path, change := range pending {
+		if w.sync.db.IsWriting(path) {
+			w.pending[path] = change
+			delete(pending, path)
+		}
+	}
+	if len(w.pending) > 0 {
+		w.timer = time.AfterFunc(w.debounce, w.flush)
+	}
+	w.mutex.Unlock()
+
+	byGame := map[types.Game][]LibraryChange{}
+	for _, change := range pending {
+		byGame[change.Game] = append(byGame[change.Game], change)
+	}
+
+	for game, changes := range byGame {
+		log.LogDebugf("library changed for %s: %v", game.Name(), changes)
+		if err := w.sync.SyncChanges(game, changes); err != nil {
+			log.LogError(err.Error())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"hmm/pkg/types"
+	"hmm/pkg/util"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLibraryChangeFromPath(t *testing.T) {
+	root := filepath.Join("mods")
+	join := func(parts ...string) string { return filepath.Join(append([]string{root}, parts...)...) }
+
+	cases := []struct {
+		path string
+		ok   bool
+		want LibraryChange
+	}{
+		{join("Genshin"), false, LibraryChange{}},
+		{join("Genshin", "Furina"), true, LibraryChange{Game: types.Genshin, Character: "Furina"}},
+		{join("ZZZ", "Ellen", "mod"), true, LibraryChange{Game: types.ZZZ, Character: "Ellen", Mod: "mod"}},
+		{join("ZZZ", "Ellen", "mod", "textures", "skin"), true, LibraryChange{Game: types.ZZZ, Character: "Ellen", Mod: "mod", Texture: "skin"}},
+		{join("ZZZ", "Ellen", "mod", "inner", "file.ini"), false, LibraryChange{}},
+		{join("Genshin", util.CategoriesDir), false, LibraryChange{}},
+		{join("Genshin", util.CategoriesDir, "Weapons", "mod", "textures", "skin"), true, LibraryChange{Game: types.Genshin, Character: "Weapons", Category: true, Mod: "mod", Texture: "skin"}},
+		{join("Unknown", "Ellen"), false, LibraryChange{}},
+	}
+
+	for _, c := range cases {
+		got, ok := libraryChangeFromPath(root, c.path, LIBRARY_CHANGE_CREATE)
+		if ok != c.ok {
+			t.Errorf("%s: expected ok %v", c.path, c.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		c.want.Path = c.path
+		c.want.Op = LIBRARY_CHANGE_CREATE
+		if got != c.want {
+			t.Errorf("%s: got %+v want %+v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestLibrarySnapshotSkipsModContent(t *testing.T) {
+	root := t.TempDir()
+	modDir := filepath.Join(root, "Genshin", "Furina", "mod")
+	os.MkdirAll(filepath.Join(modDir, "inner"), os.ModePerm)
+	os.MkdirAll(filepath.Join(modDir, "textures", "skin", "root"), os.ModePerm)
+
+	snapshot := librarySnapshot(root)
+
+	for _, path := range []string{modDir, filepath.Join(modDir, "textures", "skin")} {
+		if _, ok := snapshot[path]; !ok {
+			t.Errorf("expected %s in snapshot", path)
+		}
+	}
+	for _, path := range []string{filepath.Join(modDir, "inner"), filepath.Join(modDir, "textures", "skin", "root")} {
+		if _, ok := snapshot[path]; ok {
+			t.Errorf("did not expect %s in snapshot", path)
+		}
+	}
+}
+
+func TestFlushWaitsForAppWrites(t *testing.T) {
+	root := t.TempDir()
+	s := newTestSyncHelper(t)
+	w := NewLibraryWatcher(s)
+	w.debounce = time.Hour
+
+	modDir := filepath.Join(root, "Genshin", "Furina", "mod")
+	done := s.db.BeginWrite(modDir)
+
+	for _, path := range []string{modDir, filepath.Join(modDir, "textures", "skin"), filepath.Dir(modDir)} {
+		change, _ := libraryChangeFromPath(root, path, LIBRARY_CHANGE_CREATE)
+		w.pending[path] = change
+	}
+	w.flush()
+	if len(w.pending) != 3 {
+		t.Fatalf("expected the changes of the written dir to wait got %v", w.pending)
+	}
+	w.timer.Stop()
+
+	done()
+	if s.db.IsWriting(modDir) {
+		t.Error("the dir is still marked as written after it was done")
+	}
+}
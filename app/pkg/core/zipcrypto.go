@@ -0,0 +1,200 @@
+package core
+
+import (
+	"fmt"
+	"hash/crc32"
+	"hmm/pkg/util"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/zip"
+)
+
+const (
+	zipFlagEncrypted = 0x1
+	zipFlagDataDesc  = 0x8
+	zipMethodAES     = 99
+	zipCryptoHeader  = 12
+)
+
+// traditional PKWARE encryption, AES encrypted zips are not supported
+type zipCryptoKeys [3]uint32
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[(crc^uint32(b))&0xff] ^ (crc >> 8)
+}
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	k := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for i := range len(password) {
+		k.update(password[i])
+	}
+	return k
+}
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32Update(k[0], b)
+	k[1] = (k[1]+(k[0]&0xff))*134775813 + 1
+	k[2] = crc32Update(k[2], byte(k[1]>>24))
+}
+
+func (k *zipCryptoKeys) decryptByte() byte {
+	temp := uint16(k[2] | 2)
+	return byte((uint32(temp) * uint32(temp^1)) >> 8)
+}
+
+func (k *zipCryptoKeys) decrypt(b []byte) {
+	for i := range b {
+		b[i] ^= k.decryptByte()
+		k.update(b[i])
+	}
+}
+
+type zipCryptoReader struct {
+	r    io.Reader
+	keys *zipCryptoKeys
+}
+
+func (z *zipCryptoReader) Read(p []byte) (int, error) {
+	n, err := z.r.Read(p)
+	z.keys.decrypt(p[:n])
+	return n, err
+}
+
+// hasAesZipEntries reports if the zip has winzip aes entries, those can not be decrypted
+func hasAesZipEntries(r *zip.ReadCloser) bool {
+	return slices.ContainsFunc(r.File, func(f *zip.File) bool {
+		return f.Flags&zipFlagEncrypted != 0 && f.Method == zipMethodAES
+	})
+}
+
+// isEncryptedZip reports if any file in the zip at path needs a password
+func isEncryptedZip(path string) bool {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Flags&zipFlagEncrypted != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func openEncryptedZipFile(f *zip.File, password string) (io.ReadCloser, error) {
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := newZipCryptoKeys(password)
+	header := make([]byte, zipCryptoHeader)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	keys.decrypt(header)
+
+	// the last byte of the header is used to check the password
+	check := byte(f.CRC32 >> 24)
+	if f.Flags&zipFlagDataDesc != 0 {
+		check = byte(f.ModifiedTime >> 8)
+	}
+	if header[zipCryptoHeader-1] != check {
+		return nil, ErrIncorrectPassword
+	}
+
+	decrypted := &zipCryptoReader{r: raw, keys: keys}
+
+	switch f.Method {
+	case zip.Store:
+		return io.NopCloser(decrypted), nil
+	case zip.Deflate:
+		return flate.NewReader(decrypted), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression method %d", f.Method)
+	}
+}
+
+// extracts a zip with ZipCrypto encrypted entries verifying each file crc
+func extractEncryptedZip(
+	archivePath, basePath, password string,
+	overwrite bool,
+	onProgress func(progress int64, total int64),
+) error {
+
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if hasAesZipEntries(r) {
+		return ErrUnsupportedEncryption
+	}
+	if password == "" {
+		return ErrPasswordRequired
+	}
+
+	total := int64(0)
+	for _, f := range r.File {
+		total += int64(f.UncompressedSize64)
+	}
+	progress := int64(0)
+
+	if onProgress != nil {
+		onProgress(progress, total)
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := filepath.Clean(filepath.FromSlash(f.Name))
+		if !filepath.IsLocal(name) {
+			return ErrInvalidPath
+		}
+		out := filepath.Join(basePath, name)
+		if exists, _ := util.FileExists(out); exists && !overwrite {
+			continue
+		}
+
+		var rc io.ReadCloser
+		if f.Flags&zipFlagEncrypted != 0 {
+			rc, err = openEncryptedZipFile(f, password)
+		} else {
+			rc, err = f.Open()
+		}
+		if err != nil {
+			return err
+		}
+
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+
+		if crc32.ChecksumIEEE(b) != f.CRC32 {
+			return ErrIncorrectPassword
+		}
+
+		os.MkdirAll(filepath.Dir(out), 0755)
+		if err = os.WriteFile(out, b, 0644); err != nil {
+			return err
+		}
+
+		progress += int64(len(b))
+		if onProgress != nil {
+			onProgress(progress, total)
+		}
+	}
+
+	return nil
+}
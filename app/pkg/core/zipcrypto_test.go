@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zip"
+)
+
+// writes a stored ZipCrypto encrypted entry so extraction can be tested without fixtures
+func writeEncryptedZip(t *testing.T, path, name, password string, content []byte) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	crc := crc32.ChecksumIEEE(content)
+
+	keys := newZipCryptoKeys(password)
+	plain := make([]byte, zipCryptoHeader, zipCryptoHeader+len(content))
+	plain[zipCryptoHeader-1] = byte(crc >> 24)
+	plain = append(plain, content...)
+
+	encrypted := make([]byte, len(plain))
+	for i, b := range plain {
+		encrypted[i] = b ^ keys.decryptByte()
+		keys.update(b)
+	}
+
+	w := zip.NewWriter(f)
+	header := &zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		Flags:              zipFlagEncrypted,
+		CRC32:              crc,
+		CompressedSize64:   uint64(len(encrypted)),
+		UncompressedSize64: uint64(len(content)),
+	}
+	rw, err := w.CreateRaw(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rw.Write(encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractEncryptedZip(t *testing.T) {
+
+	content := []byte("[TextureOverrideBody]\nhash = 1234\n")
+	archive := filepath.Join(t.TempDir(), "mod.zip")
+	writeEncryptedZip(t, archive, "mod/mod.ini", "hunter2", content)
+
+	if !isEncryptedZip(archive) {
+		t.Fatal("expected archive to be encrypted")
+	}
+
+	_, err := ArchiveExtract(archive, t.TempDir(), false, true, nil)
+	if !errors.Is(err, ErrPasswordRequired) {
+		t.Errorf("expected ErrPasswordRequired got %v", err)
+	}
+
+	_, err = ArchiveExtractWithPassword(archive, t.TempDir(), "wrong", false, true, nil)
+	if !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("expected ErrIncorrectPassword got %v", err)
+	}
+
+	out := t.TempDir()
+	if _, err = ArchiveExtractWithPassword(archive, out, "hunter2", false, true, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(out, "mod", "mod.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, content) {
+		t.Errorf("extracted content does not match %s", b)
+	}
+}
+
+func TestExtractAesZipIsUnsupported(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "mod.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	rw, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "mod/mod.ini",
+		Method:             zipMethodAES,
+		Flags:              zipFlagEncrypted,
+		CompressedSize64:   4,
+		UncompressedSize64: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw.Write([]byte("aes!"))
+	w.Close()
+	f.Close()
+
+	// a password can not help so the user is not asked for one
+	for _, password := range []string{"", "hunter2"} {
+		_, err = ArchiveExtractWithPassword(archive, t.TempDir(), password, false, true, nil)
+		if !errors.Is(err, ErrUnsupportedEncryption) || IsPasswordError(err) {
+			t.Errorf("expected ErrUnsupportedEncryption with password %q got %v", password, err)
+		}
+	}
+}
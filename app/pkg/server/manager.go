@@ -32,6 +32,7 @@ type ServerManager struct {
 	server    *CancelableServer
 	prefs     *core.AppPrefs
 	generator *core.Generator
+	sync      *core.SyncHelper
 	db        *dbh.DbHelper
 	notifier  core.Notifier
 	err       chan error
@@ -75,12 +76,13 @@ func (*ServerManager) GetLocalIp() (string, error) {
 	return "", errors.New("are you connected to the network?")
 }
 
-func NewServerManager(prefs *core.AppPrefs, db *dbh.DbHelper, g *core.Generator, notifier core.Notifier) *ServerManager {
+func NewServerManager(prefs *core.AppPrefs, db *dbh.DbHelper, g *core.Generator, sync *core.SyncHelper, notifier core.Notifier) *ServerManager {
 	return &ServerManager{
 		server:    nil,
 		prefs:     prefs,
 		db:        db,
 		generator: g,
+		sync:      sync,
 		notifier:  notifier,
 		events:    make(chan ServCmd),
 		err:       make(chan error),
@@ -170,7 +172,7 @@ func (sm *ServerManager) startCancellableServer(port int) {
 
 	sm.server = &CancelableServer{
 		cancel,
-		newServer(port, sm.db, sm.generator, sm.prefs),
+		newServer(port, sm.db, sm.generator, sm.sync, sm.prefs),
 	}
 
 	go func() {
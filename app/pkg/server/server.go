@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hmm/pkg/core"
 	"hmm/pkg/core/dbh"
@@ -13,6 +14,7 @@ import (
 	"hmm/pkg/types"
 	"io"
 	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
@@ -43,9 +45,11 @@ type Server struct {
 	port      int
 	db        *dbh.DbHelper
 	generator *core.Generator
+	sync      *core.SyncHelper
 	authType  pref.Preference[int]
 	username  pref.Preference[string]
 	password  pref.Preference[string]
+	sortMods  pref.Preference[string]
 	jobs      map[int]*Job
 	jobId     *atomic.Int32
 	jobMutex  *sync.Mutex
@@ -55,15 +59,18 @@ func newServer(
 	port int,
 	db *dbh.DbHelper,
 	generator *core.Generator,
+	syncHelper *core.SyncHelper,
 	prefs *core.AppPrefs,
 ) *Server {
 	return &Server{
 		port:      port,
 		db:        db,
 		generator: generator,
+		sync:      syncHelper,
 		authType:  prefs.ServerAuthTypePref,
 		username:  prefs.ServerUsernamePref,
 		password:  prefs.ServerPasswordPref,
+		sortMods:  prefs.LibrarySortPref,
 		jobs:      map[int]*Job{},
 		jobId:     &atomic.Int32{},
 		jobMutex:  &sync.Mutex{},
@@ -125,6 +132,19 @@ type TogglePostRequest struct {
 	Enabled bool `json:"enabled"`
 }
 
+// fields left out are not changed, a rating of 0 clears it
+type PersonalPostRequest struct {
+	Id       int     `json:"mod_id"`
+	Favorite *bool   `json:"favorite"`
+	Rating   *int    `json:"rating"`
+	Notes    *string `json:"notes"`
+}
+
+type ToggleResponse struct {
+	// mods disabled because the character only allows one enabled mod
+	Disabled []types.Mod `json:"disabled"`
+}
+
 func (s *Server) registerHandlers(mux *http.ServeMux) {
 	basicAuthMiddleware := func(next func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
@@ -170,14 +190,27 @@ func (s *Server) registerHandlers(mux *http.ServeMux) {
 		}
 	}
 
-	mux.HandleFunc("GET /data", basicAuthMiddleware(dataHandler(s.db)))
-	mux.HandleFunc("GET /data/{game}", basicAuthMiddleware(gameDataHandler(s.db)))
+	mux.HandleFunc("GET /data", basicAuthMiddleware(dataHandler(s.db, s.modSort)))
+	mux.HandleFunc("GET /data/{game}", basicAuthMiddleware(gameDataHandler(s.db, s.modSort)))
 
 	mux.HandleFunc("POST /update/mod", basicAuthMiddleware(updateModHandler(s.db)))
+	mux.HandleFunc("POST /update/mod/personal", basicAuthMiddleware(updatePersonalHandler(s.db)))
+
+	mux.HandleFunc("GET /usage/{game}", basicAuthMiddleware(usageHandler(s.db)))
+	mux.HandleFunc("GET /search/{game}", basicAuthMiddleware(searchHandler(s.db)))
+
+	mux.HandleFunc("GET /v2/mods", basicAuthMiddleware(modPageHandler(s.db)))
+
+	// only changes made since the app started can be undone
+	mux.HandleFunc("POST /undo", basicAuthMiddleware(journalHandler(s.db.Undo)))
+	mux.HandleFunc("POST /redo", basicAuthMiddleware(journalHandler(s.db.Redo)))
 
 	mux.HandleFunc("POST /generate", basicAuthMiddleware(s.generateHandler()))
 
 	mux.HandleFunc("GET /poll-generation", basicAuthMiddleware(s.pollGenerationHandler()))
+
+	mux.HandleFunc("GET /sync/report/{game}", basicAuthMiddleware(s.syncReportHandler()))
+	mux.HandleFunc("POST /sync/confirm/{game}", basicAuthMiddleware(s.syncConfirmHandler()))
 }
 func validateGame(w http.ResponseWriter, r *http.Request) (types.Game, error) {
 	game, err := strconv.Atoi(r.PathValue("game"))
@@ -197,14 +230,22 @@ func validateGame(w http.ResponseWriter, r *http.Request) (types.Game, error) {
 	return types.Game(game), nil
 }
 
-func gameDataHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Request) {
+// the sort query param, LibrarySortPref is used without one
+func (s *Server) modSort(r *http.Request) types.ModSort {
+	if sort := r.URL.Query().Get("sort"); sort != "" {
+		return types.ModSort(strings.ToUpper(sort[:1]) + sort[1:])
+	}
+	return types.ModSort(s.sortMods.Get())
+}
+
+func gameDataHandler(db *dbh.DbHelper, modSort func(r *http.Request) types.ModSort) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		game, err := validateGame(w, r)
 		if err != nil {
 			return
 		}
 
-		cwmt, err := db.SelectCharacterWithModsTagsAndTextures(game, "", "", "")
+		cwmt, err := db.SelectSortedCharacterMods(game, "", "", "", modSort(r))
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte("Server encountered an error"))
@@ -224,14 +265,15 @@ func gameDataHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Reque
 	}
 }
 
-func dataHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Request) {
+func dataHandler(db *dbh.DbHelper, modSort func(r *http.Request) types.ModSort) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		sort := modSort(r)
 
 		results := make([]DataResponse, 0, 4)
 
 		for _, game := range validGame {
 
-			d, err := db.SelectCharacterWithModsTagsAndTextures(types.Game(game), "", "", "")
+			d, err := db.SelectSortedCharacterMods(types.Game(game), "", "", "", sort)
 
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
@@ -276,14 +318,279 @@ func updateModHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
-		err = db.UpdateModEnabledById(t.Enabled, t.Id)
+		disabled, err := db.UpdateModEnabledById(t.Enabled, t.Id)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte("Bad Request: unable to update mod"))
 			return
 		}
 
+		bytes, err := json.Marshal(ToggleResponse{Disabled: disabled})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
+	}
+}
+
+func updatePersonalHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Bad Request: unable to read body"))
+			return
+		}
+		var p PersonalPostRequest
+		err = json.Unmarshal(body, &p)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Bad Request: unable to unmarshal body"))
+			return
+		}
+		if p.Rating != nil && (*p.Rating < 0 || *p.Rating > 5) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Bad Request: " + dbh.ErrInvalidRating.Error()))
+			return
+		}
+
+		if _, err := db.SelectModById(p.Id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Not Found: no mod with this id"))
+			return
+		}
+
+		if p.Favorite != nil {
+			err = errors.Join(err, db.UpdateModFavorite(p.Id, *p.Favorite))
+		}
+		if p.Rating != nil {
+			err = errors.Join(err, db.UpdateModRating(p.Id, *p.Rating))
+		}
+		if p.Notes != nil {
+			err = errors.Join(err, db.UpdateModNotes(p.Id, *p.Notes))
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Bad Request: unable to update mod"))
+			return
+		}
+
+		mod, err := db.SelectModById(p.Id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+		bytes, err := json.Marshal(mod)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
+	}
+}
+
+// the query param q supports the fts5 syntax described on dbh.Search
+func searchHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := validateGame(w, r)
+		if err != nil {
+			return
+		}
+
+		results, err := db.Search(game, r.URL.Query().Get("q"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		bytes, err := json.Marshal(results)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
+	}
+}
+
+// nil when the param is not set
+func queryBool(query url.Values, name string) (*bool, error) {
+	if !query.Has(name) {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(query.Get(name))
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// the first page is filtered with the query params game, sort, element, character, enabled,
+// tag and hasTextures. the next pages only need cursor and limit
+func modPageHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		filter := types.ModPageFilter{}
+		cursor := query.Get("cursor")
+		if cursor == "" {
+			game, err := strconv.Atoi(query.Get("game"))
+			if err != nil || !slices.Contains(validGame, game) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(fmt.Sprintf("Bad Request: Invalid game acceptable values %s", joinIntSlice(validGame, ", "))))
+				return
+			}
+			characterId, _ := strconv.Atoi(query.Get("character"))
+			enabled, err := queryBool(query, "enabled")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Bad Request: enabled must be true or false"))
+				return
+			}
+			hasTextures, err := queryBool(query, "hasTextures")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Bad Request: hasTextures must be true or false"))
+				return
+			}
+
+			sort := types.SORT_MOD_NAME
+			if s := query.Get("sort"); s != "" {
+				sort = types.ModSort(strings.ToUpper(s[:1]) + s[1:])
+			}
+
+			filter = types.ModPageFilter{
+				Game:        types.Game(game),
+				Sort:        sort,
+				Element:     query.Get("element"),
+				CharacterId: characterId,
+				Enabled:     enabled,
+				Tag:         query.Get("tag"),
+				HasTextures: hasTextures,
+			}
+		}
+
+		page, err := db.SelectModPage(filter, cursor, limit)
+		if errors.Is(err, dbh.ErrInvalidCursor) || errors.Is(err, dbh.ErrInvalidSort) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Bad Request: " + err.Error()))
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		bytes, err := json.Marshal(page)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
+	}
+}
+
+var usageViews = map[string]types.UsageView{
+	"":       types.USAGE_ALL,
+	"recent": types.USAGE_RECENT,
+	"never":  types.USAGE_NEVER,
+	"stale":  types.USAGE_STALE,
+}
+
+// filtered with the query params view, character, tag and staleDays
+func usageHandler(db *dbh.DbHelper) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := validateGame(w, r)
+		if err != nil {
+			return
+		}
+
+		query := r.URL.Query()
+		view, ok := usageViews[query.Get("view")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Bad Request: view must be recent, never or stale"))
+			return
+		}
+		characterId, _ := strconv.Atoi(query.Get("character"))
+		staleDays, _ := strconv.Atoi(query.Get("staleDays"))
+
+		usage, err := db.SelectModUsage(types.UsageFilter{
+			Game:        game,
+			CharacterId: characterId,
+			Tag:         query.Get("tag"),
+			View:        view,
+			StaleDays:   staleDays,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		bytes, err := json.Marshal(usage)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
+	}
+}
+
+type JournalResponse struct {
+	// name of the operation that was undone or redone
+	Operation string `json:"operation"`
+}
+
+// replays the in memory journal of the running app, history from earlier sessions can not be undone
+func journalHandler(replay func() (string, error)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := replay()
+		if errors.Is(err, dbh.ErrNothingToUndo) || errors.Is(err, dbh.ErrNothingToRedo) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fmt.Sprintf("Server encountered an error: unable to replay %s", name)))
+			return
+		}
+
+		bytes, err := json.Marshal(JournalResponse{Operation: name})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
 	}
 }
 
@@ -397,3 +704,47 @@ func (s *Server) pollGenerationHandler() func(w http.ResponseWriter, r *http.Req
 		json.NewEncoder(w).Encode(response)
 	}
 }
+
+func writeSyncReport(w http.ResponseWriter, report core.SyncReport) {
+	bytes, err := json.Marshal(report)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Server encountered an error"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(bytes)
+}
+
+func (s *Server) syncReportHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := validateGame(w, r)
+		if err != nil {
+			return
+		}
+
+		writeSyncReport(w, s.sync.LastSyncReport(game))
+	}
+}
+
+// deletes the rows that the last sync is holding for confirmation
+func (s *Server) syncConfirmHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		game, err := validateGame(w, r)
+		if err != nil {
+			return
+		}
+
+		report, err := s.sync.ConfirmPendingDeletes(game)
+		if err != nil {
+			log.LogError(err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Server encountered an error"))
+			return
+		}
+
+		writeSyncReport(w, report)
+	}
+}
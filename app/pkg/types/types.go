@@ -15,6 +15,28 @@ const (
 type Game int
 type AuthType int
 
+// kind of a non character category, characters use CATEGORY_NONE
+type CategoryKind int
+
+const (
+	CATEGORY_NONE CategoryKind = iota
+	CATEGORY_WEAPON
+	CATEGORY_UI
+	CATEGORY_NPC
+	CATEGORY_ENVIRONMENT
+	CATEGORY_SHADER
+	CATEGORY_OTHER
+)
+
+var CategoryKinds = []CategoryKind{
+	CATEGORY_WEAPON,
+	CATEGORY_UI,
+	CATEGORY_NPC,
+	CATEGORY_ENVIRONMENT,
+	CATEGORY_SHADER,
+	CATEGORY_OTHER,
+}
+
 var Games = []Game{Genshin, StarRail, WuWa, ZZZ}
 
 type Pair[X any, Y any] struct {
@@ -58,6 +80,24 @@ func (g Game) Name() string {
 	panic("bad game in name types.go")
 }
 
+func (k CategoryKind) Name() string {
+	switch k {
+	case CATEGORY_WEAPON:
+		return "Weapons"
+	case CATEGORY_UI:
+		return "UI"
+	case CATEGORY_NPC:
+		return "NPCs"
+	case CATEGORY_ENVIRONMENT:
+		return "Environment"
+	case CATEGORY_SHADER:
+		return "Shaders"
+	case CATEGORY_OTHER:
+		return "Other"
+	}
+	return ""
+}
+
 type FileInfo struct {
 	File  string `json:"file"`
 	Bytes int64  `json:"bytes"`
@@ -68,6 +108,16 @@ type DownloadStats struct {
 	TotalBytes int64        `json:"totalBytes"`
 }
 
+// whether enabling a mod of the character disables its other mods
+type ExclusiveMode int
+
+const (
+	// uses the setting of the game
+	EXCLUSIVE_DEFAULT ExclusiveMode = 0
+	EXCLUSIVE_ON      ExclusiveMode = 1
+	EXCLUSIVE_OFF     ExclusiveMode = 2
+)
+
 type Character struct {
 	Id        int    `json:"id"`
 	Game      Game   `json:"game"`
@@ -75,6 +125,23 @@ type Character struct {
 	AvatarUrl string `json:"avatarUrl"`
 	Element   string `json:"element"`
 	Custom    bool   `json:"custom"`
+	// set for categories like weapons or UI that are stored next to characters
+	Category  CategoryKind  `json:"category"`
+	Exclusive ExclusiveMode `json:"exclusive"`
+}
+
+func (c Character) IsCategory() bool {
+	return c.Category != CATEGORY_NONE
+}
+
+// another name of a character used to match dir and GameBanana names, bundled aliases
+// are disabled instead of deleted so seeding does not add them again
+type CharacterAlias struct {
+	CharacterId int    `json:"characterId"`
+	Game        Game   `json:"game"`
+	Alias       string `json:"alias"`
+	Custom      bool   `json:"custom"`
+	Disabled    bool   `json:"disabled"`
 }
 
 type CharacterWithModsAndTags struct {
@@ -99,7 +166,56 @@ type Mod struct {
 	ModLink        string   `json:"modLink"`
 	GbFileName     string   `json:"gbFileName"`
 	GbDownloadLink string   `json:"gbDownloadLink"`
-	Id             int      `json:"id"`
+	Quarantined    bool     `json:"quarantined"`
+	// the mod belongs to a category instead of a character
+	Category bool `json:"category"`
+	Id       int  `json:"id"`
+	// filled from the GameBanana page of mods with a gbId
+	GbName         string   `json:"gbName"`
+	Author         string   `json:"author"`
+	Version        string   `json:"version"`
+	Description    string   `json:"description"`
+	ContentRatings []string `json:"contentRatings"`
+	// unix seconds, 0 when unknown
+	AddedAt       int64 `json:"addedAt"`
+	LastEnabledAt int64 `json:"lastEnabledAt"`
+	GbUpdatedAt   int64 `json:"gbUpdatedAt"`
+	// bytes on disk
+	Size int64 `json:"size"`
+	// set by the user, rating is 1 to 5 or 0 when not rated
+	Favorite bool   `json:"favorite"`
+	Rating   int    `json:"rating"`
+	Notes    string `json:"notes"`
+}
+
+// sorts for the mods of each character, the default is kept in LibrarySortPref
+type ModSort string
+
+const (
+	SORT_MOD_NAME     ModSort = ""
+	SORT_MOD_RATING   ModSort = "Rating"
+	SORT_MOD_FAVORITE ModSort = "Favorite"
+	// newest first
+	SORT_MOD_ADDED ModSort = "Added"
+)
+
+// filters of a mod page, zero values and nil match every mod
+type ModPageFilter struct {
+	Game        Game    `json:"game"`
+	Sort        ModSort `json:"sort"`
+	Element     string  `json:"element"`
+	CharacterId int     `json:"characterId"`
+	Enabled     *bool   `json:"enabled"`
+	Tag         string  `json:"tag"`
+	HasTextures *bool   `json:"hasTextures"`
+}
+
+// Characters are the characters and categories of the mods on the page
+type ModPage struct {
+	Mods       []ModWithTags `json:"mods"`
+	Characters []Character   `json:"characters"`
+	// passed back to get the next page, empty on the last page
+	NextCursor string `json:"nextCursor"`
 }
 
 type Texture struct {
@@ -119,6 +235,73 @@ type Tag struct {
 	Name  string `json:"name"`
 }
 
+// a file that was moved out of the mod when it was downloaded,
+// path is relative to the mod dir using forward slashes
+type QuarantinedFile struct {
+	ModId   int    `json:"modId"`
+	Path    string `json:"path"`
+	Allowed bool   `json:"allowed"`
+}
+
+type DependencyKind int
+type DependencySource int
+
+const (
+	DEPENDENCY_MOD      DependencyKind = 0
+	DEPENDENCY_CATEGORY DependencyKind = 1
+
+	DEPENDENCY_SOURCE_USER    DependencySource = 0
+	DEPENDENCY_SOURCE_SIDECAR DependencySource = 1
+	DEPENDENCY_SOURCE_INI     DependencySource = 2
+)
+
+// the mod needs the mod or category with the id DependsOn to be exported with it,
+// a category dependency exports every mod of the category
+type ModDependency struct {
+	ModId     int              `json:"modId"`
+	Kind      DependencyKind   `json:"kind"`
+	DependsOn int              `json:"dependsOn"`
+	Source    DependencySource `json:"source"`
+}
+
+type DuplicateReason int
+
+const (
+	// same GameBanana file, mods without a gbId match on the file name
+	DUPLICATE_GB_FILE     DuplicateReason = 0
+	DUPLICATE_FINGERPRINT DuplicateReason = 1
+)
+
+// mods that are likely copies of each other
+type DuplicateGroup struct {
+	Mods    []Mod             `json:"mods"`
+	Reasons []DuplicateReason `json:"reasons"`
+}
+
+type RecycleKind int
+
+const (
+	RECYCLE_MOD     RecycleKind = 0
+	RECYCLE_TEXTURE RecycleKind = 1
+)
+
+// a deleted mod or texture that can be restored until the recycle bin is emptied
+type RecycleEntry struct {
+	Id        string      `json:"id"`
+	Kind      RecycleKind `json:"kind"`
+	Game      Game        `json:"game"`
+	Character string      `json:"character"`
+	Mod       string      `json:"mod"`
+	Texture   string      `json:"texture"`
+	DeletedAt time.Time   `json:"deletedAt"`
+}
+
+// names of the operations that can be undone and redone, newest first
+type Journal struct {
+	Undo []string `json:"undo"`
+	Redo []string `json:"redo"`
+}
+
 type Playlist struct {
 	Id   int    `json:"id"`
 	Name string `json:"name"`
@@ -160,3 +343,68 @@ type Update struct {
 type UpdateResponse struct {
 	Updates []Update `json:"updates"`
 }
+
+// a mod matched by a search, a higher rank is a better match
+type SearchResult struct {
+	ModWithTags ModWithTags `json:"modWithTags"`
+	Rank        float64     `json:"rank"`
+}
+
+type UsageView int
+
+const (
+	USAGE_ALL UsageView = 0
+	// exported mods, last exported first
+	USAGE_RECENT UsageView = 1
+	// mods that were never in a generated set
+	USAGE_NEVER UsageView = 2
+	// mods not exported within StaleDays
+	USAGE_STALE UsageView = 3
+)
+
+// CharacterId 0 and an empty Tag match every mod of the game
+type UsageFilter struct {
+	Game        Game      `json:"game"`
+	CharacterId int       `json:"characterId"`
+	Tag         string    `json:"tag"`
+	View        UsageView `json:"view"`
+	StaleDays   int       `json:"staleDays"`
+}
+
+// how often a mod was in the enabled set of a reload and for how long, times are unix seconds
+type ModUsage struct {
+	Mod            Mod   `json:"mod"`
+	GenerateCount  int   `json:"generateCount"`
+	EnabledSeconds int64 `json:"enabledSeconds"`
+	LastExportedAt int64 `json:"lastExportedAt"`
+}
+
+type DoctorIssueKind int
+
+const (
+	// a row pointing at a row of another table that does not exist
+	DOCTOR_FOREIGN_KEY DoctorIssueKind = 0
+	// a texture row whose dir is missing from an existing mod dir
+	DOCTOR_MISSING_TEXTURE_DIR DoctorIssueKind = 1
+	// a mod whose character or category row does not exist
+	DOCTOR_MISSING_CHARACTER DoctorIssueKind = 2
+	// a cached ini path that is not in the mod anymore
+	DOCTOR_STALE_INI_CACHE DoctorIssueKind = 3
+	// a playlist entry of a deleted mod
+	DOCTOR_PLAYLIST_MISSING_MOD DoctorIssueKind = 4
+)
+
+// DoctorIssue is a finding of the integrity check, Fix describes what fixing it does.
+// the ids that do not apply to the kind are 0
+type DoctorIssue struct {
+	Kind DoctorIssueKind `json:"kind"`
+	Game Game            `json:"game"`
+	// table and rowid of a foreign key issue
+	Table      string `json:"table"`
+	RowId      int    `json:"rowId"`
+	ModId      int    `json:"modId"`
+	TextureId  int    `json:"textureId"`
+	PlaylistId int    `json:"playlistId"`
+	Message    string `json:"message"`
+	Fix        string `json:"fix"`
+}
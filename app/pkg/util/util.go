@@ -20,7 +20,22 @@ const (
 	APP_NAME = "HoyoModManagerGo"
 )
 
-var MetaDataDirs = []string{"keymaps", "textures", "config"}
+// metadata file written into each mod and texture dir
+const SidecarFile = "hmm.json"
+
+// entries of a mod dir that are not part of the mod archive
+var MetaDataDirs = []string{"keymaps", "textures", "config", "quarantine", SidecarFile}
+
+// dir inside the game dir holding the category dirs
+const CategoriesDir = "_categories"
+
+// deleted mods and textures are kept in root/_recycle/ENTRY/files so they are
+// not picked up by sync or the sidecar scan
+const RecycleDir = "_recycle"
+
+func GetRecycleDir() string {
+	return filepath.Join(GetRootModDir(), RecycleDir)
+}
 
 func GetGeneratorCache() string {
 
@@ -61,6 +76,9 @@ func GetKeyMapsDir(m types.Mod) string {
 }
 
 func GetModDir(m types.Mod) string {
+	if m.Category {
+		return filepath.Join(GetCategoryDir(m.Character, m.Game), m.Filename)
+	}
 	return filepath.Join(GetCharacterDir(m.Character, m.Game), m.Filename)
 }
 
@@ -68,6 +86,18 @@ func GetCharacterDir(character string, game types.Game) string {
 	return filepath.Join(GetGameDir(game), character)
 }
 
+func GetCategoryDir(category string, game types.Game) string {
+	return filepath.Join(GetGameDir(game), CategoriesDir, category)
+}
+
+// dir the mods of c are stored in
+func GetCharacterOrCategoryDir(c types.Character) string {
+	if c.IsCategory() {
+		return GetCategoryDir(c.Name, c.Game)
+	}
+	return GetCharacterDir(c.Name, c.Game)
+}
+
 func GetGameDir(game types.Game) string {
 	return filepath.Join(GetRootModDir(), game.Name())
 }
@@ -95,6 +125,10 @@ func GetModConfigCache(m types.Mod) string {
 	return filepath.Join(GetModDir(m), "config")
 }
 
+func GetQuarantineDir(m types.Mod) string {
+	return filepath.Join(GetModDir(m), "quarantine")
+}
+
 func HashForName(name string) int {
 	h := fnv.New32a()
 	h.Write([]byte(name))
@@ -102,6 +136,12 @@ func HashForName(name string) int {
 	return int(h.Sum32())
 }
 
+// ids of categories are hashed with the categories dir so they
+// do not collide with a custom character of the same name
+func CategoryId(name string) int {
+	return HashForName(CategoriesDir + "/" + name)
+}
+
 func GetTextureArchiveFrom(mdir string, t types.Texture) (string, error) {
 	textureDir := filepath.Join(mdir, "textures", t.Filename)
 	dirs, err := os.ReadDir(textureDir)